@@ -68,7 +68,8 @@ func (instance *Apply) ConfigureCliCommands(context string, hc common.HasCommand
 	cmd.Flag("dryRun", "If set to 'before' it will execute a dry run before the actual apply."+
 		" This is perfect in cases where the first parts of the apply configuration works and"+
 		" the following stuff is broken. If set to 'never' apply will be executed without dry run."+
-		" On 'only' it will only run the dry run but not the apply.").
+		" On 'only' it will only run the dry run but not the apply. In this case missing namespaces are not created"+
+		" (see claim.manageNamespaces) and a dry run on the server fails for objects inside of them.").
 		Envar("KUBOR_DRY_RUN").
 		Default(instance.DryRun.String()).
 		SetValue(&instance.DryRun)
@@ -120,7 +121,7 @@ func (instance *Apply) RunWithArguments(arguments Arguments) error {
 		arguments:     arguments,
 		cleanupTask:   &ct,
 	}
-	oh, err := model.NewObjectHandler(task.onObject, arguments.Project)
+	oh, err := newObjectHandler(task.onObject, arguments)
 	if err != nil {
 		return err
 	}
@@ -135,6 +136,15 @@ func (instance *Apply) RunWithArguments(arguments Arguments) error {
 		return err
 	}
 
+	// Claimed namespaces have to exist before the dry run and the first stage. They
+	// are never created if nothing will be applied; therefore a dry run on the server
+	// with --dryRun=only fails for objects inside of missing claimed namespaces.
+	if instance.DryRun.IsApplyAllowed() {
+		if err := kubernetes.NewNamespaceTask(arguments.Project, arguments.Runtime, arguments.DynamicClient).Ensure(); err != nil {
+			return err
+		}
+	}
+
 	if instance.DryRun.IsDryRunAllowed() {
 		if _, err := task.stagedApplySet.Execute("dryRun", instance.DryRunOn, nil, false); err != nil {
 			return err
@@ -208,3 +218,7 @@ func (instance *applyTask) onObject(source string, _ runtime.Object, object *uns
 
 	return nil
 }
+
+func (instance *Apply) ReverseWorkspaceOrder() bool {
+	return false
+}
@@ -41,7 +41,7 @@ func (instance *Cleanup) RunWithArguments(arguments Arguments) error {
 		project:     arguments.Project,
 		cleanupTask: &ct,
 	}
-	oh, err := model.NewObjectHandler(task.onObject, arguments.Project)
+	oh, err := newObjectHandler(task.onObject, arguments)
 	if err != nil {
 		return err
 	}
@@ -5,7 +5,9 @@ import (
 	"github.com/alecthomas/kingpin"
 	"github.com/echocat/kubor/kubernetes"
 	"github.com/echocat/kubor/model"
+	"github.com/echocat/slf4g"
 	"k8s.io/client-go/dynamic"
+	"time"
 )
 
 type Arguments struct {
@@ -18,6 +20,23 @@ type RunnableConsumingCommandArguments interface {
 	RunWithArguments(args Arguments) error
 }
 
+// WorkspaceAwareCommand is implemented by commands which could be executed for
+// every member of a workspace (see --workspace).
+type WorkspaceAwareCommand interface {
+	// ReverseWorkspaceOrder returns true if dependents have to be handled before the
+	// members they depend on (like on delete).
+	ReverseWorkspaceOrder() bool
+}
+
+// ValuesInspectingCommand is implemented by commands which inspect the values of the
+// project and therefore report violations of the values schema themselves instead of
+// failing while the project is created.
+type ValuesInspectingCommand interface {
+	// ToleratesValuesSchemaViolations returns true if the project should be created
+	// even if its values violate the values schema (see Project.ValuesSchemaViolations).
+	ToleratesValuesSchemaViolations() bool
+}
+
 type Command struct {
 	ProjectFactory *model.ProjectFactory
 	Parent         RunnableConsumingCommandArguments
@@ -32,6 +51,9 @@ func (instance *Command) createProject(runtime kubernetes.Runtime) (*model.Proje
 	if instance.ProjectFactory == nil {
 		return nil, fmt.Errorf("command not yet initialized")
 	}
+	if inspecting, ok := instance.Parent.(ValuesInspectingCommand); ok && inspecting.ToleratesValuesSchemaViolations() {
+		return instance.ProjectFactory.CreateToleratingViolations(runtime.ContextName())
+	}
 	return instance.ProjectFactory.Create(runtime.ContextName())
 }
 
@@ -48,16 +70,79 @@ func (instance *Command) Run() error {
 	if err != nil {
 		return err
 	}
+	if instance.Parent == nil {
+		panic("no Parent defined")
+	}
+	if aware, ok := instance.Parent.(WorkspaceAwareCommand); !ok {
+		// KUBOR_WORKSPACE is ignored here, only an explicit --workspace is rejected.
+		if instance.ProjectFactory.HasExplicitWorkspace() {
+			return fmt.Errorf("this command does not support --workspace")
+		}
+	} else if workspace, err := instance.ProjectFactory.Workspace(); err != nil {
+		return err
+	} else if workspace != nil {
+		return instance.runWorkspace(aware, runtime, dc)
+	}
 	project, err := instance.createProject(runtime)
 	if err != nil {
 		return err
 	}
-	if instance.Parent == nil {
-		panic("no Parent defined")
-	}
 	return instance.Parent.RunWithArguments(Arguments{
 		Project:       project,
 		Runtime:       runtime,
 		DynamicClient: dc,
 	})
 }
+
+// runWorkspace executes the Parent for every member of the workspace in the order of
+// their dependencies. After the first failing member all remaining ones are skipped.
+func (instance *Command) runWorkspace(aware WorkspaceAwareCommand, runtime kubernetes.Runtime, dc dynamic.Interface) error {
+	projects, err := instance.ProjectFactory.CreateAll(runtime.ContextName(), aware.ReverseWorkspaceOrder())
+	if err != nil {
+		return err
+	}
+
+	var failed error
+	succeeded := 0
+	for _, project := range projects {
+		l := log.With("workspaceMember", project.WorkspaceMember)
+		if failed != nil {
+			l.Warnf("Workspace member %s... SKIPPED!", project.WorkspaceMember)
+			continue
+		}
+		start := time.Now()
+		l.Debugf("Workspace member %s...", project.WorkspaceMember)
+		if err := instance.Parent.RunWithArguments(Arguments{
+			Project:       project,
+			Runtime:       runtime,
+			DynamicClient: dc,
+		}); err != nil {
+			l.With("duration", time.Since(start)).
+				WithError(err).
+				Errorf("Workspace member %s... FAILED!", project.WorkspaceMember)
+			failed = fmt.Errorf("workspace member '%s' failed: %w", project.WorkspaceMember, err)
+		} else {
+			l.With("duration", time.Since(start)).
+				Infof("Workspace member %s... SUCCESS!", project.WorkspaceMember)
+			succeeded++
+		}
+	}
+
+	if failed != nil {
+		log.Errorf("%d of %d workspace members succeeded.", succeeded, len(projects))
+	} else {
+		log.Infof("All %d workspace members succeeded.", len(projects))
+	}
+	return failed
+}
+
+// newObjectHandler creates a model.ObjectHandler for the project of the given
+// arguments which defaults the namespace of all objects without one.
+func newObjectHandler(onObject model.OnObject, arguments Arguments) (*model.ObjectHandler, error) {
+	oh, err := model.NewObjectHandler(onObject, arguments.Project)
+	if err != nil {
+		return nil, err
+	}
+	oh.Prepare = kubernetes.NewNamespaceDefaulter(arguments.Project, arguments.Runtime).Default
+	return oh, nil
+}
@@ -32,5 +32,12 @@ func (instance *Delete) RunWithArguments(arguments Arguments) error {
 	if err != nil {
 		return err
 	}
-	return ct.Execute()
+	if err := ct.Execute(); err != nil {
+		return err
+	}
+	return kubernetes.NewNamespaceTask(arguments.Project, arguments.Runtime, arguments.DynamicClient).DeleteIfEmpty()
+}
+
+func (instance *Delete) ReverseWorkspaceOrder() bool {
+	return true
 }
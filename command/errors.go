@@ -0,0 +1,47 @@
+package command
+
+import (
+	"fmt"
+	"github.com/echocat/kubor/template"
+	"io"
+	"strings"
+)
+
+const excerptSurroundingLines = 2
+
+// ReportError writes the given error in a human readable form to the provided writer.
+// For every contained template.TemplateError the whole include stack is printed
+// together with an excerpt of the affected source code.
+func ReportError(w io.Writer, err error) {
+	if err == nil {
+		return
+	}
+	errs := []error{err}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		errs = joined.Unwrap()
+	}
+	for _, candidate := range errs {
+		_, _ = fmt.Fprintf(w, "kubor: error: %v\n", candidate)
+		for _, te := range template.CollectTemplateErrors(candidate) {
+			for _, frame := range te.Stack() {
+				reportTemplateErrorFrame(w, frame)
+			}
+		}
+	}
+	if len(errs) > 1 {
+		_, _ = fmt.Fprintf(w, "kubor: %d errors occurred\n", len(errs))
+	}
+}
+
+func reportTemplateErrorFrame(w io.Writer, frame *template.TemplateError) {
+	_, _ = fmt.Fprintf(w, "  --> %s", frame.Location())
+	if frame.Action != "" {
+		_, _ = fmt.Fprintf(w, " at %s", frame.Action)
+	}
+	_, _ = fmt.Fprint(w, "\n")
+	if excerpt := frame.Excerpt(excerptSurroundingLines); excerpt != "" {
+		for _, line := range strings.Split(strings.TrimSuffix(excerpt, "\n"), "\n") {
+			_, _ = fmt.Fprintf(w, "    %s\n", line)
+		}
+	}
+}
@@ -1,9 +1,11 @@
 package command
 
 import (
+	"errors"
 	"fmt"
 	"github.com/echocat/kubor/common"
-	"github.com/echocat/kubor/model"
+	"github.com/echocat/kubor/kubernetes/openapi"
+	log "github.com/echocat/slf4g"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/serializer/json"
@@ -12,7 +14,9 @@ import (
 )
 
 func init() {
-	cmd := &Evaluate{}
+	cmd := &Evaluate{
+		Schema: SchemaValidation{KubernetesVersion: openapi.DefaultVersion()},
+	}
 	cmd.Parent = cmd
 	RegisterInitializable(cmd)
 	common.RegisterCliFactory(cmd)
@@ -23,6 +27,13 @@ type Evaluate struct {
 
 	SourceHint bool
 	Predicate  common.EvaluatingPredicate
+	AllErrors  bool
+	Validate   bool
+	Schema     SchemaValidation
+
+	// printed is true if at least one object was printed - also by a former
+	// member of a workspace.
+	printed bool
 }
 
 func (instance *Evaluate) ConfigureCliCommands(context string, hc common.HasCommands, _ string) error {
@@ -43,6 +54,16 @@ func (instance *Evaluate) ConfigureCliCommands(context string, hc common.HasComm
 		Short('p').
 		Envar("KUBOR_PREDICATE").
 		SetValue(&instance.Predicate)
+	cmd.Flag("allErrors", "If a template file could not be evaluated, continue with the remaining ones and report all errors at the end.").
+		Envar("KUBOR_ALL_ERRORS").
+		Default(fmt.Sprint(instance.AllErrors)).
+		BoolVar(&instance.AllErrors)
+	cmd.Flag("validate", "Validates every object against the OpenAPI schema of its kind (see --kubernetesVersion and --crd)"+
+		" without connecting to any cluster.").
+		Envar("KUBOR_VALIDATE").
+		Default(fmt.Sprint(instance.Validate)).
+		BoolVar(&instance.Validate)
+	instance.Schema.ConfigureFlags(cmd)
 
 	return nil
 }
@@ -50,12 +71,19 @@ func (instance *Evaluate) ConfigureCliCommands(context string, hc common.HasComm
 func (instance *Evaluate) RunWithArguments(arguments Arguments) error {
 	task := &evaluateTask{
 		source: instance,
-		first:  true,
 	}
-	oh, err := model.NewObjectHandler(task.onObject, arguments.Project)
+	if instance.Validate {
+		validator, err := instance.Schema.NewValidator()
+		if err != nil {
+			return err
+		}
+		task.validator = validator
+	}
+	oh, err := newObjectHandler(task.onObject, arguments)
 	if err != nil {
 		return err
 	}
+	oh.ContinueOnError = instance.AllErrors
 
 	cp, err := arguments.Project.RenderedTemplatesProvider()
 	if err != nil {
@@ -66,8 +94,8 @@ func (instance *Evaluate) RunWithArguments(arguments Arguments) error {
 }
 
 type evaluateTask struct {
-	source *Evaluate
-	first  bool
+	source    *Evaluate
+	validator *openapi.Validator
 }
 
 func (instance *evaluateTask) onObject(source string, object runtime.Object, unstructured *unstructured.Unstructured) error {
@@ -77,10 +105,19 @@ func (instance *evaluateTask) onObject(source string, object runtime.Object, uns
 		return nil
 	}
 
-	if instance.first {
-		instance.first = false
-	} else {
+	if instance.validator != nil {
+		if err := instance.validator.Validate(unstructured); errors.Is(err, openapi.ErrNoSchema) {
+			log.With("source", source).
+				Debugf("Object not validated: %v", err)
+		} else if err != nil {
+			return err
+		}
+	}
+
+	if instance.source.printed {
 		fmt.Print("---\n")
+	} else {
+		instance.source.printed = true
 	}
 	if instance.source.SourceHint {
 		fmt.Printf(sourceHintTemplate, source)
@@ -88,3 +125,7 @@ func (instance *evaluateTask) onObject(source string, object runtime.Object, uns
 	encoder := json.NewSerializerWithOptions(json.DefaultMetaFactory, scheme.Scheme, scheme.Scheme, json.SerializerOptions{Yaml: true, Pretty: true})
 	return encoder.Encode(object, os.Stdout)
 }
+
+func (instance *Evaluate) ReverseWorkspaceOrder() bool {
+	return false
+}
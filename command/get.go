@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"github.com/echocat/kubor/common"
 	"github.com/echocat/kubor/kubernetes"
-	"github.com/echocat/kubor/model"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/serializer/json"
@@ -52,7 +51,7 @@ func (instance *Get) RunWithArguments(arguments Arguments) error {
 		arguments: arguments,
 		first:     true,
 	}
-	oh, err := model.NewObjectHandler(task.onObject, arguments.Project)
+	oh, err := newObjectHandler(task.onObject, arguments)
 	if err != nil {
 		return err
 	}
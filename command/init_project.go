@@ -0,0 +1,92 @@
+package command
+
+import (
+	"fmt"
+	"github.com/alecthomas/kingpin"
+	"github.com/echocat/kubor/common"
+	"github.com/echocat/kubor/model"
+	"github.com/echocat/kubor/scaffold"
+	"github.com/echocat/kubor/wrapper"
+	"github.com/echocat/slf4g"
+)
+
+func init() {
+	cmd := &InitProject{
+		Directory: ".",
+	}
+	RegisterInitializable(cmd)
+	common.RegisterCliFactory(cmd)
+}
+
+type InitProject struct {
+	Command
+
+	Directory string
+	Stages    model.Stages
+	Schema    bool
+	Wrapper   bool
+	From      string
+	Force     bool
+
+	version string
+}
+
+func (instance *InitProject) ConfigureCliCommands(context string, hc common.HasCommands, version string) error {
+	if context != "" {
+		return nil
+	}
+	instance.version = version
+
+	cmd := hc.Command("init", "Creates a new project ("+scaffold.ProjectFile+") together with starter templates"+
+		" (a Deployment, a Service and a ConfigMap) and their values. The groupId and artifactId are taken from"+
+		" --groupId and --artifactId; the artifactId defaults to the name of the directory and the groupId"+
+		" (which is also the namespace of the starter templates) to the artifactId.").
+		Action(instance.ExecuteFromCli)
+	cmd.Arg("directory", "Directory to create the project in.").
+		Default(instance.Directory).
+		StringVar(&instance.Directory)
+	cmd.Flag("stages", "Comma separated stages of the project. The starter templates use the stage "+model.StageDefault.String()+
+		" (or the first one if not present). Default: "+model.NewStages().String()).
+		PlaceHolder("<stage>[,<stage>...]").
+		SetValue(&instance.Stages)
+	cmd.Flag("schema", "If set also a values schema for the starter values is created and configured as valuesSchema.").
+		Default(fmt.Sprint(instance.Schema)).
+		BoolVar(&instance.Schema)
+	cmd.Flag("wrapper", "If set also the kuborw wrapper is installed into the directory.").
+		Default(fmt.Sprint(instance.Wrapper)).
+		BoolVar(&instance.Wrapper)
+	cmd.Flag("from", "Directory of another local project which is used as template instead of the starter templates."+
+		" Its project file and all of its files (except hidden ones) are copied.").
+		PlaceHolder("<dir>").
+		StringVar(&instance.From)
+	cmd.Flag("force", "If set existing files are overwritten.").
+		Default(fmt.Sprint(instance.Force)).
+		BoolVar(&instance.Force)
+	return nil
+}
+
+func (instance *InitProject) ExecuteFromCli(*kingpin.ParseContext) error {
+	if instance.ProjectFactory == nil {
+		return fmt.Errorf("command not yet initialized")
+	}
+	project, err := scaffold.Create(scaffold.Options{
+		Directory:  instance.Directory,
+		GroupId:    instance.ProjectFactory.GroupId(),
+		ArtifactId: instance.ProjectFactory.ArtifactId(),
+		Stages:     instance.Stages,
+		Schema:     instance.Schema,
+		From:       instance.From,
+		Force:      instance.Force,
+	})
+	if err != nil {
+		return err
+	}
+	if instance.Wrapper {
+		if err := wrapper.Write(instance.Directory, instance.version, wrapper.WoCreateOrUpdate); err != nil {
+			return err
+		}
+	}
+	log.With("source", project.Source).
+		Infof("Project %s created.", project.ArtifactId)
+	return nil
+}
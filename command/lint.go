@@ -0,0 +1,168 @@
+package command
+
+import (
+	"fmt"
+	"github.com/alecthomas/kingpin"
+	"github.com/echocat/kubor/common"
+	"github.com/echocat/kubor/kubernetes/openapi"
+	"github.com/echocat/kubor/lint"
+	"github.com/echocat/kubor/model"
+	"os"
+	"strings"
+)
+
+type LintOutput string
+
+func (instance *LintOutput) Set(plain string) error {
+	if plain != "text" && plain != "json" && plain != "sarif" {
+		return fmt.Errorf("unsupported output format: %s", plain)
+	}
+	*instance = LintOutput(plain)
+	return nil
+}
+
+func (instance LintOutput) String() string {
+	return string(instance)
+}
+
+type LintRules map[lint.RuleName]model.LintSeverity
+
+func (instance *LintRules) Set(plain string) error {
+	plainName, plainSeverity, ok := strings.Cut(plain, "=")
+	if !ok {
+		return fmt.Errorf("expected <rule>=<severity> but got: %s", plain)
+	}
+	name := lint.RuleName(plainName)
+	if _, ok := lint.RuleByName(name); !ok {
+		return fmt.Errorf("unknown lint rule: %s", plainName)
+	}
+	var severity model.LintSeverity
+	if err := severity.Set(plainSeverity); err != nil {
+		return err
+	}
+	if *instance == nil {
+		*instance = LintRules{}
+	}
+	(*instance)[name] = severity
+	return nil
+}
+
+func (instance LintRules) String() string {
+	parts := make([]string, 0, len(instance))
+	for name, severity := range instance {
+		parts = append(parts, fmt.Sprintf("%s=%s", name, severity))
+	}
+	return strings.Join(parts, ",")
+}
+
+func (instance *LintRules) IsCumulative() bool {
+	return true
+}
+
+func init() {
+	cmd := &Lint{
+		Output: LintOutput("text"),
+		FailOn: model.LintSeverityError,
+		Schema: SchemaValidation{KubernetesVersion: openapi.DefaultVersion()},
+	}
+	RegisterInitializable(cmd)
+	common.RegisterCliFactory(cmd)
+}
+
+type Lint struct {
+	Command
+
+	Output LintOutput
+	Rules  LintRules
+	FailOn model.LintSeverity
+	Schema SchemaValidation
+
+	version string
+}
+
+func (instance *Lint) ConfigureCliCommands(context string, hc common.HasCommands, version string) error {
+	if context != "" {
+		return nil
+	}
+	instance.version = version
+
+	ruleNames := make([]string, len(lint.Rules))
+	for i, rule := range lint.Rules {
+		ruleNames[i] = string(rule.Name)
+	}
+
+	cmd := hc.Command("lint", "Checks the project file, renders all templates and checks every resulting object"+
+		" (kubor annotations, claim, OpenAPI schema and common smells) without connecting to any cluster.").
+		Action(instance.ExecuteFromCli)
+	cmd.Flag("output", "Specifies how to render the findings: text, json or sarif.").
+		Short('o').
+		Envar("KUBOR_LINT_OUTPUT").
+		Default(instance.Output.String()).
+		SetValue(&instance.Output)
+	cmd.Flag("rule", "Overrides the severity (off, info, warning or error) of a rule. It takes precedence over"+
+		" lint.rules of the project. Available rules: "+strings.Join(ruleNames, ", ")+".").
+		PlaceHolder("<rule>=<severity>").
+		SetValue(&instance.Rules)
+	cmd.Flag("failOn", "Fails if there is at least one finding of this severity or a more severe one.").
+		Envar("KUBOR_LINT_FAIL_ON").
+		Default(instance.FailOn.String()).
+		SetValue(&instance.FailOn)
+	instance.Schema.ConfigureFlags(cmd)
+	return nil
+}
+
+func (instance *Lint) ExecuteFromCli(*kingpin.ParseContext) error {
+	if instance.ProjectFactory == nil {
+		return fmt.Errorf("command not yet initialized")
+	}
+	validator, err := instance.Schema.NewValidator()
+	if err != nil {
+		return err
+	}
+	linter := &lint.Linter{
+		Severities: instance.Rules,
+		Validator:  validator,
+	}
+
+	if source, err := instance.ProjectFactory.Source(); err != nil {
+		linter.LintProjectError("", err)
+	} else if project, err := instance.createProject(); err != nil {
+		// The strict check usually explains the problem more precisely.
+		if !linter.LintSource(nil, source) {
+			linter.LintProjectError(source, err)
+		}
+	} else {
+		linter.LintSource(project, source)
+		if err := linter.Lint(project); err != nil {
+			return err
+		}
+	}
+
+	findings := linter.Findings()
+	switch instance.Output {
+	case "json":
+		err = lint.WriteJson(os.Stdout, findings)
+	case "sarif":
+		err = lint.WriteSarif(os.Stdout, findings, instance.version)
+	default:
+		err = lint.WriteText(os.Stdout, findings)
+	}
+	if err != nil {
+		return err
+	}
+	if failing := findings.AtLeast(instance.FailOn); len(failing) > 0 {
+		return fmt.Errorf("lint failed with %d findings of severity %v or above", len(failing), instance.FailOn)
+	}
+	return nil
+}
+
+// createProject creates the project without a connection to any cluster. The secrets
+// files are only decrypted if keys are available.
+func (instance *Lint) createProject() (*model.Project, error) {
+	if keys, err := instance.ProjectFactory.SecretKeys(); err != nil {
+		return nil, err
+	} else if keys.IsEmpty() {
+		return instance.ProjectFactory.CreateWithoutSecrets("")
+	}
+	return instance.ProjectFactory.Create("")
+}
@@ -0,0 +1,21 @@
+package command
+
+import (
+	"github.com/echocat/kubor/common"
+)
+
+func init() {
+	common.RegisterCliFactory(&Project{})
+}
+
+type Project struct {
+	Command
+}
+
+func (instance *Project) ConfigureCliCommands(context string, hc common.HasCommands, version string) error {
+	if context != "" {
+		return nil
+	}
+	cmd := hc.Command("project", "Inspect the project (.kubor.yml). see sub-commands.")
+	return common.ConfigureCliCommands("project", cmd, version)
+}
@@ -0,0 +1,57 @@
+package command
+
+import (
+	"fmt"
+	"github.com/alecthomas/kingpin"
+	"github.com/echocat/kubor/common"
+	"gopkg.in/yaml.v2"
+	"os"
+)
+
+func init() {
+	cmd := &ProjectShow{}
+	RegisterInitializable(cmd)
+	common.RegisterCliFactory(cmd)
+}
+
+type ProjectShow struct {
+	Command
+
+	Resolved bool
+}
+
+func (instance *ProjectShow) ConfigureCliCommands(context string, hc common.HasCommands, _ string) error {
+	if context != "project" {
+		return nil
+	}
+	cmd := hc.Command("show", "Shows the project file as it is.").
+		Action(instance.ExecuteFromCli)
+	cmd.Flag("resolved", "If set the effective project is shown: All files of extends and include are"+
+		" merged into it and all defaults are applied.").
+		Default(fmt.Sprint(instance.Resolved)).
+		BoolVar(&instance.Resolved)
+	return nil
+}
+
+func (instance *ProjectShow) ExecuteFromCli(*kingpin.ParseContext) error {
+	project, err := instance.createProjectWithoutSecrets()
+	if err != nil {
+		return err
+	}
+	if project.Source == "" {
+		return fmt.Errorf("there is no project file")
+	}
+	if !instance.Resolved {
+		if content, err := os.ReadFile(project.Source); err != nil {
+			return err
+		} else {
+			_, err := os.Stdout.Write(content)
+			return err
+		}
+	}
+	_, _ = fmt.Fprintf(os.Stdout, "# source: %s\n", project.Source)
+	for _, base := range project.Bases {
+		_, _ = fmt.Fprintf(os.Stdout, "# base: %s\n", base)
+	}
+	return yaml.NewEncoder(os.Stdout).Encode(project)
+}
@@ -1,6 +1,7 @@
 package command
 
 import (
+	"errors"
 	"fmt"
 	"github.com/echocat/kubor/common"
 	"io"
@@ -27,6 +28,7 @@ type Render struct {
 
 	TemplateFile string
 	SourceHint   bool
+	AllErrors    bool
 }
 
 func (instance *Render) ConfigureCliCommands(context string, hc common.HasCommands, version string) error {
@@ -48,6 +50,10 @@ func (instance *Render) ConfigureCliCommands(context string, hc common.HasComman
 		Envar("KUBOR_SOURCE_HINT").
 		Default(fmt.Sprint(instance.SourceHint)).
 		BoolVar(&instance.SourceHint)
+	cmd.Flag("allErrors", "If a template file could not be rendered, continue with the remaining ones and report all errors at the end.").
+		Envar("KUBOR_ALL_ERRORS").
+		Default(fmt.Sprint(instance.AllErrors)).
+		BoolVar(&instance.AllErrors)
 
 	return nil
 }
@@ -64,9 +70,17 @@ func (instance *Render) RunWithArguments(arguments Arguments) error {
 
 	var name string
 	var content []byte
+	var errs []error
 	first := true
 
-	for name, content, err = cp(); err == nil; name, content, err = cp() {
+	for name, content, err = cp(); err != io.EOF; name, content, err = cp() {
+		if err != nil {
+			if !instance.AllErrors {
+				return err
+			}
+			errs = append(errs, err)
+			continue
+		}
 		trimmed := strings.TrimSpace(string(content))
 		if len(trimmed) > 0 {
 			if first {
@@ -80,9 +94,6 @@ func (instance *Render) RunWithArguments(arguments Arguments) error {
 			fmt.Print(trimmed)
 		}
 	}
-	if err == io.EOF {
-		return nil
-	}
 
-	return err
+	return errors.Join(errs...)
 }
@@ -0,0 +1,77 @@
+package command
+
+import (
+	"fmt"
+	"github.com/echocat/kubor/common"
+	"github.com/echocat/kubor/model"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func init() {
+	common.RegisterCliFactory(&Secrets{})
+}
+
+type Secrets struct {
+	Command
+}
+
+func (instance *Secrets) ConfigureCliCommands(context string, hc common.HasCommands, version string) error {
+	if context != "" {
+		return nil
+	}
+	cmd := hc.Command("secrets", "Encrypt, decrypt and edit secrets files (see secrets.files of the project). see sub-commands.")
+	return common.ConfigureCliCommands("secrets", cmd, version)
+}
+
+// createProjectWithoutSecrets creates the project without a connection to a cluster
+// and without decrypting its secrets files, which might not be readable yet.
+func (instance *Command) createProjectWithoutSecrets() (*model.Project, error) {
+	if instance.ProjectFactory == nil {
+		return nil, fmt.Errorf("command not yet initialized")
+	}
+	return instance.ProjectFactory.CreateWithoutSecrets("")
+}
+
+func (instance *Command) secretKeys() (model.SecretKeys, error) {
+	if instance.ProjectFactory == nil {
+		return model.SecretKeys{}, fmt.Errorf("command not yet initialized")
+	}
+	if keys, err := instance.ProjectFactory.SecretKeys(); err != nil {
+		return model.SecretKeys{}, err
+	} else if keys.IsEmpty() {
+		return model.SecretKeys{}, model.ErrNoSecretKeys
+	} else {
+		return keys, nil
+	}
+}
+
+// writeSecretsFile writes the given content to the given file. The file is replaced
+// atomically to never leave a partially written secrets file behind.
+func writeSecretsFile(file string, content []byte) error {
+	temp, err := os.CreateTemp(filepath.Dir(file), "."+filepath.Base(file)+".*")
+	if err != nil {
+		return fmt.Errorf("cannot write '%s': %w", file, err)
+	}
+	//noinspection GoUnhandledErrorResult
+	defer os.Remove(temp.Name())
+	if _, err := temp.Write(content); err != nil {
+		_ = temp.Close()
+		return fmt.Errorf("cannot write '%s': %w", file, err)
+	} else if err := temp.Close(); err != nil {
+		return fmt.Errorf("cannot write '%s': %w", file, err)
+	} else if err := os.Chmod(temp.Name(), 0600); err != nil {
+		return fmt.Errorf("cannot write '%s': %w", file, err)
+	} else if err := os.Rename(temp.Name(), file); err != nil {
+		return fmt.Errorf("cannot write '%s': %w", file, err)
+	}
+	return nil
+}
+
+// encryptedFileNameOf returns the name of the encrypted variant of the given
+// plain file: values.yml becomes values.enc.yml
+func encryptedFileNameOf(file string) string {
+	ext := filepath.Ext(file)
+	return strings.TrimSuffix(file, ext) + ".enc" + ext
+}
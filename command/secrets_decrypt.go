@@ -0,0 +1,51 @@
+package command
+
+import (
+	"github.com/alecthomas/kingpin"
+	"github.com/echocat/kubor/common"
+	"os"
+)
+
+func init() {
+	cmd := &SecretsDecrypt{}
+	RegisterInitializable(cmd)
+	common.RegisterCliFactory(cmd)
+}
+
+type SecretsDecrypt struct {
+	Command
+
+	File   string
+	Output string
+}
+
+func (instance *SecretsDecrypt) ConfigureCliCommands(context string, hc common.HasCommands, _ string) error {
+	if context != "secrets" {
+		return nil
+	}
+	cmd := hc.Command("decrypt", "Decrypts the given secrets file using the keys provided by KUBOR_SECRETS_KEY or --secretsKeyFile.").
+		Action(instance.ExecuteFromCli)
+	cmd.Arg("file", "Encrypted (age or OpenPGP) file to decrypt.").
+		Required().
+		StringVar(&instance.File)
+	cmd.Flag("output", "File to write the plain content to. Default: stdout").
+		Short('o').
+		StringVar(&instance.Output)
+	return nil
+}
+
+func (instance *SecretsDecrypt) ExecuteFromCli(*kingpin.ParseContext) error {
+	keys, err := instance.secretKeys()
+	if err != nil {
+		return err
+	}
+	plain, err := keys.DecryptFile(instance.File)
+	if err != nil {
+		return err
+	}
+	if instance.Output == "" || instance.Output == "-" {
+		_, err := os.Stdout.Write(plain)
+		return err
+	}
+	return writeSecretsFile(instance.Output, plain)
+}
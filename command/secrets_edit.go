@@ -0,0 +1,118 @@
+package command
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/alecthomas/kingpin"
+	"github.com/echocat/kubor/common"
+	"gopkg.in/yaml.v2"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+func init() {
+	cmd := &SecretsEdit{}
+	RegisterInitializable(cmd)
+	common.RegisterCliFactory(cmd)
+}
+
+type SecretsEdit struct {
+	Command
+
+	File string
+}
+
+func (instance *SecretsEdit) ConfigureCliCommands(context string, hc common.HasCommands, _ string) error {
+	if context != "secrets" {
+		return nil
+	}
+	cmd := hc.Command("edit", "Decrypts the given secrets file, opens it in $EDITOR (default: vi) and encrypts it again"+
+		" for all secrets.recipients of the project. If the file does not exist yet it will be created.").
+		Action(instance.ExecuteFromCli)
+	cmd.Arg("file", "Encrypted (age or OpenPGP) file to edit.").
+		Required().
+		StringVar(&instance.File)
+	return nil
+}
+
+func (instance *SecretsEdit) ExecuteFromCli(*kingpin.ParseContext) error {
+	project, err := instance.createProjectWithoutSecrets()
+	if err != nil {
+		return err
+	}
+	var plain []byte
+	if _, err := os.Stat(instance.File); err == nil {
+		keys, err := instance.secretKeys()
+		if err != nil {
+			return err
+		}
+		if plain, err = keys.DecryptFile(instance.File); err != nil {
+			return err
+		}
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("cannot read '%s': %w", instance.File, err)
+	}
+
+	temp, err := os.CreateTemp("", "kubor-secrets-*.yml")
+	if err != nil {
+		return err
+	}
+	// Like kubectl edit the edited file is kept if its content could not be stored.
+	kept := false
+	defer func() {
+		if !kept {
+			_ = os.Remove(temp.Name())
+		}
+	}()
+	keepEdited := func(err error) error {
+		kept = true
+		return fmt.Errorf("%w; a copy of your changes has been stored unencrypted to '%s'", err, temp.Name())
+	}
+	if err := temp.Chmod(0600); err != nil {
+		_ = temp.Close()
+		return err
+	} else if _, err := temp.Write(plain); err != nil {
+		_ = temp.Close()
+		return err
+	} else if err := temp.Close(); err != nil {
+		return err
+	}
+
+	if err := instance.runEditor(temp.Name()); err != nil {
+		return err
+	}
+	edited, err := os.ReadFile(temp.Name())
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(edited, plain) {
+		return nil
+	}
+	var check interface{}
+	if err := yaml.Unmarshal(edited, &check); err != nil {
+		return keepEdited(fmt.Errorf("edited content of '%s' is not valid YAML; nothing was changed: %w", instance.File, err))
+	}
+	encrypted, err := project.Secrets.Encrypt(project.Root, edited)
+	if err != nil {
+		return keepEdited(fmt.Errorf("cannot encrypt '%s': %w", instance.File, err))
+	}
+	if err := writeSecretsFile(instance.File, encrypted); err != nil {
+		return keepEdited(err)
+	}
+	return nil
+}
+
+func (instance *SecretsEdit) runEditor(file string) error {
+	editor := strings.TrimSpace(os.Getenv("EDITOR"))
+	if editor == "" {
+		editor = "vi"
+	}
+	args := strings.Fields(editor)
+	cmd := exec.Command(args[0], append(args[1:], file)...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("editor '%s' failed: %w", editor, err)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package command
+
+import (
+	"fmt"
+	"github.com/alecthomas/kingpin"
+	"github.com/echocat/kubor/common"
+	"os"
+)
+
+func init() {
+	cmd := &SecretsEncrypt{}
+	RegisterInitializable(cmd)
+	common.RegisterCliFactory(cmd)
+}
+
+type SecretsEncrypt struct {
+	Command
+
+	File   string
+	Output string
+}
+
+func (instance *SecretsEncrypt) ConfigureCliCommands(context string, hc common.HasCommands, _ string) error {
+	if context != "secrets" {
+		return nil
+	}
+	cmd := hc.Command("encrypt", "Encrypts the given plain file for all secrets.recipients of the project.").
+		Action(instance.ExecuteFromCli)
+	cmd.Arg("file", "Plain YAML or JSON file to encrypt.").
+		Required().
+		StringVar(&instance.File)
+	cmd.Flag("output", "File to write the encrypted content to. Default: <file> with .enc inserted before its extension"+
+		" (values.yml becomes values.enc.yml). Use - for stdout.").
+		Short('o').
+		StringVar(&instance.Output)
+	return nil
+}
+
+func (instance *SecretsEncrypt) ExecuteFromCli(*kingpin.ParseContext) error {
+	project, err := instance.createProjectWithoutSecrets()
+	if err != nil {
+		return err
+	}
+	plain, err := os.ReadFile(instance.File)
+	if err != nil {
+		return fmt.Errorf("cannot read '%s': %w", instance.File, err)
+	}
+	encrypted, err := project.Secrets.Encrypt(project.Root, plain)
+	if err != nil {
+		return fmt.Errorf("cannot encrypt '%s': %w", instance.File, err)
+	}
+	output := instance.Output
+	if output == "" {
+		output = encryptedFileNameOf(instance.File)
+	}
+	if output == "-" {
+		_, err := os.Stdout.Write(encrypted)
+		return err
+	}
+	return writeSecretsFile(output, encrypted)
+}
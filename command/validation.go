@@ -0,0 +1,43 @@
+package command
+
+import (
+	"github.com/alecthomas/kingpin"
+	"github.com/echocat/kubor/kubernetes/openapi"
+	"strings"
+)
+
+// SchemaValidation holds the flags which configure the validation of rendered objects
+// against the OpenAPI schemas bundled with kubor.
+type SchemaValidation struct {
+	KubernetesVersion string
+	Crds              []string
+}
+
+func (instance *SchemaValidation) ConfigureFlags(cmd *kingpin.CmdClause) {
+	cmd.Flag("kubernetesVersion", "Version of Kubernetes whose schemas are used to validate objects."+
+		" Available: "+strings.Join(openapi.Versions(), ", ")+".").
+		PlaceHolder("<major>.<minor>").
+		Envar("KUBOR_KUBERNETES_VERSION").
+		Default(instance.KubernetesVersion).
+		StringVar(&instance.KubernetesVersion)
+	cmd.Flag("crd", "YAML or JSON file (or directory of such files) with CustomResourceDefinitions whose"+
+		" schemas are used to validate custom resources. Could be specified multiple times.").
+		PlaceHolder("<file>").
+		Envar("KUBOR_CRDS").
+		StringsVar(&instance.Crds)
+}
+
+// NewValidator creates a validator for the configured Kubernetes version with all
+// configured CRDs.
+func (instance *SchemaValidation) NewValidator() (*openapi.Validator, error) {
+	result, err := openapi.NewValidator(instance.KubernetesVersion)
+	if err != nil {
+		return nil, err
+	}
+	for _, crd := range instance.Crds {
+		if err := result.AddCrds(crd); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
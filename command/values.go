@@ -1,14 +1,34 @@
 package command
 
 import (
+	"encoding/json"
+	"fmt"
 	"github.com/alecthomas/kingpin"
 	"github.com/echocat/kubor/common"
+	"github.com/echocat/kubor/model"
 	"gopkg.in/yaml.v2"
 	"os"
+	"strings"
 )
 
+type ValuesOutput string
+
+func (instance *ValuesOutput) Set(plain string) error {
+	if plain != "json" && plain != "yaml" {
+		return fmt.Errorf("unsupported output format: %s", plain)
+	}
+	*instance = ValuesOutput(plain)
+	return nil
+}
+
+func (instance ValuesOutput) String() string {
+	return string(instance)
+}
+
 func init() {
-	cmd := &Values{}
+	cmd := &Values{
+		Output: ValuesOutput("yaml"),
+	}
 	cmd.Parent = cmd
 	RegisterInitializable(cmd)
 	common.RegisterCliFactory(cmd)
@@ -16,20 +36,138 @@ func init() {
 
 type Values struct {
 	Command
+
+	Output      ValuesOutput
+	Path        string
+	Schema      bool
+	Explain     bool
+	ShowSecrets bool
 }
 
 func (instance *Values) ConfigureCliCommands(context string, hc common.HasCommands, version string) error {
 	if context != "" {
 		return nil
 	}
-	hc.Command("values", "Get the aggregated values used by the project based on the given parameters.").
+	cmd := hc.Command("values", "Get the aggregated values used by the project based on the given parameters."+
+		" The values of all sources (defaults, values files, conditional values and CLI) are merged in this order.").
 		Action(func(context *kingpin.ParseContext) error {
 			return instance.Run()
 		})
+	cmd.Arg("path", "If set only the values at this dotted path (like \"image.tag\") are shown.").
+		StringVar(&instance.Path)
+	cmd.Flag("output", "Specifies how to render the output.").
+		Short('o').
+		Default(instance.Output.String()).
+		SetValue(&instance.Output)
+	cmd.Flag("schema", "If set the values schema (see valuesSchema) is shown instead of the values."+
+		" The default of every property is set to its current value (including the defaults of the schema itself).").
+		Default(fmt.Sprint(instance.Schema)).
+		BoolVar(&instance.Schema)
+	cmd.Flag("explain", "If set every value is shown together with the ordered list of sources"+
+		" (default, valuesFile, workspace, secretsFile, conditional or cli) which have set or overridden it.").
+		Default(fmt.Sprint(instance.Explain)).
+		BoolVar(&instance.Explain)
+	cmd.Flag("showSecrets", "If set values with secret-looking keys (like password or token) and values of secrets files are not masked.").
+		Default(fmt.Sprint(instance.ShowSecrets)).
+		BoolVar(&instance.ShowSecrets)
 	return nil
 }
 
+// ToleratesValuesSchemaViolations returns true for --schema and --explain which are
+// used to find out why values violate the values schema.
+func (instance *Values) ToleratesValuesSchemaViolations() bool {
+	return instance.Schema || instance.Explain
+}
+
 func (instance *Values) RunWithArguments(arguments Arguments) error {
+	if err := instance.print(arguments); err != nil {
+		return err
+	}
+	if violations := arguments.Project.ValuesSchemaViolations; violations != nil {
+		return violations
+	}
+	return nil
+}
+
+func (instance *Values) print(arguments Arguments) error {
+	var values interface{}
+	if instance.Explain {
+		return instance.explain(arguments.Project)
+	} else if instance.Schema {
+		if schema, err := arguments.Project.ValuesSchema.Load(arguments.Project.Root); err != nil {
+			return err
+		} else if schema == nil {
+			return fmt.Errorf("there is no valuesSchema defined in '%s'", arguments.Project.Source)
+		} else {
+			values = schema.WithDefaults(instance.maskSecretsOf(arguments.Project))
+		}
+	} else if selected, err := instance.selectPath(instance.maskSecretsOf(arguments.Project)); err != nil {
+		return err
+	} else {
+		values = selected
+	}
+	if instance.Output == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(values)
+	}
 	enc := yaml.NewEncoder(os.Stdout)
-	return enc.Encode(arguments.Project.Values)
+	return enc.Encode(values)
+}
+
+func (instance *Values) selectPath(values model.Values) (interface{}, error) {
+	var current interface{} = map[string]interface{}(values)
+	if instance.Path == "" {
+		return current, nil
+	}
+	for _, element := range strings.Split(instance.Path, ".") {
+		if m, ok := current.(map[string]interface{}); !ok {
+			return nil, fmt.Errorf("there is no value at path '%s'", instance.Path)
+		} else if v, ok := m[element]; !ok {
+			return nil, fmt.Errorf("there is no value at path '%s'", instance.Path)
+		} else {
+			current = v
+		}
+	}
+	return current, nil
+}
+
+func (instance *Values) maskSecretsOf(project *model.Project) model.Values {
+	if instance.ShowSecrets {
+		return project.Values
+	}
+	return project.ValuesProvenance.MaskSecrets(project.Values)
+}
+
+func (instance *Values) explain(project *model.Project) error {
+	explained := project.ValuesProvenance.Explain(project.Values, !instance.ShowSecrets)
+	if instance.Path != "" {
+		filtered := explained[:0]
+		for _, candidate := range explained {
+			if candidate.Path == instance.Path || strings.HasPrefix(candidate.Path, instance.Path+".") {
+				filtered = append(filtered, candidate)
+			}
+		}
+		explained = filtered
+	}
+	switch instance.Output {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(explained)
+	default:
+		for _, candidate := range explained {
+			if !candidate.Present {
+				fmt.Printf("%s (absent)\n", candidate.Path)
+			} else if encoded, err := json.Marshal(candidate.Value); err != nil {
+				return err
+			} else {
+				fmt.Printf("%s = %s\n", candidate.Path, string(encoded))
+			}
+			for i, source := range candidate.Sources {
+				fmt.Printf("    %d. %v\n", i+1, source)
+			}
+		}
+		return nil
+	}
 }
@@ -0,0 +1,216 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"github.com/echocat/kubor/model"
+	"github.com/echocat/slf4g"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"reflect"
+)
+
+var namespaceResource = schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}
+
+// NamespaceTask creates and deletes the namespaces claimed by a project if
+// model.Claim.ManageNamespaces is enabled; otherwise it does nothing.
+type NamespaceTask struct {
+	project *model.Project
+	runtime Runtime
+	client  dynamic.Interface
+}
+
+func NewNamespaceTask(project *model.Project, runtime Runtime, client dynamic.Interface) NamespaceTask {
+	return NamespaceTask{
+		project: project,
+		runtime: runtime,
+		client:  client,
+	}
+}
+
+// Ensure creates all missing claimed namespaces. Namespaces which were created by
+// kubor for this project before get the configured labels and annotations again; all
+// others (including the ones of other projects) are left untouched.
+func (instance NamespaceTask) Ensure() error {
+	if !instance.project.Claim.ManageNamespaces {
+		return nil
+	}
+	resource := instance.client.Resource(namespaceResource)
+	for _, namespace := range instance.project.Claim.Namespaces {
+		l := log.With("namespace", namespace)
+		existing, err := resource.Get(context.Background(), namespace.String(), metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			if _, err := resource.Create(context.Background(), instance.newNamespace(namespace), metav1.CreateOptions{}); err != nil {
+				return fmt.Errorf("cannot create namespace %v: %w", namespace, err)
+			}
+			l.Infof("Namespace %v created.", namespace)
+		} else if err != nil {
+			return fmt.Errorf("cannot get namespace %v: %w", namespace, err)
+		} else if !instance.isCreatedByProject(existing) {
+			l.Debugf("Namespace %v was not created by kubor for this project and will therefore not be touched.", namespace)
+		} else if instance.ensureMetadata(existing) {
+			if _, err := resource.Update(context.Background(), existing, metav1.UpdateOptions{}); err != nil {
+				return fmt.Errorf("cannot update namespace %v: %w", namespace, err)
+			}
+			l.Infof("Namespace %v updated.", namespace)
+		}
+	}
+	return nil
+}
+
+// DeleteIfEmpty deletes all claimed namespaces which were created by kubor for this
+// project and which do not contain any objects anymore. All namespaced resources the server knows are
+// checked, not only the claimed kinds; objects of other projects or created by hand
+// keep the namespace alive.
+func (instance NamespaceTask) DeleteIfEmpty() error {
+	if !instance.project.Claim.ManageNamespaces {
+		return nil
+	}
+	resource := instance.client.Resource(namespaceResource)
+	for _, namespace := range instance.project.Claim.Namespaces {
+		l := log.With("namespace", namespace)
+		existing, err := resource.Get(context.Background(), namespace.String(), metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return fmt.Errorf("cannot get namespace %v: %w", namespace, err)
+		} else if !instance.isCreatedByProject(existing) {
+			l.Debugf("Namespace %v was not created by kubor for this project and will therefore be kept.", namespace)
+			continue
+		}
+
+		if reference, err := instance.findRemainingObject(namespace); err != nil {
+			return err
+		} else if reference != "" {
+			l.With("reference", reference).
+				Infof("Namespace %v still contains %s and will therefore be kept.", namespace, reference)
+			continue
+		}
+
+		if err := resource.Delete(context.Background(), namespace.String(), metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("cannot delete namespace %v: %w", namespace, err)
+		}
+		l.Infof("Namespace %v deleted.", namespace)
+	}
+	return nil
+}
+
+func (instance NamespaceTask) newNamespace(namespace model.Namespace) *unstructured.Unstructured {
+	result := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Namespace",
+		"metadata": map[string]interface{}{
+			"name": namespace.String(),
+		},
+	}}
+	instance.ensureMetadata(result)
+	return result
+}
+
+// ensureMetadata sets the configured labels and annotations on the given namespace
+// and returns true if something was changed.
+func (instance NamespaceTask) ensureMetadata(target *unstructured.Unstructured) (changed bool) {
+	claim := instance.project.Claim
+
+	labels := target.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	for key, value := range claim.NamespaceLabels {
+		labels[key] = value
+	}
+
+	annotations := target.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	for key, value := range claim.NamespaceAnnotations {
+		annotations[key] = value
+	}
+	if _, exist := annotations[model.AnnotationNamespaceCreatedBy]; !exist {
+		annotations[model.AnnotationNamespaceCreatedBy] = instance.creator()
+	}
+
+	changed = !reflect.DeepEqual(labels, target.GetLabels()) || !reflect.DeepEqual(annotations, target.GetAnnotations())
+	if len(labels) > 0 {
+		target.SetLabels(labels)
+	}
+	target.SetAnnotations(annotations)
+	return
+}
+
+func (instance NamespaceTask) creator() string {
+	if instance.project.GroupId != "" {
+		return fmt.Sprintf("%v:%v", instance.project.GroupId, instance.project.ArtifactId)
+	}
+	return instance.project.ArtifactId.String()
+}
+
+// isCreatedByProject returns true if the target was created by kubor for the project
+// of this task. Namespaces of other projects are never touched.
+func (instance NamespaceTask) isCreatedByProject(target *unstructured.Unstructured) bool {
+	creator, exist := target.GetAnnotations()[model.AnnotationNamespaceCreatedBy]
+	return exist && creator == instance.creator()
+}
+
+// findRemainingObject returns a description of the first object inside of the given
+// namespace which prevents its deletion; an empty string if there is none. If some
+// API groups could not be discovered the namespace is never considered as empty.
+func (instance NamespaceTask) findRemainingObject(namespace model.Namespace) (string, error) {
+	gvrs, err := instance.runtime.NamespacedResources()
+	if discovery.IsGroupDiscoveryFailedError(err) {
+		return fmt.Sprintf("objects of API groups which cannot be discovered (%v)", err), nil
+	} else if err != nil {
+		return "", err
+	}
+
+	for _, gvr := range gvrs {
+		resource := instance.client.Resource(gvr).Namespace(namespace.String())
+		opts := metav1.ListOptions{}
+		for {
+			list, err := resource.List(context.Background(), opts)
+			if errors.IsNotFound(err) || errors.IsMethodNotSupported(err) {
+				break
+			} else if err != nil {
+				return "", fmt.Errorf("cannot collect existing elements of type %v in namespace %v: %w", gvr, namespace, err)
+			}
+			for _, candidate := range list.Items {
+				if !isImplicitNamespaceContent(&candidate) {
+					return fmt.Sprintf("%v %s", model.GroupVersionKind(candidate.GroupVersionKind()), candidate.GetName()), nil
+				}
+			}
+			if v := list.GetContinue(); v != "" {
+				opts.Continue = v
+			} else {
+				break
+			}
+		}
+	}
+	return "", nil
+}
+
+// isImplicitNamespaceContent returns true for objects which do not make a namespace
+// non-empty: objects which are deleted or owned by others, events and the objects
+// Kubernetes creates inside of every namespace.
+func isImplicitNamespaceContent(candidate *unstructured.Unstructured) bool {
+	if candidate.GetDeletionTimestamp() != nil || len(candidate.GetOwnerReferences()) > 0 {
+		return true
+	}
+	switch candidate.GroupVersionKind().GroupKind() {
+	case schema.GroupKind{Kind: "Event"}, schema.GroupKind{Group: "events.k8s.io", Kind: "Event"}:
+		return true
+	case schema.GroupKind{Kind: "ServiceAccount"}:
+		return candidate.GetName() == "default"
+	case schema.GroupKind{Kind: "ConfigMap"}:
+		return candidate.GetName() == "kube-root-ca.crt"
+	case schema.GroupKind{Kind: "Secret"}:
+		t, _, _ := unstructured.NestedString(candidate.Object, "type")
+		return t == "kubernetes.io/service-account-token"
+	default:
+		return false
+	}
+}
@@ -0,0 +1,230 @@
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"github.com/echocat/kubor/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	dynamicFake "k8s.io/client-go/dynamic/fake"
+	"testing"
+)
+
+var (
+	configMapsResource      = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	serviceAccountsResource = schema.GroupVersionResource{Version: "v1", Resource: "serviceaccounts"}
+	widgetsResource         = schema.GroupVersionResource{Group: "example.org", Version: "v1", Resource: "widgets"}
+)
+
+// namespaceTestRuntime is a Runtime which only knows the given namespaced resources.
+type namespaceTestRuntime struct {
+	Runtime
+	resources []schema.GroupVersionResource
+	err       error
+}
+
+func (instance namespaceTestRuntime) NamespacedResources() ([]schema.GroupVersionResource, error) {
+	return instance.resources, instance.err
+}
+
+func newNamespaceTestProject(namespaces ...model.Namespace) *model.Project {
+	project := model.NewProject()
+	project.GroupId = "shop"
+	project.ArtifactId = "api"
+	project.Claim.ManageNamespaces = true
+	project.Claim.Namespaces = namespaces
+	// Only a single kind is claimed to ensure that emptiness does not depend on it.
+	project.Claim.GroupVersionKinds = model.GroupVersionKinds{{Version: "v1", Kind: "Service"}: true}
+	return &project
+}
+
+func newNamespaceTestTask(project *model.Project, r namespaceTestRuntime, objects ...runtime.Object) (NamespaceTask, *dynamicFake.FakeDynamicClient) {
+	client := dynamicFake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		namespaceResource:       "NamespaceList",
+		configMapsResource:      "ConfigMapList",
+		serviceAccountsResource: "ServiceAccountList",
+		widgetsResource:         "WidgetList",
+	}, objects...)
+	return NewNamespaceTask(project, r, client), client
+}
+
+func newNamespaceTestObject(apiVersion, kind, namespace, name string, annotations map[string]string) *unstructured.Unstructured {
+	result := &unstructured.Unstructured{}
+	result.SetAPIVersion(apiVersion)
+	result.SetKind(kind)
+	result.SetNamespace(namespace)
+	result.SetName(name)
+	if annotations != nil {
+		result.SetAnnotations(annotations)
+	}
+	return result
+}
+
+func newNamespaceTestNamespace(name string, createdBy string) *unstructured.Unstructured {
+	var annotations map[string]string
+	if createdBy != "" {
+		annotations = map[string]string{model.AnnotationNamespaceCreatedBy: createdBy}
+	}
+	return newNamespaceTestObject("v1", "Namespace", "", name, annotations)
+}
+
+func getNamespaceTestNamespace(t *testing.T, client *dynamicFake.FakeDynamicClient, name string) *unstructured.Unstructured {
+	t.Helper()
+	result, err := client.Resource(namespaceResource).Get(context.Background(), name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	require.NoError(t, err)
+	return result
+}
+
+func Test_NamespaceTask_Ensure(t *testing.T) {
+	project := newNamespaceTestProject("missing", "foreign", "outdated", "other-project")
+	project.Claim.NamespaceLabels = map[string]string{"pod-security.kubernetes.io/enforce": "restricted"}
+	instance, client := newNamespaceTestTask(project, namespaceTestRuntime{},
+		newNamespaceTestNamespace("foreign", ""),
+		newNamespaceTestNamespace("outdated", "shop:api"),
+		newNamespaceTestNamespace("other-project", "shop:other"),
+	)
+
+	err := instance.Ensure()
+
+	require.NoError(t, err)
+	created := getNamespaceTestNamespace(t, client, "missing")
+	require.NotNil(t, created)
+	assert.Equal(t, map[string]string{"pod-security.kubernetes.io/enforce": "restricted"}, created.GetLabels())
+	assert.Equal(t, map[string]string{model.AnnotationNamespaceCreatedBy: "shop:api"}, created.GetAnnotations())
+
+	foreign := getNamespaceTestNamespace(t, client, "foreign")
+	assert.Empty(t, foreign.GetLabels())
+	assert.Empty(t, foreign.GetAnnotations())
+
+	outdated := getNamespaceTestNamespace(t, client, "outdated")
+	assert.Equal(t, map[string]string{"pod-security.kubernetes.io/enforce": "restricted"}, outdated.GetLabels())
+	assert.Equal(t, map[string]string{model.AnnotationNamespaceCreatedBy: "shop:api"}, outdated.GetAnnotations())
+
+	other := getNamespaceTestNamespace(t, client, "other-project")
+	assert.Empty(t, other.GetLabels(), "created by another project")
+	assert.Equal(t, map[string]string{model.AnnotationNamespaceCreatedBy: "shop:other"}, other.GetAnnotations())
+}
+
+func Test_NamespaceTask_does_nothing_if_not_managed(t *testing.T) {
+	project := newNamespaceTestProject("missing", "empty")
+	project.Claim.ManageNamespaces = false
+	instance, client := newNamespaceTestTask(project, namespaceTestRuntime{},
+		newNamespaceTestNamespace("empty", "shop:api"),
+	)
+
+	require.NoError(t, instance.Ensure())
+	require.NoError(t, instance.DeleteIfEmpty())
+
+	assert.Nil(t, getNamespaceTestNamespace(t, client, "missing"))
+	assert.NotNil(t, getNamespaceTestNamespace(t, client, "empty"))
+}
+
+func Test_NamespaceTask_ensureMetadata(t *testing.T) {
+	project := newNamespaceTestProject()
+	project.GroupId = ""
+	project.Claim.NamespaceLabels = map[string]string{"a": "1"}
+	project.Claim.NamespaceAnnotations = map[string]string{"b": "2"}
+	instance := NewNamespaceTask(project, nil, nil)
+	target := newNamespaceTestNamespace("ns", "")
+	target.SetLabels(map[string]string{"own": "label"})
+
+	assert.True(t, instance.ensureMetadata(target))
+	assert.Equal(t, map[string]string{"own": "label", "a": "1"}, target.GetLabels())
+	assert.Equal(t, map[string]string{"b": "2", model.AnnotationNamespaceCreatedBy: "api"}, target.GetAnnotations())
+
+	assert.False(t, instance.ensureMetadata(target), "second call should not change anything")
+}
+
+func Test_NamespaceTask_DeleteIfEmpty(t *testing.T) {
+	r := namespaceTestRuntime{resources: []schema.GroupVersionResource{
+		configMapsResource, serviceAccountsResource, widgetsResource,
+	}}
+	project := newNamespaceTestProject("empty", "foreign", "other-project", "other-kind", "missing")
+	instance, client := newNamespaceTestTask(project, r,
+		newNamespaceTestNamespace("empty", "shop:api"),
+		newNamespaceTestObject("v1", "ServiceAccount", "empty", "default", nil),
+		newNamespaceTestObject("v1", "ConfigMap", "empty", "kube-root-ca.crt", nil),
+		newNamespaceTestNamespace("foreign", ""),
+		newNamespaceTestNamespace("other-project", "shop:other"),
+		newNamespaceTestNamespace("other-kind", "shop:api"),
+		newNamespaceTestObject("example.org/v1", "Widget", "other-kind", "left-over", nil),
+	)
+
+	err := instance.DeleteIfEmpty()
+
+	require.NoError(t, err)
+	assert.Nil(t, getNamespaceTestNamespace(t, client, "empty"))
+	assert.NotNil(t, getNamespaceTestNamespace(t, client, "foreign"), "not created by kubor")
+	assert.NotNil(t, getNamespaceTestNamespace(t, client, "other-project"), "created by another project")
+	assert.NotNil(t, getNamespaceTestNamespace(t, client, "other-kind"), "contains a kind which is not claimed")
+}
+
+func Test_NamespaceTask_DeleteIfEmpty_keeps_namespace_if_discovery_failed(t *testing.T) {
+	r := namespaceTestRuntime{err: &discovery.ErrGroupDiscoveryFailed{Groups: map[schema.GroupVersion]error{
+		{Group: "metrics.k8s.io", Version: "v1beta1"}: errors.New("service unavailable"),
+	}}}
+	project := newNamespaceTestProject("empty")
+	instance, client := newNamespaceTestTask(project, r,
+		newNamespaceTestNamespace("empty", "shop:api"),
+	)
+
+	err := instance.DeleteIfEmpty()
+
+	require.NoError(t, err)
+	assert.NotNil(t, getNamespaceTestNamespace(t, client, "empty"))
+}
+
+func Test_NamespaceTask_DeleteIfEmpty_fails_if_discovery_fails(t *testing.T) {
+	r := namespaceTestRuntime{err: errors.New("expected")}
+	project := newNamespaceTestProject("empty")
+	instance, client := newNamespaceTestTask(project, r,
+		newNamespaceTestNamespace("empty", "shop:api"),
+	)
+
+	err := instance.DeleteIfEmpty()
+
+	assert.EqualError(t, err, "expected")
+	assert.NotNil(t, getNamespaceTestNamespace(t, client, "empty"))
+}
+
+func Test_isImplicitNamespaceContent(t *testing.T) {
+	owned := newNamespaceTestObject("v1", "ConfigMap", "ns", "owned", nil)
+	owned.SetOwnerReferences([]metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "Deployment", Name: "api"}})
+	deleted := newNamespaceTestObject("v1", "ConfigMap", "ns", "deleted", nil)
+	now := metav1.Now()
+	deleted.SetDeletionTimestamp(&now)
+	token := newNamespaceTestObject("v1", "Secret", "ns", "token", nil)
+	token.Object["type"] = "kubernetes.io/service-account-token"
+
+	cases := []struct {
+		name     string
+		target   *unstructured.Unstructured
+		expected bool
+	}{
+		{"owned", owned, true},
+		{"deleted", deleted, true},
+		{"event", newNamespaceTestObject("v1", "Event", "ns", "e", nil), true},
+		{"events.k8s.io event", newNamespaceTestObject("events.k8s.io/v1", "Event", "ns", "e", nil), true},
+		{"default service account", newNamespaceTestObject("v1", "ServiceAccount", "ns", "default", nil), true},
+		{"other service account", newNamespaceTestObject("v1", "ServiceAccount", "ns", "api", nil), false},
+		{"root ca", newNamespaceTestObject("v1", "ConfigMap", "ns", "kube-root-ca.crt", nil), true},
+		{"other config map", newNamespaceTestObject("v1", "ConfigMap", "ns", "api", nil), false},
+		{"service account token", token, true},
+		{"other secret", newNamespaceTestObject("v1", "Secret", "ns", "api", nil), false},
+		{"custom resource", newNamespaceTestObject("example.org/v1", "Widget", "ns", "w", nil), false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.expected, isImplicitNamespaceContent(c.target))
+		})
+	}
+}
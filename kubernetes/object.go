@@ -3,6 +3,7 @@ package kubernetes
 import (
 	"fmt"
 	"github.com/echocat/kubor/model"
+	"github.com/echocat/slf4g"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	rbacv1alpha1 "k8s.io/api/rbac/v1alpha1"
@@ -13,6 +14,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"reflect"
@@ -94,13 +96,7 @@ func GetObjectReference(object runtime.Object, by ObjectValidator) (model.Object
 	}
 	namespace := model.Namespace(objv.GetNamespace())
 
-	var namespaceExpectation bool
-	if expectation := by.IsNamespaced(gvk); expectation != nil {
-		namespaceExpectation = *expectation
-	} else {
-		_, found := expectedNamespaceAbsentGvks[gvk]
-		namespaceExpectation = !found
-	}
+	namespaceExpectation := expectNamespace(gvk, by)
 	if namespace == "" && namespaceExpectation {
 		return model.ObjectReference{}, fmt.Errorf("meta.namespace is not set or empty, but requird for %v", gvk)
 	} else if namespace != "" && !namespaceExpectation {
@@ -123,3 +119,64 @@ func GetObjectReference(object runtime.Object, by ObjectValidator) (model.Object
 		Namespace:        namespace,
 	}, nil
 }
+
+// expectNamespace returns true if objects of the given kind are expected to be
+// namespaced; the ObjectValidator overrides the built-in expectations.
+func expectNamespace(gvk model.GroupVersionKind, by ObjectValidator) bool {
+	if expectation := by.IsNamespaced(gvk); expectation != nil {
+		return *expectation
+	}
+	_, found := expectedNamespaceAbsentGvks[gvk]
+	return !found
+}
+
+// NamespaceDefaulter sets the model.Project.DefaultNamespace on all namespaced objects
+// without metadata.namespace.
+type NamespaceDefaulter struct {
+	project *model.Project
+	runtime Runtime
+}
+
+// NewNamespaceDefaulter creates a new NamespaceDefaulter for the given project. If
+// runtime is not nil the server is asked which kinds are namespaced if this is not
+// overridden by model.Scheme.Namespaced.
+func NewNamespaceDefaulter(project *model.Project, runtime Runtime) NamespaceDefaulter {
+	return NamespaceDefaulter{
+		project: project,
+		runtime: runtime,
+	}
+}
+
+// Default implements model.PrepareObject.
+func (instance NamespaceDefaulter) Default(object *unstructured.Unstructured) (modified bool, err error) {
+	namespace := instance.project.DefaultNamespace
+	if namespace == "" || object.GetNamespace() != "" {
+		return false, nil
+	}
+	gvk := model.GroupVersionKind(object.GroupVersionKind()).Normalize()
+	if gvk.Kind == "" || gvk.Version == "" {
+		// Will be reported by GetObjectReference.
+		return false, nil
+	}
+	if !instance.isNamespaced(gvk) {
+		return false, nil
+	}
+	object.SetNamespace(namespace.String())
+	return true, nil
+}
+
+func (instance NamespaceDefaulter) isNamespaced(gvk model.GroupVersionKind) bool {
+	if expectation := instance.project.Scheme.IsNamespaced(gvk); expectation != nil {
+		return *expectation
+	}
+	if instance.runtime != nil {
+		if expectation, err := instance.runtime.IsNamespaced(gvk); err != nil {
+			log.WithError(err).
+				With("gvk", gvk).
+				Debugf("Cannot discover if %v is namespaced; fallback to built-in expectations.", gvk)
+		} else if expectation != nil {
+			return *expectation
+		}
+	}
+	return expectNamespace(gvk, instance.project.Scheme)
+}
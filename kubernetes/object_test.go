@@ -0,0 +1,128 @@
+package kubernetes
+
+import (
+	"errors"
+	"github.com/echocat/kubor/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"testing"
+)
+
+// namespacedTestRuntime is a Runtime which knows only the given kinds.
+type namespacedTestRuntime struct {
+	Runtime
+	namespaced map[model.GroupVersionKind]bool
+	err        error
+}
+
+func (instance namespacedTestRuntime) IsNamespaced(gvk model.GroupVersionKind) (*bool, error) {
+	if instance.err != nil {
+		return nil, instance.err
+	}
+	for candidate, v := range instance.namespaced {
+		if candidate.Normalize() == gvk.Normalize() {
+			return &v, nil
+		}
+	}
+	return nil, nil
+}
+
+func newNamespaceDefaulterTestProject(defaultNamespace model.Namespace) *model.Project {
+	project := model.NewProject()
+	project.DefaultNamespace = defaultNamespace
+	return &project
+}
+
+func newNamespaceDefaulterTestScheme(group, version, kind string, expectation bool) model.Scheme {
+	var namespaced model.SchemaValidationNamespaced
+	namespaced.Group = group
+	namespaced.Version = version
+	namespaced.Kind = kind
+	namespaced.Expectation = expectation
+	return model.Scheme{Namespaced: []model.SchemaValidationNamespaced{namespaced}}
+}
+
+func Test_NamespaceDefaulter_Default(t *testing.T) {
+	widget := model.GroupVersionKind{Group: "example.org", Version: "v1", Kind: "Widget"}
+	cases := []struct {
+		name             string
+		apiVersion       string
+		kind             string
+		namespace        string
+		defaultNamespace model.Namespace
+		scheme           model.Scheme
+		runtime          Runtime
+		expected         string
+	}{{
+		name:             "namespaced kind",
+		apiVersion:       "apps/v1",
+		kind:             "Deployment",
+		defaultNamespace: "shop",
+		expected:         "shop",
+	}, {
+		name:             "namespace of object wins",
+		apiVersion:       "v1",
+		kind:             "ConfigMap",
+		namespace:        "other",
+		defaultNamespace: "shop",
+		expected:         "other",
+	}, {
+		name:       "without default namespace",
+		apiVersion: "v1",
+		kind:       "ConfigMap",
+		expected:   "",
+	}, {
+		name:             "cluster scoped kind",
+		apiVersion:       "v1",
+		kind:             "Namespace",
+		defaultNamespace: "shop",
+		expected:         "",
+	}, {
+		name:             "cluster scoped kind of other group",
+		apiVersion:       "rbac.authorization.k8s.io/v1",
+		kind:             "ClusterRole",
+		defaultNamespace: "shop",
+		expected:         "",
+	}, {
+		name:             "discovered cluster scoped kind",
+		apiVersion:       "example.org/v1",
+		kind:             "Widget",
+		defaultNamespace: "shop",
+		runtime:          namespacedTestRuntime{namespaced: map[model.GroupVersionKind]bool{widget: false}},
+		expected:         "",
+	}, {
+		name:             "scheme wins over discovery",
+		apiVersion:       "example.org/v1",
+		kind:             "Widget",
+		defaultNamespace: "shop",
+		scheme:           newNamespaceDefaulterTestScheme("example.org", "v1", "Widget", true),
+		runtime:          namespacedTestRuntime{namespaced: map[model.GroupVersionKind]bool{widget: false}},
+		expected:         "shop",
+	}, {
+		name:             "failing discovery falls back to built-in expectations",
+		apiVersion:       "example.org/v1",
+		kind:             "Widget",
+		defaultNamespace: "shop",
+		runtime:          namespacedTestRuntime{err: errors.New("expected")},
+		expected:         "shop",
+	}}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			project := newNamespaceDefaulterTestProject(c.defaultNamespace)
+			project.Scheme = c.scheme
+			instance := NewNamespaceDefaulter(project, c.runtime)
+			target := &unstructured.Unstructured{}
+			target.SetAPIVersion(c.apiVersion)
+			target.SetKind(c.kind)
+			target.SetName("test")
+			target.SetNamespace(c.namespace)
+
+			modified, err := instance.Default(target)
+
+			require.NoError(t, err)
+			assert.Equal(t, c.expected, target.GetNamespace())
+			assert.Equal(t, c.namespace == "" && c.expected != "", modified)
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package openapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// AddCrds reads all CustomResourceDefinitions (apiextensions.k8s.io/v1) of the given
+// YAML or JSON file and validates objects of their kinds against the
+// openAPIV3Schema of the matching version from now on. If the given file is a
+// directory all *.yml, *.yaml and *.json files inside of it are read. Other documents
+// than CustomResourceDefinitions are ignored.
+func (instance *Validator) AddCrds(file string) error {
+	fi, err := os.Stat(file)
+	if err != nil {
+		return fmt.Errorf("cannot read CRDs of '%s': %w", file, err)
+	}
+	if !fi.IsDir() {
+		return instance.addCrdsOfFile(file)
+	}
+	return filepath.WalkDir(file, func(candidate string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		switch strings.ToLower(filepath.Ext(candidate)) {
+		case ".yml", ".yaml", ".json":
+			return instance.addCrdsOfFile(candidate)
+		}
+		return nil
+	})
+}
+
+func (instance *Validator) addCrdsOfFile(file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return fmt.Errorf("cannot read CRDs of '%s': %w", file, err)
+	}
+	//noinspection GoUnhandledErrorResult
+	defer f.Close()
+
+	decoder := yaml.NewYAMLOrJSONDecoder(f, 4096)
+	for i := 0; ; i++ {
+		var document map[string]interface{}
+		if err := decoder.Decode(&document); errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("cannot read CRDs of '%s#%d': %w", file, i, err)
+		} else if err := instance.addCrd(document); err != nil {
+			return fmt.Errorf("cannot read CRDs of '%s#%d': %w", file, i, err)
+		}
+	}
+}
+
+type crd struct {
+	ApiVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Spec       struct {
+		Group string `json:"group"`
+		Names struct {
+			Kind string `json:"kind"`
+		} `json:"names"`
+		Versions []struct {
+			Name   string `json:"name"`
+			Schema struct {
+				OpenAPIV3Schema map[string]interface{} `json:"openAPIV3Schema"`
+			} `json:"schema"`
+		} `json:"versions"`
+	} `json:"spec"`
+}
+
+func (instance *Validator) addCrd(document map[string]interface{}) error {
+	if document == nil {
+		return nil
+	}
+	var definition crd
+	if b, err := json.Marshal(document); err != nil {
+		return err
+	} else if err := json.Unmarshal(b, &definition); err != nil {
+		return err
+	}
+	if definition.ApiVersion != "apiextensions.k8s.io/v1" || definition.Kind != "CustomResourceDefinition" {
+		return nil
+	}
+	for _, version := range definition.Spec.Versions {
+		s := version.Schema.OpenAPIV3Schema
+		if s == nil {
+			continue
+		}
+		gvk := schema.GroupVersionKind{
+			Group:   definition.Spec.Group,
+			Version: version.Name,
+			Kind:    definition.Spec.Names.Kind,
+		}
+		raw, err := toJsonValue(s)
+		if err != nil {
+			return fmt.Errorf("%v: %w", gvk, err)
+		}
+		root, _ := raw.(map[string]interface{})
+		normalizeSchema(root)
+		// The API server validates apiVersion, kind and metadata of every custom
+		// resource itself; regardless of what the schema says.
+		properties, _ := root["properties"].(map[string]interface{})
+		if properties == nil {
+			properties = map[string]interface{}{}
+			root["properties"] = properties
+		}
+		properties["apiVersion"] = map[string]interface{}{"type": "string"}
+		properties["kind"] = map[string]interface{}{"type": "string"}
+		properties["metadata"] = map[string]interface{}{
+			"$ref": instance.location() + schemasPointer + objectMetaSchema,
+		}
+		if _, ok := root["additionalProperties"]; !ok && root["x-kubernetes-preserve-unknown-fields"] != true {
+			root["additionalProperties"] = false
+		}
+
+		location := baseLocation + path.Join("crds", gvk.Group, gvk.Version, gvk.Kind) + ".json"
+		if err := instance.compiler.AddResource(location, root); err != nil {
+			return fmt.Errorf("%v: %w", gvk, err)
+		}
+		instance.locations[gvk] = location
+		delete(instance.compiled, gvk)
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+//go:build ignore
+
+// This program trims the OpenAPI v3 specifications shipped with the Kubernetes sources
+// (api/openapi-spec/v3 of k8s.io/kubernetes) down to the schemas of the kinds
+// which could be rendered by a project and stores them compressed inside schemas/.
+//
+// Usage: go run generate.go <path to k8s.io/kubernetes sources> <minor version, like 1.36>
+package main
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// specFileRegexp matches all specifications of stable and beta group versions.
+var specFileRegexp = regexp.MustCompile(`^apis?__(?:(.+)__)?(v\d+(?:beta\d+)?)_openapi\.json$`)
+
+func main() {
+	if len(os.Args) != 3 {
+		fail("usage: go run generate.go <path to k8s.io/kubernetes sources> <minor version>")
+	}
+	directory := filepath.Join(os.Args[1], "api", "openapi-spec", "v3")
+	version := os.Args[2]
+
+	entries, err := os.ReadDir(directory)
+	if err != nil {
+		fail("%v", err)
+	}
+	all := map[string]interface{}{}
+	var roots []string
+	for _, entry := range entries {
+		match := specFileRegexp.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+		group, groupVersion := match[1], match[2]
+		spec, err := read(filepath.Join(directory, entry.Name()))
+		if err != nil {
+			fail("%s: %v", entry.Name(), err)
+		}
+		for name, schema := range spec.Components.Schemas {
+			all[name] = schema
+			if isRoot(schema, group, groupVersion) {
+				roots = append(roots, name)
+			}
+		}
+	}
+	sort.Strings(roots)
+
+	result := map[string]interface{}{}
+	for _, root := range roots {
+		collect(root, all, result)
+	}
+	for _, schema := range result {
+		stripDocumentation(schema)
+	}
+
+	target := filepath.Join("schemas", version+".json.gz")
+	if err := write(target, result); err != nil {
+		fail("%s: %v", target, err)
+	}
+	fmt.Printf("%s: %d schemas (%d kinds)\n", target, len(result), len(roots))
+}
+
+type specification struct {
+	Components struct {
+		Schemas map[string]interface{} `json:"schemas"`
+	} `json:"components"`
+}
+
+func read(file string) (result specification, err error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	err = json.NewDecoder(f).Decode(&result)
+	return
+}
+
+func write(file string, schemas map[string]interface{}) error {
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	gz, err := gzip.NewWriterLevel(f, gzip.BestCompression)
+	if err != nil {
+		return err
+	}
+	if err := json.NewEncoder(gz).Encode(schemas); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// isRoot returns true if the given schema describes a kind of the given group version.
+// Schemas like DeleteOptions are tagged with every group version; they are ignored.
+func isRoot(schema interface{}, group, version string) bool {
+	m, ok := schema.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	gvks, ok := m["x-kubernetes-group-version-kind"].([]interface{})
+	if !ok || len(gvks) != 1 {
+		return false
+	}
+	gvk, ok := gvks[0].(map[string]interface{})
+	return ok && gvk["group"] == group && gvk["version"] == version
+}
+
+func collect(name string, all, result map[string]interface{}) {
+	if _, ok := result[name]; ok {
+		return
+	}
+	schema, ok := all[name]
+	if !ok {
+		fail("unknown schema %s", name)
+	}
+	result[name] = schema
+	walk(schema, func(m map[string]interface{}) {
+		if ref, ok := m["$ref"].(string); ok {
+			collect(strings.TrimPrefix(ref, "#/components/schemas/"), all, result)
+		}
+	})
+}
+
+func stripDocumentation(schema interface{}) {
+	walk(schema, func(m map[string]interface{}) {
+		for _, key := range []string{"description", "example"} {
+			if _, ok := m[key].(string); ok {
+				delete(m, key)
+			}
+		}
+	})
+}
+
+func walk(v interface{}, visitor func(map[string]interface{})) {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		visitor(t)
+		for _, child := range t {
+			walk(child, visitor)
+		}
+	case []interface{}:
+		for _, child := range t {
+			walk(child, visitor)
+		}
+	}
+}
+
+func fail(format string, args ...interface{}) {
+	_, _ = fmt.Fprintf(os.Stderr, format+"\n", args...)
+	os.Exit(1)
+}
@@ -0,0 +1,395 @@
+package openapi
+
+import (
+	"bytes"
+	"compress/gzip"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/santhosh-tekuri/jsonschema/v6/kind"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"io"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrUnknownKubernetesVersion = errors.New("unknown kubernetes version")
+	ErrNoSchema                 = errors.New("no schema available")
+)
+
+// schemas holds the trimmed OpenAPI v3 schemas of every supported Kubernetes minor
+// version (see generate.go).
+//
+//go:embed schemas/*.json.gz
+var schemas embed.FS
+
+const (
+	baseLocation     = "kubor:///openapi/"
+	schemasPointer   = "#/components/schemas/"
+	objectMetaSchema = "io.k8s.apimachinery.pkg.apis.meta.v1.ObjectMeta"
+)
+
+// Versions returns all Kubernetes minor versions (like "1.36") schemas are bundled for.
+func Versions() []string {
+	entries, _ := schemas.ReadDir("schemas")
+	result := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		result = append(result, strings.TrimSuffix(entry.Name(), ".json.gz"))
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return compareVersions(result[i], result[j]) < 0
+	})
+	return result
+}
+
+// DefaultVersion returns the latest of Versions.
+func DefaultVersion() string {
+	versions := Versions()
+	return versions[len(versions)-1]
+}
+
+// Validator validates objects against the OpenAPI schemas of one Kubernetes version
+// and the schemas of all added CustomResourceDefinitions.
+type Validator struct {
+	version   string
+	compiler  *jsonschema.Compiler
+	locations map[schema.GroupVersionKind]string
+	compiled  map[schema.GroupVersionKind]*jsonschema.Schema
+}
+
+// NewValidator creates a new Validator for the given Kubernetes version. Patch versions
+// and a "v" prefix are accepted (like "v1.36.2") and only the minor version is respected.
+func NewValidator(version string) (*Validator, error) {
+	minor, err := minorVersionOf(version)
+	if err != nil {
+		return nil, err
+	}
+	f, err := schemas.Open("schemas/" + minor + ".json.gz")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s; supported are: %s", ErrUnknownKubernetesVersion, version, strings.Join(Versions(), ", "))
+	}
+	//noinspection GoUnhandledErrorResult
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read schemas of kubernetes %s: %w", minor, err)
+	}
+	plain, err := io.ReadAll(gz)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read schemas of kubernetes %s: %w", minor, err)
+	}
+	document, err := jsonschema.UnmarshalJSON(bytes.NewReader(plain))
+	if err != nil {
+		return nil, fmt.Errorf("cannot read schemas of kubernetes %s: %w", minor, err)
+	}
+	all, ok := document.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("cannot read schemas of kubernetes %s: unexpected document", minor)
+	}
+
+	result := &Validator{
+		version:   minor,
+		compiler:  jsonschema.NewCompiler(),
+		locations: map[schema.GroupVersionKind]string{},
+		compiled:  map[schema.GroupVersionKind]*jsonschema.Schema{},
+	}
+	result.compiler.DefaultDraft(jsonschema.Draft4)
+
+	location := result.location()
+	for name, candidate := range all {
+		normalizeSchema(candidate)
+		if gvk, ok := groupVersionKindOf(candidate); ok {
+			result.locations[gvk] = location + schemasPointer + name
+		}
+	}
+	if err := result.compiler.AddResource(location, map[string]interface{}{
+		"components": map[string]interface{}{"schemas": all},
+	}); err != nil {
+		return nil, fmt.Errorf("cannot read schemas of kubernetes %s: %w", minor, err)
+	}
+	return result, nil
+}
+
+// Version returns the Kubernetes minor version of this validator.
+func (instance *Validator) Version() string {
+	return instance.version
+}
+
+func (instance *Validator) location() string {
+	return baseLocation + instance.version + ".json"
+}
+
+// Validate validates the given object against the schema of its kind. If the object
+// does not match the schema Violations is returned; if there is no schema for the kind
+// of the object ErrNoSchema.
+func (instance *Validator) Validate(object *unstructured.Unstructured) error {
+	gvk := object.GroupVersionKind()
+	compiled, err := instance.schemaFor(gvk)
+	if err != nil {
+		return err
+	}
+	raw, err := toJsonValue(object.Object)
+	if err != nil {
+		return fmt.Errorf("cannot validate %v: %w", gvk, err)
+	}
+	err = compiled.Validate(raw)
+	if ve, ok := err.(*jsonschema.ValidationError); ok {
+		violations := Violations{GroupVersionKind: gvk}
+		violations.collect(ve)
+		sort.SliceStable(violations.Violations, func(i, j int) bool {
+			return violations.Violations[i].Path < violations.Violations[j].Path
+		})
+		return violations
+	} else if err != nil {
+		return fmt.Errorf("cannot validate %v: %w", gvk, err)
+	}
+	return nil
+}
+
+func (instance *Validator) schemaFor(gvk schema.GroupVersionKind) (*jsonschema.Schema, error) {
+	if result, ok := instance.compiled[gvk]; ok {
+		return result, nil
+	}
+	location, ok := instance.locations[gvk]
+	if !ok {
+		return nil, fmt.Errorf("%w for %v in kubernetes %s", ErrNoSchema, gvk, instance.version)
+	}
+	result, err := instance.compiler.Compile(location)
+	if err != nil {
+		return nil, fmt.Errorf("cannot compile schema of %v: %w", gvk, err)
+	}
+	instance.compiled[gvk] = result
+	return result, nil
+}
+
+func groupVersionKindOf(plain interface{}) (schema.GroupVersionKind, bool) {
+	m, _ := plain.(map[string]interface{})
+	gvks, _ := m["x-kubernetes-group-version-kind"].([]interface{})
+	if len(gvks) != 1 {
+		return schema.GroupVersionKind{}, false
+	}
+	gvk, _ := gvks[0].(map[string]interface{})
+	group, _ := gvk["group"].(string)
+	version, _ := gvk["version"].(string)
+	k, _ := gvk["kind"].(string)
+	return schema.GroupVersionKind{Group: group, Version: version, Kind: k}, version != "" && k != ""
+}
+
+// normalizeSchema converts the given OpenAPI schema (recursively) into a JSON schema
+// which reflects how the API server validates objects:
+//
+// 1. Objects with properties do not allow unknown properties, unless they are marked
+// with x-kubernetes-preserve-unknown-fields.
+// 2. x-kubernetes-int-or-string allows integers and strings.
+// 3. nullable allows null.
+// 4. x-kubernetes-embedded-resource allows apiVersion, kind and metadata.
+func normalizeSchema(plain interface{}) {
+	switch v := plain.(type) {
+	case map[string]interface{}:
+		if properties, ok := v["properties"].(map[string]interface{}); ok {
+			for _, property := range properties {
+				normalizeSchema(property)
+			}
+			if v["x-kubernetes-embedded-resource"] == true {
+				for _, name := range []string{"apiVersion", "kind"} {
+					if _, ok := properties[name]; !ok {
+						properties[name] = map[string]interface{}{"type": "string"}
+					}
+				}
+				if _, ok := properties["metadata"]; !ok {
+					properties["metadata"] = map[string]interface{}{"type": "object"}
+				}
+			}
+			if _, ok := v["additionalProperties"]; !ok && v["x-kubernetes-preserve-unknown-fields"] != true {
+				v["additionalProperties"] = false
+			}
+		}
+		for _, key := range []string{"items", "additionalProperties", "not"} {
+			normalizeSchema(v[key])
+		}
+		for _, key := range []string{"allOf", "anyOf", "oneOf"} {
+			normalizeSchema(v[key])
+		}
+		if v["x-kubernetes-int-or-string"] == true {
+			delete(v, "type")
+			if _, ok := v["anyOf"]; !ok {
+				v["anyOf"] = []interface{}{
+					map[string]interface{}{"type": "integer"},
+					map[string]interface{}{"type": "string"},
+				}
+			}
+		}
+		if t, ok := v["type"].(string); ok && v["nullable"] == true {
+			v["type"] = []interface{}{t, "null"}
+		}
+	case []interface{}:
+		for _, element := range v {
+			normalizeSchema(element)
+		}
+	}
+}
+
+// Violation describes one location inside of an object which does not match the schema.
+type Violation struct {
+	// Path is the path of the violating field like "spec.template.spec.containers[0].image".
+	Path    string
+	Message string
+}
+
+func (instance Violation) String() string {
+	if instance.Path == "" {
+		return instance.Message
+	}
+	return fmt.Sprintf("%s: %s", instance.Path, instance.Message)
+}
+
+// Violations is returned by Validator.Validate and holds all violations of an object.
+type Violations struct {
+	GroupVersionKind schema.GroupVersionKind
+	Violations       []Violation
+}
+
+func (instance Violations) Error() string {
+	buf := new(bytes.Buffer)
+	_, _ = fmt.Fprintf(buf, "object does not match schema of %v:", instance.GroupVersionKind)
+	for _, violation := range instance.Violations {
+		_, _ = fmt.Fprintf(buf, "\n\t%v", violation)
+	}
+	return buf.String()
+}
+
+var messagePrinter = message.NewPrinter(language.English)
+
+func (instance *Violations) collect(ve *jsonschema.ValidationError) {
+	if message, ok := alternativesMessageOf(ve); ok {
+		instance.add(ve.InstanceLocation, message)
+		return
+	}
+	if len(ve.Causes) > 0 {
+		for _, cause := range ve.Causes {
+			instance.collect(cause)
+		}
+		return
+	}
+	if ap, ok := ve.ErrorKind.(*kind.AdditionalProperties); ok {
+		for _, property := range ap.Properties {
+			instance.add(append(ve.InstanceLocation[:len(ve.InstanceLocation):len(ve.InstanceLocation)], property), "unknown field")
+		}
+		return
+	}
+	instance.add(ve.InstanceLocation, ve.ErrorKind.LocalizedString(messagePrinter))
+}
+
+func (instance *Violations) add(location []string, message string) {
+	instance.Violations = append(instance.Violations, Violation{
+		Path:    pathOf(location),
+		Message: message,
+	})
+}
+
+// alternativesMessageOf summarizes failed anyOf/oneOf which only failed because of the
+// type of the value, like "got boolean, want integer or string".
+func alternativesMessageOf(ve *jsonschema.ValidationError) (string, bool) {
+	switch ve.ErrorKind.(type) {
+	case *kind.AnyOf, *kind.OneOf:
+	default:
+		return "", false
+	}
+	if len(ve.Causes) == 0 {
+		return "", false
+	}
+	got := ""
+	var want []string
+	for _, cause := range ve.Causes {
+		t, ok := cause.ErrorKind.(*kind.Type)
+		if !ok || len(cause.Causes) > 0 || (got != "" && got != t.Got) {
+			return "", false
+		}
+		got = t.Got
+		want = append(want, t.Want...)
+	}
+	return fmt.Sprintf("got %s, want %s", got, strings.Join(want, " or ")), true
+}
+
+// pathOf converts the given location into a path like "spec.containers[0].image".
+func pathOf(location []string) string {
+	buf := new(strings.Builder)
+	for _, element := range location {
+		if _, err := strconv.Atoi(element); err == nil {
+			buf.WriteString("[" + element + "]")
+		} else if strings.ContainsAny(element, ".[]") {
+			buf.WriteString("[" + strconv.Quote(element) + "]")
+		} else {
+			if buf.Len() > 0 {
+				buf.WriteByte('.')
+			}
+			buf.WriteString(element)
+		}
+	}
+	return buf.String()
+}
+
+// toJsonValue converts the given object into a value as it would be created by
+// the JSON decoder (which is required by the validator). Fields which are null are
+// removed because the API server treats them as absent.
+func toJsonValue(in map[string]interface{}) (interface{}, error) {
+	pruned := pruneNulls(in)
+	b, err := json.Marshal(pruned)
+	if err != nil {
+		return nil, err
+	}
+	return jsonschema.UnmarshalJSON(bytes.NewReader(b))
+}
+
+func pruneNulls(in interface{}) interface{} {
+	switch v := in.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			if value != nil {
+				result[key] = pruneNulls(value)
+			}
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, value := range v {
+			result[i] = pruneNulls(value)
+		}
+		return result
+	default:
+		return v
+	}
+}
+
+func minorVersionOf(version string) (string, error) {
+	parts := strings.Split(strings.TrimPrefix(version, "v"), ".")
+	if len(parts) < 2 || len(parts) > 3 {
+		return "", fmt.Errorf("%w: %s", ErrUnknownKubernetesVersion, version)
+	}
+	for _, part := range parts {
+		if _, err := strconv.Atoi(part); err != nil {
+			return "", fmt.Errorf("%w: %s", ErrUnknownKubernetesVersion, version)
+		}
+	}
+	return parts[0] + "." + parts[1], nil
+}
+
+func compareVersions(a, b string) int {
+	pa, pb := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(pa) && i < len(pb); i++ {
+		na, _ := strconv.Atoi(pa[i])
+		nb, _ := strconv.Atoi(pb[i])
+		if na != nb {
+			return na - nb
+		}
+	}
+	return len(pa) - len(pb)
+}
@@ -0,0 +1,189 @@
+package openapi
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newDeployment(container map[string]interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"name":              "app",
+			"creationTimestamp": nil,
+			"annotations": map[string]interface{}{
+				"kubor.echocat.org/stage": "deploy",
+			},
+		},
+		"spec": map[string]interface{}{
+			"selector": map[string]interface{}{
+				"matchLabels": map[string]interface{}{"app": "app"},
+			},
+			"template": map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"labels": map[string]interface{}{"app": "app"},
+				},
+				"spec": map[string]interface{}{
+					"containers": []interface{}{container},
+				},
+			},
+		},
+	}}
+}
+
+func Test_Versions_are_sorted_and_DefaultVersion_is_the_latest(t *testing.T) {
+	versions := Versions()
+
+	require.NotEmpty(t, versions)
+	for i := 1; i < len(versions); i++ {
+		assert.Less(t, compareVersions(versions[i-1], versions[i]), 0)
+	}
+	assert.Equal(t, versions[len(versions)-1], DefaultVersion())
+}
+
+func Test_NewValidator_accepts_patch_versions(t *testing.T) {
+	actual, err := NewValidator("v" + DefaultVersion() + ".2")
+
+	require.NoError(t, err)
+	assert.Equal(t, DefaultVersion(), actual.Version())
+}
+
+func Test_NewValidator_fails_on_unknown_versions(t *testing.T) {
+	for _, version := range []string{"1.2", "foo", "1"} {
+		t.Run(version, func(t *testing.T) {
+			_, err := NewValidator(version)
+
+			assert.ErrorIs(t, err, ErrUnknownKubernetesVersion)
+		})
+	}
+}
+
+func Test_Validator_Validate_accepts_valid_objects(t *testing.T) {
+	instance, err := NewValidator(DefaultVersion())
+	require.NoError(t, err)
+
+	assert.NoError(t, instance.Validate(newDeployment(map[string]interface{}{
+		"name":  "app",
+		"image": "app:1.0",
+		"ports": []interface{}{map[string]interface{}{"containerPort": int64(8080)}},
+		"resources": map[string]interface{}{
+			"requests": map[string]interface{}{"cpu": "100m", "memory": int64(1024)},
+		},
+		"readinessProbe": map[string]interface{}{
+			"httpGet": map[string]interface{}{"port": "http"},
+		},
+		"livenessProbe": map[string]interface{}{
+			"httpGet": map[string]interface{}{"port": int64(8080)},
+		},
+	})))
+}
+
+func Test_Validator_Validate_reports_violations_with_paths(t *testing.T) {
+	instance, err := NewValidator(DefaultVersion())
+	require.NoError(t, err)
+
+	err = instance.Validate(newDeployment(map[string]interface{}{
+		"name":            "app",
+		"image":           "app:1.0",
+		"imagePullPolicy": true,
+		"foo":             "bar",
+		"readinessProbe": map[string]interface{}{
+			"httpGet": map[string]interface{}{"port": true},
+		},
+	}))
+
+	var violations Violations
+	require.ErrorAs(t, err, &violations)
+	assert.Equal(t, []Violation{
+		{Path: "spec.template.spec.containers[0].foo", Message: "unknown field"},
+		{Path: "spec.template.spec.containers[0].imagePullPolicy", Message: "got boolean, want string"},
+		{Path: "spec.template.spec.containers[0].readinessProbe.httpGet.port", Message: "got boolean, want integer or string"},
+	}, violations.Violations)
+}
+
+func Test_Validator_Validate_fails_for_unknown_kinds(t *testing.T) {
+	instance, err := NewValidator(DefaultVersion())
+	require.NoError(t, err)
+
+	err = instance.Validate(&unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "example.org/v1",
+		"kind":       "Foo",
+	}})
+
+	assert.ErrorIs(t, err, ErrNoSchema)
+}
+
+func Test_Validator_AddCrds(t *testing.T) {
+	instance, err := NewValidator(DefaultVersion())
+	require.NoError(t, err)
+	file := filepath.Join(t.TempDir(), "crd.yml")
+	require.NoError(t, os.WriteFile(file, []byte(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: ignored
+---
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: foos.example.org
+spec:
+  group: example.org
+  names:
+    kind: Foo
+  versions:
+  - name: v1
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            properties:
+              size:
+                type: integer
+              port:
+                x-kubernetes-int-or-string: true
+              extra:
+                type: object
+                x-kubernetes-preserve-unknown-fields: true
+`), 0644))
+
+	require.NoError(t, instance.AddCrds(file))
+
+	assert.NoError(t, instance.Validate(&unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "example.org/v1",
+		"kind":       "Foo",
+		"metadata":   map[string]interface{}{"name": "foo"},
+		"spec": map[string]interface{}{
+			"size":  int64(1),
+			"port":  "http",
+			"extra": map[string]interface{}{"anything": "goes"},
+		},
+	}}))
+
+	err = instance.Validate(&unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "example.org/v1",
+		"kind":       "Foo",
+		"metadata":   map[string]interface{}{"nam": "foo"},
+		"spec": map[string]interface{}{
+			"size": "1",
+		},
+	}})
+	var violations Violations
+	require.ErrorAs(t, err, &violations)
+	assert.Equal(t, []Violation{
+		{Path: "metadata.nam", Message: "unknown field"},
+		{Path: "spec.size", Message: "got string, want integer"},
+	}, violations.Violations)
+}
+
+func Test_pathOf(t *testing.T) {
+	assert.Equal(t, "", pathOf(nil))
+	assert.Equal(t, "spec.containers[0].image", pathOf([]string{"spec", "containers", "0", "image"}))
+	assert.Equal(t, `metadata.annotations["example.org/foo"]`, pathOf([]string{"metadata", "annotations", "example.org/foo"}))
+}
@@ -43,7 +43,11 @@ func (instance ObjectResource) Clone() ObjectResource {
 
 func (instance ObjectResource) CloneForCreate(project *model.Project) (ObjectResource, error) {
 	result := instance.Clone()
-	if err := transformation.Default.TransformForCreate(project, result.Object); err != nil {
+	transformations, err := transformation.For(project)
+	if err != nil {
+		return ObjectResource{}, err
+	}
+	if err := transformations.TransformForCreate(project, result.Object); err != nil {
 		return ObjectResource{}, err
 	}
 	return result, nil
@@ -51,7 +55,11 @@ func (instance ObjectResource) CloneForCreate(project *model.Project) (ObjectRes
 
 func (instance ObjectResource) CloneForUpdate(project *model.Project, original unstructured.Unstructured) (ObjectResource, error) {
 	result := instance.Clone()
-	if err := transformation.Default.TransformForUpdate(project, original, result.Object); err != nil {
+	transformations, err := transformation.For(project)
+	if err != nil {
+		return ObjectResource{}, err
+	}
+	if err := transformations.TransformForUpdate(project, original, result.Object); err != nil {
 		return ObjectResource{}, err
 	}
 	return result, nil
@@ -1,7 +1,11 @@
 package kubernetes
 
 import (
+	"fmt"
+	"github.com/echocat/kubor/model"
 	openapi_v2 "github.com/google/gnostic-models/openapiv2"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/discovery"
@@ -10,12 +14,22 @@ import (
 	"k8s.io/client-go/rest"
 	restFake "k8s.io/client-go/rest/fake"
 	"k8s.io/client-go/tools/clientcmd"
+	"strings"
+	"sync"
 )
 
 type Runtime interface {
 	ContextName() string
 	NewDynamicClient() (dynamic.Interface, error)
 	NewRestClient(gvk schema.GroupVersionKind) (rest.Interface, error)
+	// IsNamespaced asks the server if objects of the given kind are namespaced; nil if
+	// the server does not know this kind.
+	IsNamespaced(gvk model.GroupVersionKind) (*bool, error)
+	// NamespacedResources asks the server for all namespaced resources which could be
+	// listed; each in the preferred version of its group. If some groups could not be
+	// discovered the resources of all others are returned together with an error
+	// which satisfies discovery.IsGroupDiscoveryFailedError.
+	NamespacedResources() ([]schema.GroupVersionResource, error)
 
 	discovery.OpenAPISchemaInterface
 }
@@ -33,6 +47,7 @@ func newRuntimeImpl(clientConfig clientcmd.ClientConfig, contextName string) (*r
 		config:          config,
 		contextName:     contextName,
 		discoveryClient: dc,
+		resources:       map[schema.GroupVersion]*metav1.APIResourceList{},
 	}, nil
 }
 
@@ -41,6 +56,9 @@ type runtimeImpl struct {
 	contextName string
 
 	discoveryClient discovery.DiscoveryInterface
+
+	resources      map[schema.GroupVersion]*metav1.APIResourceList
+	resourcesMutex sync.Mutex
 }
 
 func (instance *runtimeImpl) NewDynamicClient() (dynamic.Interface, error) {
@@ -63,6 +81,55 @@ func (instance *runtimeImpl) OpenAPISchema() (*openapi_v2.Document, error) {
 	return instance.discoveryClient.OpenAPISchema()
 }
 
+func (instance *runtimeImpl) IsNamespaced(gvk model.GroupVersionKind) (*bool, error) {
+	resources, err := instance.resourcesOf(gvk.GroupVersion())
+	if err != nil || resources == nil {
+		return nil, err
+	}
+	for _, resource := range resources.APIResources {
+		// Ignore sub resources like deployments/status. The kind could be normalized
+		// (see model.GroupVersionKind.Normalize).
+		if strings.EqualFold(resource.Kind, gvk.Kind) && !strings.Contains(resource.Name, "/") {
+			v := resource.Namespaced
+			return &v, nil
+		}
+	}
+	return nil, nil
+}
+
+func (instance *runtimeImpl) NamespacedResources() ([]schema.GroupVersionResource, error) {
+	lists, err := discovery.ServerPreferredNamespacedResources(instance.discoveryClient)
+	if err != nil && !discovery.IsGroupDiscoveryFailedError(err) {
+		return nil, fmt.Errorf("cannot discover namespaced resources: %w", err)
+	}
+	lists = discovery.FilteredBy(discovery.SupportsAllVerbs{Verbs: []string{"list"}}, lists)
+	gvrs, gvrsErr := discovery.GroupVersionResources(lists)
+	if gvrsErr != nil {
+		return nil, fmt.Errorf("cannot discover namespaced resources: %w", gvrsErr)
+	}
+	result := make([]schema.GroupVersionResource, 0, len(gvrs))
+	for gvr := range gvrs {
+		result = append(result, gvr)
+	}
+	return result, err
+}
+
+func (instance *runtimeImpl) resourcesOf(gv schema.GroupVersion) (*metav1.APIResourceList, error) {
+	instance.resourcesMutex.Lock()
+	defer instance.resourcesMutex.Unlock()
+	if result, ok := instance.resources[gv]; ok {
+		return result, nil
+	}
+	result, err := instance.discoveryClient.ServerResourcesForGroupVersion(gv.String())
+	if errors.IsNotFound(err) {
+		result, err = nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("cannot discover resources of %v: %w", gv, err)
+	}
+	instance.resources[gv] = result
+	return result, nil
+}
+
 func newRuntimeMock(contextName string) (*runtimeMock, error) {
 	return &runtimeMock{
 		scheme:      runtime.NewScheme(),
@@ -89,6 +156,14 @@ func (instance *runtimeMock) ContextName() string {
 	return instance.contextName
 }
 
+func (instance *runtimeMock) IsNamespaced(model.GroupVersionKind) (*bool, error) {
+	return nil, nil
+}
+
+func (instance *runtimeMock) NamespacedResources() ([]schema.GroupVersionResource, error) {
+	return nil, nil
+}
+
 func (instance *runtimeMock) OpenAPISchema() (*openapi_v2.Document, error) {
 	return &openapi_v2.Document{}, nil
 }
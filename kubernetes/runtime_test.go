@@ -0,0 +1,44 @@
+package kubernetes
+
+import (
+	"github.com/echocat/kubor/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	discoveryFake "k8s.io/client-go/discovery/fake"
+	clientTesting "k8s.io/client-go/testing"
+	"testing"
+)
+
+func Test_runtimeImpl_IsNamespaced(t *testing.T) {
+	instance := &runtimeImpl{
+		discoveryClient: &discoveryFake.FakeDiscovery{Fake: &clientTesting.Fake{Resources: []*metav1.APIResourceList{{
+			GroupVersion: "example.org/v1",
+			APIResources: []metav1.APIResource{
+				{Name: "widgets/status", Kind: "Widget", Namespaced: true},
+				{Name: "widgets", Kind: "Widget", Namespaced: false},
+				{Name: "gadgets", Kind: "Gadget", Namespaced: true},
+			},
+		}}}},
+		resources: map[schema.GroupVersion]*metav1.APIResourceList{},
+	}
+	cases := []struct {
+		gvk      model.GroupVersionKind
+		expected *bool
+	}{
+		{model.GroupVersionKind{Group: "example.org", Version: "v1", Kind: "Widget"}, new(false)},
+		{model.GroupVersionKind{Group: "example.org", Version: "v1", Kind: "Widget"}.Normalize(), new(false)},
+		{model.GroupVersionKind{Group: "example.org", Version: "v1", Kind: "Gadget"}, new(true)},
+		{model.GroupVersionKind{Group: "example.org", Version: "v1", Kind: "Unknown"}, nil},
+		{model.GroupVersionKind{Group: "other.org", Version: "v1", Kind: "Widget"}, nil},
+	}
+	for _, c := range cases {
+		t.Run(c.gvk.String(), func(t *testing.T) {
+			actual, err := instance.IsNamespaced(c.gvk)
+
+			require.NoError(t, err)
+			assert.Equal(t, c.expected, actual)
+		})
+	}
+}
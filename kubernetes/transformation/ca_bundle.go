@@ -0,0 +1,98 @@
+package transformation
+
+import (
+	"github.com/echocat/kubor/model"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var (
+	webhookConfigurationGroupKinds = map[schema.GroupKind]bool{
+		{Group: "admissionregistration.k8s.io", Kind: "MutatingWebhookConfiguration"}:   true,
+		{Group: "admissionregistration.k8s.io", Kind: "ValidatingWebhookConfiguration"}: true,
+	}
+	customResourceDefinitionGroupKind = schema.GroupKind{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"}
+	apiServiceGroupKind               = schema.GroupKind{Group: "apiregistration.k8s.io", Kind: "APIService"}
+)
+
+func init() {
+	Default.MustRegisterUpdateFunc("webhook-preserve-ca-bundles", preserveWebhookCaBundles)
+	Default.MustRegisterUpdateFunc("api-service-preserve-ca-bundle", preserveApiServiceCaBundle)
+}
+
+// preserveWebhookCaBundles keeps the caBundles of webhook configurations and of the
+// conversion webhook of CustomResourceDefinitions which were injected by tools like
+// the cert-manager CA injector, if the target does not define them.
+func preserveWebhookCaBundles(_ *model.Project, existing unstructured.Unstructured, target *unstructured.Unstructured, _ *string) error {
+	if !groupVersionKindMatches(&existing, target) {
+		return nil
+	}
+	groupKind := target.GroupVersionKind().GroupKind()
+	if groupKind == customResourceDefinitionGroupKind {
+		return preserveNestedCaBundle(existing.Object, target.Object, "spec", "conversion", "webhook", "clientConfig", "caBundle")
+	}
+	if !webhookConfigurationGroupKinds[groupKind] {
+		return nil
+	}
+
+	existingWebhooks, _, err := unstructured.NestedSlice(existing.Object, "webhooks")
+	if err != nil {
+		return err
+	}
+	targetWebhooks, _, err := unstructured.NestedSlice(target.Object, "webhooks")
+	if err != nil {
+		return err
+	}
+	if len(targetWebhooks) <= 0 {
+		return nil
+	}
+
+	for i, pExistingWebhook := range existingWebhooks {
+		name, existingWebhook, err := getNameOfUncheckedNamedMap(pExistingWebhook, i)
+		if err != nil {
+			return err
+		}
+		targetWebhook, targetIndex, err := findByNameOfUncheckedNamedMap(targetWebhooks, name)
+		if err != nil {
+			return err
+		}
+		if targetIndex >= 0 {
+			if err := preserveNestedCaBundle(existingWebhook, targetWebhook, "clientConfig", "caBundle"); err != nil {
+				return err
+			}
+			targetWebhooks[targetIndex] = targetWebhook
+		}
+	}
+
+	return unstructured.SetNestedSlice(target.Object, targetWebhooks, "webhooks")
+}
+
+// preserveApiServiceCaBundle keeps spec.caBundle of APIServices if the target neither
+// defines it nor skips the TLS verification.
+func preserveApiServiceCaBundle(_ *model.Project, existing unstructured.Unstructured, target *unstructured.Unstructured, _ *string) error {
+	if !groupVersionKindMatches(&existing, target) {
+		return nil
+	}
+	if target.GroupVersionKind().GroupKind() != apiServiceGroupKind {
+		return nil
+	}
+	if insecure, _, err := unstructured.NestedBool(target.Object, "spec", "insecureSkipTLSVerify"); err != nil || insecure {
+		return err
+	}
+	return preserveNestedCaBundle(existing.Object, target.Object, "spec", "caBundle")
+}
+
+func preserveNestedCaBundle(existing, target map[string]interface{}, fields ...string) error {
+	caBundle, exist, err := unstructured.NestedString(existing, fields...)
+	if err != nil || !exist || caBundle == "" {
+		return err
+	}
+	if v, _, err := unstructured.NestedString(target, fields...); err != nil || v != "" {
+		return err
+	}
+	if _, exist, err := unstructured.NestedFieldNoCopy(target, fields[:len(fields)-1]...); err != nil || !exist {
+		// Only restore the bundle if the surrounding structure is still defined.
+		return err
+	}
+	return unstructured.SetNestedField(target, caBundle, fields...)
+}
@@ -0,0 +1,48 @@
+package transformation
+
+import (
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"testing"
+)
+
+func Test_preserveWebhookCaBundles(t *testing.T) {
+	newWebhookConfiguration := func(caBundle string) *unstructured.Unstructured {
+		clientConfig := map[string]interface{}{"url": "https://example.org"}
+		if caBundle != "" {
+			clientConfig["caBundle"] = caBundle
+		}
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "admissionregistration.k8s.io/v1",
+			"kind":       "MutatingWebhookConfiguration",
+			"webhooks": []interface{}{
+				map[string]interface{}{"name": "a.example.org", "clientConfig": clientConfig},
+			},
+		}}
+	}
+	existing := newWebhookConfiguration("Y2E=")
+	target := newWebhookConfiguration("")
+
+	err := preserveWebhookCaBundles(nil, *existing, target, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, existing, target)
+}
+
+func Test_preserveApiServiceCaBundle(t *testing.T) {
+	existing := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apiregistration.k8s.io/v1",
+		"kind":       "APIService",
+		"spec":       map[string]interface{}{"caBundle": "Y2E=", "group": "metrics.k8s.io"},
+	}}
+	target := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apiregistration.k8s.io/v1",
+		"kind":       "APIService",
+		"spec":       map[string]interface{}{"group": "metrics.k8s.io"},
+	}}
+
+	err := preserveApiServiceCaBundle(nil, *existing, target, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, existing, target)
+}
@@ -0,0 +1,90 @@
+package transformation
+
+import (
+	"github.com/echocat/kubor/model"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sort"
+)
+
+const ciDiscoveryTransformationName = model.TransformationName("ci-discovery")
+
+func init() {
+	Default.MustRegisterUpdateFunc(ciDiscoveryTransformationName, appendCiDiscoveryOnUpdate)
+	Default.MustRegisterCreateFunc(ciDiscoveryTransformationName, appendCiDiscovery)
+}
+
+// ciAnnotation describes an annotation which is set to the value of an environment
+// variable (if not empty).
+type ciAnnotation struct {
+	annotation string
+	value      func(env map[string]string) string
+}
+
+func ciAnnotationOfEnv(annotation, variable string) ciAnnotation {
+	return ciAnnotation{annotation, func(env map[string]string) string {
+		return env[variable]
+	}}
+}
+
+func appendCiDiscoveryOnUpdate(project *model.Project, _ unstructured.Unstructured, target *unstructured.Unstructured, argument *string) error {
+	return appendCiDiscovery(project, target, argument)
+}
+
+// appendCiDiscovery sets the annotations configured by model.Ci.
+func appendCiDiscovery(project *model.Project, target *unstructured.Unstructured, _ *string) error {
+	keys := make([]string, 0, len(project.Ci.Annotations))
+	for key := range project.Ci.Annotations {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	cas := make([]ciAnnotation, len(keys))
+	for i, key := range keys {
+		cas[i] = ciAnnotationOfEnv(key, project.Ci.Annotations[key])
+	}
+	return appendCiAnnotations(project, target, cas)
+}
+
+// appendCiAnnotations sets the given annotations on workloads (see
+// GitlabDiscoveryReceiverGvks) and their pod templates.
+func appendCiAnnotations(project *model.Project, target *unstructured.Unstructured, cas []ciAnnotation) error {
+	if !GitlabDiscoveryReceiverGvks.Contains(model.GroupVersionKind(target.GroupVersionKind())) {
+		return nil
+	}
+
+	if err := appendCiAnnotationsOfPath(project, target, cas, "metadata", "annotations"); err != nil {
+		return err
+	}
+
+	if _, specTemplateExists, err := NestedMap(target.Object, "spec", "template"); err != nil || !specTemplateExists {
+		return err
+	}
+	if err := appendCiAnnotationsOfPath(project, target, cas, "spec", "template", "metadata", "annotations"); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func appendCiAnnotationsOfPath(project *model.Project, target *unstructured.Unstructured, cas []ciAnnotation, fields ...string) error {
+	annotations, _, err := NestedStringMap(target.Object, fields...)
+	if err != nil {
+		return err
+	}
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+
+	atLeastOneSet := false
+	for _, ca := range cas {
+		if v := ca.value(project.Env); v != "" {
+			annotations[ca.annotation] = v
+			atLeastOneSet = true
+		}
+	}
+
+	if !atLeastOneSet {
+		return nil
+	}
+
+	return unstructured.SetNestedStringMap(target.Object, annotations, fields...)
+}
@@ -0,0 +1,49 @@
+package transformation
+
+import (
+	"github.com/echocat/kubor/model"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"testing"
+)
+
+func ciTestAnnotationsOf(target *unstructured.Unstructured) (object, template map[string]string) {
+	object, _, _ = NestedStringMap(target.Object, "metadata", "annotations")
+	template, _, _ = NestedStringMap(target.Object, "spec", "template", "metadata", "annotations")
+	return
+}
+
+func Test_appendCiDiscovery_maps_configured_variables(t *testing.T) {
+	project := model.NewProject()
+	project.Ci.Annotations = map[string]string{
+		"example.org/build":  "BUILD_TAG",
+		"example.org/absent": "ABSENT",
+	}
+	project.Env["BUILD_TAG"] = "kubor-7"
+	target := newTestDeployment("app", newTestPodSpec(map[string]interface{}{"name": "app"}))
+
+	err := appendCiDiscovery(&project, target, nil)
+
+	assert.NoError(t, err)
+	object, template := ciTestAnnotationsOf(target)
+	assert.Equal(t, map[string]string{"example.org/build": "kubor-7"}, object)
+	assert.Equal(t, map[string]string{"example.org/build": "kubor-7"}, template)
+}
+
+func Test_appendCiAnnotations_ignores_non_workloads(t *testing.T) {
+	project := model.NewProject()
+	project.Ci.Annotations = map[string]string{"example.org/build": "BUILD_TAG"}
+	project.Env["BUILD_TAG"] = "kubor-7"
+	target := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+		},
+	}
+	expected := target.DeepCopy()
+
+	err := appendCiDiscovery(&project, target, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, expected, target)
+}
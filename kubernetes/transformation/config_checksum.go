@@ -0,0 +1,146 @@
+package transformation
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/echocat/kubor/model"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sort"
+)
+
+const (
+	configChecksumTransformationName = model.TransformationName("config-checksum")
+
+	configChecksumAnnotation = "kubor.echocat.org/config-checksum"
+)
+
+var (
+	configMapGroupKind = schema.GroupKind{Kind: "ConfigMap"}
+	secretGroupKind    = schema.GroupKind{Kind: "Secret"}
+)
+
+func init() {
+	Default.MustRegisterUpdateFunc(configChecksumTransformationName, appendConfigChecksumOnUpdate)
+	Default.MustRegisterCreateFunc(configChecksumTransformationName, appendConfigChecksum)
+}
+
+func appendConfigChecksumOnUpdate(project *model.Project, _ unstructured.Unstructured, target *unstructured.Unstructured, argument *string) error {
+	return appendConfigChecksum(project, target, argument)
+}
+
+// appendConfigChecksum annotates the pod template of the target with a checksum of all
+// ConfigMaps and Secrets it references which were rendered together with it. If one
+// of them changes the pod template changes, too, which rolls out new pods.
+func appendConfigChecksum(project *model.Project, target *unstructured.Unstructured, _ *string) error {
+	path, ok := PodTemplatePathOf(target)
+	if !ok {
+		return nil
+	}
+	spec, ok, err := NestedMap(target.Object, append(path, "spec")...)
+	if err != nil || !ok {
+		return err
+	}
+
+	hash := sha256.New()
+	atLeastOneFound := false
+	for _, reference := range configReferencesOf(spec) {
+		object := project.Rendered.Get(reference.GroupKind, target.GetNamespace(), reference.Name)
+		if object == nil {
+			continue
+		}
+		content := map[string]interface{}{}
+		for _, field := range []string{"data", "binaryData", "stringData"} {
+			if v, ok := object.Object[field]; ok {
+				content[field] = v
+			}
+		}
+		b, err := json.Marshal(content)
+		if err != nil {
+			return err
+		}
+		hash.Write([]byte(reference.Kind + "/" + reference.Name + "\n"))
+		hash.Write(b)
+		hash.Write([]byte("\n"))
+		atLeastOneFound = true
+	}
+	if !atLeastOneFound {
+		return nil
+	}
+
+	fields := append(path, "metadata", "annotations")
+	annotations, _, err := NestedStringMap(target.Object, fields...)
+	if err != nil {
+		return err
+	}
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[configChecksumAnnotation] = hex.EncodeToString(hash.Sum(nil))
+	return unstructured.SetNestedStringMap(target.Object, annotations, fields...)
+}
+
+type configReference struct {
+	schema.GroupKind
+	Name string
+}
+
+// configReferencesOf returns all ConfigMaps and Secrets referenced by volumes, envFrom
+// and env valueFrom of the given pod spec; sorted and without duplicates.
+func configReferencesOf(spec map[string]interface{}) []configReference {
+	found := map[configReference]bool{}
+	add := func(groupKind schema.GroupKind, in map[string]interface{}, fields ...string) {
+		if name, _, _ := unstructured.NestedString(in, fields...); name != "" {
+			found[configReference{groupKind, name}] = true
+		}
+	}
+
+	volumes, _, _ := unstructured.NestedSlice(spec, "volumes")
+	for _, volume := range mapsOf(volumes) {
+		add(configMapGroupKind, volume, "configMap", "name")
+		add(secretGroupKind, volume, "secret", "secretName")
+		sources, _, _ := unstructured.NestedSlice(volume, "projected", "sources")
+		for _, source := range mapsOf(sources) {
+			add(configMapGroupKind, source, "configMap", "name")
+			add(secretGroupKind, source, "secret", "name")
+		}
+	}
+
+	for _, field := range []string{"initContainers", "containers"} {
+		containers, _, _ := unstructured.NestedSlice(spec, field)
+		for _, container := range mapsOf(containers) {
+			envFrom, _, _ := unstructured.NestedSlice(container, "envFrom")
+			for _, source := range mapsOf(envFrom) {
+				add(configMapGroupKind, source, "configMapRef", "name")
+				add(secretGroupKind, source, "secretRef", "name")
+			}
+			env, _, _ := unstructured.NestedSlice(container, "env")
+			for _, variable := range mapsOf(env) {
+				add(configMapGroupKind, variable, "valueFrom", "configMapKeyRef", "name")
+				add(secretGroupKind, variable, "valueFrom", "secretKeyRef", "name")
+			}
+		}
+	}
+
+	result := make([]configReference, 0, len(found))
+	for reference := range found {
+		result = append(result, reference)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Kind != result[j].Kind {
+			return result[i].Kind < result[j].Kind
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+func mapsOf(in []interface{}) (result []map[string]interface{}) {
+	for _, candidate := range in {
+		if m, ok := candidate.(map[string]interface{}); ok {
+			result = append(result, m)
+		}
+	}
+	return
+}
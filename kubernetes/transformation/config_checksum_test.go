@@ -0,0 +1,126 @@
+package transformation
+
+import (
+	"github.com/echocat/kubor/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"testing"
+)
+
+func newConfigChecksumTestProject(objects ...*unstructured.Unstructured) *model.Project {
+	project := model.NewProject()
+	for _, object := range objects {
+		project.Rendered.Add(object)
+	}
+	return &project
+}
+
+func newConfigChecksumTestConfigMap(name string, data map[string]interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]interface{}{"name": name},
+		"data":       data,
+	}}
+}
+
+func configChecksumOf(t *testing.T, target *unstructured.Unstructured) string {
+	result, _, err := unstructured.NestedString(target.Object, "spec", "template", "metadata", "annotations", configChecksumAnnotation)
+	require.NoError(t, err)
+	return result
+}
+
+func Test_appendConfigChecksum_changes_with_referenced_content(t *testing.T) {
+	podSpec := func() map[string]interface{} {
+		return map[string]interface{}{
+			"volumes": []interface{}{
+				map[string]interface{}{"name": "config", "configMap": map[string]interface{}{"name": "config"}},
+			},
+			"containers": []interface{}{
+				map[string]interface{}{
+					"name": "app",
+					"env": []interface{}{
+						map[string]interface{}{"name": "A", "valueFrom": map[string]interface{}{
+							"secretKeyRef": map[string]interface{}{"name": "credentials", "key": "a"},
+						}},
+					},
+				},
+			},
+		}
+	}
+	secret := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata":   map[string]interface{}{"name": "credentials"},
+		"stringData": map[string]interface{}{"a": "secret"},
+	}}
+
+	first := newTestDeployment("app", podSpec())
+	require.NoError(t, appendConfigChecksum(newConfigChecksumTestProject(
+		newConfigChecksumTestConfigMap("config", map[string]interface{}{"a": "1"}), secret,
+	), first, nil))
+	same := newTestDeployment("app", podSpec())
+	require.NoError(t, appendConfigChecksum(newConfigChecksumTestProject(
+		newConfigChecksumTestConfigMap("config", map[string]interface{}{"a": "1"}), secret,
+	), same, nil))
+	changed := newTestDeployment("app", podSpec())
+	require.NoError(t, appendConfigChecksum(newConfigChecksumTestProject(
+		newConfigChecksumTestConfigMap("config", map[string]interface{}{"a": "2"}), secret,
+	), changed, nil))
+
+	assert.Len(t, configChecksumOf(t, first), 64)
+	assert.Equal(t, configChecksumOf(t, first), configChecksumOf(t, same))
+	assert.NotEqual(t, configChecksumOf(t, first), configChecksumOf(t, changed))
+}
+
+func Test_appendConfigChecksum_ignores_not_rendered_references(t *testing.T) {
+	target := newTestDeployment("app", map[string]interface{}{
+		"containers": []interface{}{
+			map[string]interface{}{
+				"name": "app",
+				"envFrom": []interface{}{
+					map[string]interface{}{"configMapRef": map[string]interface{}{"name": "external"}},
+				},
+			},
+		},
+	})
+	expected := target.DeepCopy()
+
+	require.NoError(t, appendConfigChecksum(newConfigChecksumTestProject(
+		newConfigChecksumTestConfigMap("other", map[string]interface{}{"a": "1"}),
+	), target, nil))
+
+	assert.Equal(t, expected, target)
+}
+
+func Test_configReferencesOf(t *testing.T) {
+	actual := configReferencesOf(map[string]interface{}{
+		"volumes": []interface{}{
+			map[string]interface{}{"name": "a", "secret": map[string]interface{}{"secretName": "s1"}},
+			map[string]interface{}{"name": "b", "projected": map[string]interface{}{"sources": []interface{}{
+				map[string]interface{}{"configMap": map[string]interface{}{"name": "c2"}},
+				map[string]interface{}{"secret": map[string]interface{}{"name": "s2"}},
+			}}},
+		},
+		"initContainers": []interface{}{
+			map[string]interface{}{"envFrom": []interface{}{
+				map[string]interface{}{"secretRef": map[string]interface{}{"name": "s1"}},
+			}},
+		},
+		"containers": []interface{}{
+			map[string]interface{}{"env": []interface{}{
+				map[string]interface{}{"name": "A", "valueFrom": map[string]interface{}{
+					"configMapKeyRef": map[string]interface{}{"name": "c1", "key": "a"},
+				}},
+			}},
+		},
+	})
+
+	assert.Equal(t, []configReference{
+		{configMapGroupKind, "c1"},
+		{configMapGroupKind, "c2"},
+		{secretGroupKind, "s1"},
+		{secretGroupKind, "s2"},
+	}, actual)
+}
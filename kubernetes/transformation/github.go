@@ -0,0 +1,60 @@
+package transformation
+
+import (
+	"github.com/echocat/kubor/model"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"strings"
+)
+
+const (
+	githubDiscoveryTransformationName = model.TransformationName("github-discovery")
+
+	githubAnnotationRepository = "github.com/repository"
+	githubAnnotationCommitSha  = "github.com/commit-sha"
+	githubAnnotationRunId      = "github.com/run-id"
+	githubAnnotationRunUrl     = "github.com/run-url"
+	githubAnnotationActor      = "github.com/actor"
+
+	// githubEnvActions is set to "true" by GitHub Actions for every workflow run.
+	githubEnvActions    = "GITHUB_ACTIONS"
+	githubEnvServerUrl  = "GITHUB_SERVER_URL"
+	githubEnvRepository = "GITHUB_REPOSITORY"
+	githubEnvSha        = "GITHUB_SHA"
+	githubEnvRunId      = "GITHUB_RUN_ID"
+	githubEnvActor      = "GITHUB_ACTOR"
+)
+
+var githubAnnotations = []ciAnnotation{
+	ciAnnotationOfEnv(githubAnnotationRepository, githubEnvRepository),
+	ciAnnotationOfEnv(githubAnnotationCommitSha, githubEnvSha),
+	ciAnnotationOfEnv(githubAnnotationRunId, githubEnvRunId),
+	{githubAnnotationRunUrl, githubRunUrlOf},
+	ciAnnotationOfEnv(githubAnnotationActor, githubEnvActor),
+}
+
+func init() {
+	Default.MustRegisterUpdateFunc(githubDiscoveryTransformationName, appendGithubDiscoveryOnUpdate)
+	Default.MustRegisterCreateFunc(githubDiscoveryTransformationName, appendGithubDiscovery)
+}
+
+func appendGithubDiscoveryOnUpdate(project *model.Project, _ unstructured.Unstructured, target *unstructured.Unstructured, argument *string) error {
+	return appendGithubDiscovery(project, target, argument)
+}
+
+// appendGithubDiscovery annotates workloads with information about the GitHub Actions
+// workflow run kubor runs in. Outside of GitHub Actions (GITHUB_ACTIONS is not true)
+// nothing is set.
+func appendGithubDiscovery(project *model.Project, target *unstructured.Unstructured, _ *string) error {
+	if project.Env[githubEnvActions] != "true" {
+		return nil
+	}
+	return appendCiAnnotations(project, target, githubAnnotations)
+}
+
+func githubRunUrlOf(env map[string]string) string {
+	server, repository, runId := env[githubEnvServerUrl], env[githubEnvRepository], env[githubEnvRunId]
+	if server == "" || repository == "" || runId == "" {
+		return ""
+	}
+	return strings.TrimSuffix(server, "/") + "/" + repository + "/actions/runs/" + runId
+}
@@ -0,0 +1,53 @@
+package transformation
+
+import (
+	"github.com/echocat/kubor/model"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func newGithubTestProject() model.Project {
+	project := model.NewProject()
+	project.Env[githubEnvServerUrl] = "https://github.com"
+	project.Env[githubEnvRepository] = "echocat/kubor"
+	project.Env[githubEnvSha] = "abc123"
+	project.Env[githubEnvRunId] = "42"
+	project.Env[githubEnvActor] = "octocat"
+	return project
+}
+
+func Test_appendGithubDiscovery(t *testing.T) {
+	project := newGithubTestProject()
+	project.Env[githubEnvActions] = "true"
+	target := newTestDeployment("app", newTestPodSpec(map[string]interface{}{"name": "app"}))
+
+	err := appendGithubDiscovery(&project, target, nil)
+
+	assert.NoError(t, err)
+	expected := map[string]string{
+		githubAnnotationRepository: "echocat/kubor",
+		githubAnnotationCommitSha:  "abc123",
+		githubAnnotationRunId:      "42",
+		githubAnnotationRunUrl:     "https://github.com/echocat/kubor/actions/runs/42",
+		githubAnnotationActor:      "octocat",
+	}
+	object, template := ciTestAnnotationsOf(target)
+	assert.Equal(t, expected, object)
+	assert.Equal(t, expected, template)
+}
+
+func Test_appendGithubDiscovery_outside_of_github_actions(t *testing.T) {
+	for _, actions := range []string{"", "false"} {
+		t.Run(githubEnvActions+"="+actions, func(t *testing.T) {
+			project := newGithubTestProject()
+			project.Env[githubEnvActions] = actions
+			target := newTestDeployment("app", newTestPodSpec(map[string]interface{}{"name": "app"}))
+			expected := target.DeepCopy()
+
+			err := appendGithubDiscovery(&project, target, nil)
+
+			assert.NoError(t, err)
+			assert.Equal(t, expected, target)
+		})
+	}
+}
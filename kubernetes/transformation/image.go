@@ -0,0 +1,125 @@
+package transformation
+
+import (
+	"errors"
+	"fmt"
+	"github.com/echocat/kubor/model"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	imageOverrideTransformationName = model.TransformationName("image-override")
+	imagePolicyTransformationName   = model.TransformationName("image-policy")
+)
+
+var (
+	ErrImagePolicyViolated = errors.New("image policy violated")
+
+	imageContainerFields = []string{"initContainers", "containers"}
+)
+
+func init() {
+	Default.MustRegisterUpdateFunc(imageOverrideTransformationName, overrideImagesOnUpdate)
+	Default.MustRegisterCreateFunc(imageOverrideTransformationName, overrideImages)
+
+	t := checkImagePolicy{}
+	Default.MustRegisterUpdate(&t)
+	Default.MustRegisterCreate(&t)
+}
+
+func overrideImagesOnUpdate(project *model.Project, _ unstructured.Unstructured, target *unstructured.Unstructured, argument *string) error {
+	return overrideImages(project, target, argument)
+}
+
+// overrideImages replaces the tags and digests of all images of the containers of the
+// target by the ones configured with model.Project.Images and rewrites their prefixes
+// afterwards using model.Project.ImageMirrors.
+func overrideImages(project *model.Project, target *unstructured.Unstructured, _ *string) error {
+	if len(project.Images) == 0 && len(project.ImageMirrors) == 0 {
+		return nil
+	}
+	return visitContainerImages(target, func(_ string, image model.ImageReference) (model.ImageReference, error) {
+		if version, ok := project.Images.Find(image); ok {
+			image = image.WithVersion(version)
+		}
+		return project.ImageMirrors.Apply(image), nil
+	})
+}
+
+// checkImagePolicy fails for every image of the containers of the target which
+// violates model.Project.ImagePolicy. It runs after all other transformations (except
+// apply-annotations and apply-labels) to see the final images.
+type checkImagePolicy struct{}
+
+func (instance *checkImagePolicy) GetName() model.TransformationName {
+	return imagePolicyTransformationName
+}
+
+func (instance *checkImagePolicy) GetPriority() int32 {
+	return 999_999_999
+}
+
+func (instance *checkImagePolicy) DefaultEnabled(*unstructured.Unstructured) bool {
+	return true
+}
+
+func (instance *checkImagePolicy) TransformForUpdate(project *model.Project, _ unstructured.Unstructured, target *unstructured.Unstructured, argument *string) error {
+	return instance.TransformForCreate(project, target, argument)
+}
+
+func (instance *checkImagePolicy) TransformForCreate(project *model.Project, target *unstructured.Unstructured, _ *string) error {
+	if !project.ImagePolicy.DenyMutableTags {
+		return nil
+	}
+	return visitContainerImages(target, func(container string, image model.ImageReference) (model.ImageReference, error) {
+		if violation := project.ImagePolicy.Violation(image); violation != "" {
+			return image, fmt.Errorf("%w: image '%v' of container '%s' %s", ErrImagePolicyViolated, image, container, violation)
+		}
+		return image, nil
+	})
+}
+
+// visitContainerImages calls the visitor for the image of every (init) container of
+// the target and replaces the image by the returned one. All kinds with a pod spec
+// known to PodSpecPathOf are visited; this is more than GitlabDiscoveryReceiverGvks
+// which misses for example ReplicationController and batch/v1 CronJob, whose images
+// would otherwise escape overrides, mirrors and the policy.
+func visitContainerImages(target *unstructured.Unstructured, visitor func(container string, image model.ImageReference) (model.ImageReference, error)) error {
+	path, ok := PodSpecPathOf(target)
+	if !ok {
+		return nil
+	}
+	for _, field := range imageContainerFields {
+		fields := append(append([]string{}, path...), field)
+		containers, exist, err := unstructured.NestedSlice(target.Object, fields...)
+		if err != nil {
+			return err
+		}
+		if !exist {
+			continue
+		}
+		for i, pContainer := range containers {
+			name, container, err := getNameOfUncheckedNamedMap(pContainer, i)
+			if err != nil {
+				return err
+			}
+			plain, exist, err := unstructured.NestedString(container, "image")
+			if err != nil {
+				return err
+			}
+			if !exist {
+				continue
+			}
+			image, err := visitor(name, model.ParseImageReference(plain))
+			if err != nil {
+				return err
+			}
+			container["image"] = image.String()
+			containers[i] = container
+		}
+		if err := unstructured.SetNestedSlice(target.Object, containers, fields...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package transformation
+
+import (
+	"github.com/echocat/kubor/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"testing"
+)
+
+func newImageTestPodSpec(images ...string) map[string]interface{} {
+	containers := make([]map[string]interface{}, len(images))
+	for i, image := range images {
+		containers[i] = map[string]interface{}{"name": "c" + string(rune('0'+i)), "image": image}
+	}
+	result := newTestPodSpec(containers...)
+	result["initContainers"] = []interface{}{map[string]interface{}{"name": "init", "image": "busybox"}}
+	return result
+}
+
+func imagesOf(t *testing.T, target *unstructured.Unstructured) (result []string) {
+	for _, field := range imageContainerFields {
+		containers, _, err := unstructured.NestedSlice(target.Object, "spec", "template", "spec", field)
+		require.NoError(t, err)
+		for _, container := range containers {
+			result = append(result, container.(map[string]interface{})["image"].(string))
+		}
+	}
+	return
+}
+
+func Test_overrideImages(t *testing.T) {
+	project := model.NewProject()
+	project.Images = model.ImageOverrides{
+		"registry.example.org/shop/api":  "1.2.3",
+		"registry.example.org/shop/web":  "sha256:abc",
+		"docker.io/library/busybox":      "1.36@sha256:def",
+		"registry.example.org:5000/shop": "2.0.0",
+	}
+	project.ImageMirrors = model.ImageMirrors{
+		"docker.io/":         "mirror.example.org/docker.io/",
+		"docker.io/library/": "mirror.example.org/library/",
+	}
+	target := newTestDeployment("app", newImageTestPodSpec(
+		"registry.example.org/shop/api:latest",
+		"registry.example.org/shop/web:1.0.0@sha256:old",
+		"registry.example.org:5000/shop",
+		"bitnami/redis:7.2",
+	))
+
+	err := overrideImages(&project, target, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"mirror.example.org/library/busybox:1.36@sha256:def",
+		"registry.example.org/shop/api:1.2.3",
+		"registry.example.org/shop/web@sha256:abc",
+		"registry.example.org:5000/shop:2.0.0",
+		"mirror.example.org/docker.io/bitnami/redis:7.2",
+	}, imagesOf(t, target))
+}
+
+func Test_overrideImages_ignores_non_workloads(t *testing.T) {
+	project := model.NewProject()
+	project.Images = model.ImageOverrides{"nginx": "1.27"}
+	target := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"data":       map[string]interface{}{"image": "nginx"},
+	}}
+	expected := target.DeepCopy()
+
+	err := overrideImages(&project, target, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, expected, target)
+}
+
+func Test_overrideImages_of_cronJob(t *testing.T) {
+	project := model.NewProject()
+	project.Images = model.ImageOverrides{"nginx": "1.27"}
+	target := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "batch/v1beta1",
+		"kind":       "CronJob",
+		"spec": map[string]interface{}{"jobTemplate": map[string]interface{}{"spec": map[string]interface{}{
+			"template": map[string]interface{}{"spec": map[string]interface{}{
+				"containers": []interface{}{map[string]interface{}{"name": "job", "image": "nginx:latest"}},
+			}},
+		}}},
+	}}
+
+	err := overrideImages(&project, target, nil)
+
+	assert.NoError(t, err)
+	containers, _, _ := unstructured.NestedSlice(target.Object, "spec", "jobTemplate", "spec", "template", "spec", "containers")
+	assert.Equal(t, "nginx:1.27", containers[0].(map[string]interface{})["image"])
+}
+
+func Test_checkImagePolicy(t *testing.T) {
+	cases := []struct {
+		name        string
+		policy      model.ImagePolicy
+		image       string
+		expectedErr string
+	}{
+		{"disabled", model.ImagePolicy{}, "nginx:latest", ""},
+		{"immutable tag", model.ImagePolicy{DenyMutableTags: true}, "nginx:1.27", ""},
+		{"pinned latest", model.ImagePolicy{DenyMutableTags: true}, "nginx:latest@sha256:abc", ""},
+		{"latest", model.ImagePolicy{DenyMutableTags: true}, "nginx:latest", "image policy violated: image 'nginx:latest' of container 'c0' uses the mutable tag 'latest' without a digest"},
+		{"no tag", model.ImagePolicy{DenyMutableTags: true}, "localhost:5000/nginx", "image policy violated: image 'localhost:5000/nginx' of container 'c0' has neither a tag nor a digest"},
+		{"custom mutable tags", model.ImagePolicy{DenyMutableTags: true, MutableTags: []string{"main"}}, "nginx:main", "image policy violated: image 'nginx:main' of container 'c0' uses the mutable tag 'main' without a digest"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			project := model.NewProject()
+			project.ImagePolicy = c.policy
+			target := newTestDeployment("app", newImageTestPodSpec(c.image))
+			unstructured.RemoveNestedField(target.Object, "spec", "template", "spec", "initContainers")
+
+			err := (&checkImagePolicy{}).TransformForCreate(&project, target, nil)
+
+			if c.expectedErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, c.expectedErr)
+				assert.ErrorIs(t, err, ErrImagePolicyViolated)
+			}
+		})
+	}
+}
+
+func Test_overrideImages_of_kinds_outside_of_GitlabDiscoveryReceiverGvks(t *testing.T) {
+	cases := []struct {
+		apiVersion string
+		kind       string
+		path       []string
+	}{
+		{"v1", "ReplicationController", []string{"spec", "template", "spec", "containers"}},
+		{"batch/v1", "CronJob", []string{"spec", "jobTemplate", "spec", "template", "spec", "containers"}},
+	}
+	for _, c := range cases {
+		t.Run(c.apiVersion+"/"+c.kind, func(t *testing.T) {
+			project := model.NewProject()
+			project.Images = model.ImageOverrides{"nginx": "1.27"}
+			target := &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": c.apiVersion,
+				"kind":       c.kind,
+			}}
+			require.NoError(t, unstructured.SetNestedSlice(target.Object, []interface{}{
+				map[string]interface{}{"name": "app", "image": "nginx:latest"},
+			}, c.path...))
+			require.False(t, GitlabDiscoveryReceiverGvks.Contains(model.GroupVersionKind(target.GroupVersionKind())))
+
+			err := overrideImages(&project, target, nil)
+
+			assert.NoError(t, err)
+			containers, _, _ := unstructured.NestedSlice(target.Object, c.path...)
+			assert.Equal(t, "nginx:1.27", containers[0].(map[string]interface{})["image"])
+		})
+	}
+}
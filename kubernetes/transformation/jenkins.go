@@ -0,0 +1,55 @@
+package transformation
+
+import (
+	"github.com/echocat/kubor/model"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	jenkinsDiscoveryTransformationName = model.TransformationName("jenkins-discovery")
+
+	jenkinsAnnotationRepository = "jenkins.io/repository"
+	jenkinsAnnotationCommitSha  = "jenkins.io/commit-sha"
+	jenkinsAnnotationJobName    = "jenkins.io/job-name"
+	jenkinsAnnotationBuildId    = "jenkins.io/build-id"
+	jenkinsAnnotationBuildUrl   = "jenkins.io/build-url"
+	jenkinsAnnotationActor      = "jenkins.io/actor"
+
+	// jenkinsEnvUrl is set by Jenkins for every build.
+	jenkinsEnvUrl         = "JENKINS_URL"
+	jenkinsEnvGitUrl      = "GIT_URL"
+	jenkinsEnvGitCommit   = "GIT_COMMIT"
+	jenkinsEnvJobName     = "JOB_NAME"
+	jenkinsEnvBuildId     = "BUILD_ID"
+	jenkinsEnvBuildUrl    = "BUILD_URL"
+	jenkinsEnvBuildUserId = "BUILD_USER_ID"
+)
+
+var jenkinsAnnotations = []ciAnnotation{
+	ciAnnotationOfEnv(jenkinsAnnotationRepository, jenkinsEnvGitUrl),
+	ciAnnotationOfEnv(jenkinsAnnotationCommitSha, jenkinsEnvGitCommit),
+	ciAnnotationOfEnv(jenkinsAnnotationJobName, jenkinsEnvJobName),
+	ciAnnotationOfEnv(jenkinsAnnotationBuildId, jenkinsEnvBuildId),
+	ciAnnotationOfEnv(jenkinsAnnotationBuildUrl, jenkinsEnvBuildUrl),
+	ciAnnotationOfEnv(jenkinsAnnotationActor, jenkinsEnvBuildUserId),
+}
+
+func init() {
+	Default.MustRegisterUpdateFunc(jenkinsDiscoveryTransformationName, appendJenkinsDiscoveryOnUpdate)
+	Default.MustRegisterCreateFunc(jenkinsDiscoveryTransformationName, appendJenkinsDiscovery)
+}
+
+func appendJenkinsDiscoveryOnUpdate(project *model.Project, _ unstructured.Unstructured, target *unstructured.Unstructured, argument *string) error {
+	return appendJenkinsDiscovery(project, target, argument)
+}
+
+// appendJenkinsDiscovery annotates workloads with information about the Jenkins build
+// kubor runs in. The repository and commit are provided by the Git plugin, the actor
+// by the Build User Vars plugin. Outside of Jenkins (no JENKINS_URL) nothing is set,
+// because variables like BUILD_ID are too generic to rely on.
+func appendJenkinsDiscovery(project *model.Project, target *unstructured.Unstructured, _ *string) error {
+	if project.Env[jenkinsEnvUrl] == "" {
+		return nil
+	}
+	return appendCiAnnotations(project, target, jenkinsAnnotations)
+}
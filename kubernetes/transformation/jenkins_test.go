@@ -0,0 +1,41 @@
+package transformation
+
+import (
+	"github.com/echocat/kubor/model"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_appendJenkinsDiscovery_sets_only_present_variables(t *testing.T) {
+	project := model.NewProject()
+	project.Env[jenkinsEnvUrl] = "https://jenkins/"
+	project.Env[jenkinsEnvJobName] = "kubor/main"
+	project.Env[jenkinsEnvBuildId] = "7"
+	project.Env[jenkinsEnvBuildUrl] = "https://jenkins/job/kubor/job/main/7/"
+	target := newTestDeployment("app", newTestPodSpec(map[string]interface{}{"name": "app"}))
+
+	err := appendJenkinsDiscovery(&project, target, nil)
+
+	assert.NoError(t, err)
+	expected := map[string]string{
+		jenkinsAnnotationJobName:  "kubor/main",
+		jenkinsAnnotationBuildId:  "7",
+		jenkinsAnnotationBuildUrl: "https://jenkins/job/kubor/job/main/7/",
+	}
+	object, template := ciTestAnnotationsOf(target)
+	assert.Equal(t, expected, object)
+	assert.Equal(t, expected, template)
+}
+
+func Test_appendJenkinsDiscovery_outside_of_jenkins(t *testing.T) {
+	project := model.NewProject()
+	project.Env[jenkinsEnvGitCommit] = "abc123"
+	project.Env[jenkinsEnvBuildId] = "7"
+	target := newTestDeployment("app", newTestPodSpec(map[string]interface{}{"name": "app"}))
+	expected := target.DeepCopy()
+
+	err := appendJenkinsDiscovery(&project, target, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, expected, target)
+}
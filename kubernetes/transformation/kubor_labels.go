@@ -5,6 +5,7 @@ import (
 	"github.com/echocat/kubor/model"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 const kuborLabelName = model.TransformationName("apply-labels")
@@ -17,6 +18,18 @@ func init() {
 
 var NamespaceGvks = model.BuildGroupVersionKinds(v1.SchemeGroupVersion, &v1.Namespace{}).Build()
 
+// selectorGroupKinds are the kinds whose spec.selector.matchLabels selects the pods of
+// their pod template.
+var selectorGroupKinds = map[schema.GroupKind]bool{
+	{Group: "apps", Kind: "Deployment"}:       true,
+	{Group: "extensions", Kind: "Deployment"}: true,
+	{Group: "apps", Kind: "StatefulSet"}:      true,
+	{Group: "apps", Kind: "DaemonSet"}:        true,
+	{Group: "extensions", Kind: "DaemonSet"}:  true,
+	{Group: "apps", Kind: "ReplicaSet"}:       true,
+	{Group: "extensions", Kind: "ReplicaSet"}: true,
+}
+
 type ensureKuborLabels struct{}
 
 func (instance *ensureKuborLabels) GetName() model.TransformationName {
@@ -31,17 +44,49 @@ func (instance *ensureKuborLabels) DefaultEnabled(target *unstructured.Unstructu
 	return !NamespaceGvks.Contains(model.GroupVersionKind(target.GroupVersionKind()))
 }
 
-func (instance *ensureKuborLabels) TransformForUpdate(p *model.Project, _ unstructured.Unstructured, target *unstructured.Unstructured, argument *string) error {
-	return instance.TransformForCreate(p, target, argument)
+func (instance *ensureKuborLabels) TransformForUpdate(p *model.Project, existing unstructured.Unstructured, target *unstructured.Unstructured, _ *string) error {
+	return instance.transform(p, &existing, target)
 }
 
 func (instance *ensureKuborLabels) TransformForCreate(project *model.Project, target *unstructured.Unstructured, _ *string) error {
+	return instance.transform(project, nil, target)
+}
+
+func (instance *ensureKuborLabels) transform(project *model.Project, existing *unstructured.Unstructured, target *unstructured.Unstructured) error {
+	recommended, err := instance.recommendedLabelsOf(project, target)
+	if err != nil {
+		return err
+	}
+
 	if err := instance.ensureKuborLabelsOfPath(project, target, "metadata", "labels"); err != nil {
 		return err
 	}
-	if err := instance.ensureKuborLabelsOfPath(project, target, "spec", "template", "metadata", "labels"); err != nil {
+	if err := instance.ensureRecommendedLabelsOfPath(recommended, target, "metadata", "labels"); err != nil {
+		return err
+	}
+	if _, specTemplateExists, err := NestedMap(target.Object, "spec", "template"); err != nil {
+		return err
+	} else if specTemplateExists {
+		if err := instance.ensureKuborLabelsOfPath(project, target, "spec", "template", "metadata", "labels"); err != nil {
+			return err
+		}
+	}
+
+	path, ok := PodTemplatePathOf(target)
+	if !ok {
+		return nil
+	}
+	if _, templateExists, err := NestedMap(target.Object, path...); err != nil || !templateExists {
+		return err
+	}
+	if err := instance.ensureRecommendedLabelsOfPath(recommended, target, append(append([]string{}, path...), "metadata", "labels")...); err != nil {
 		return err
 	}
+	if selectorGroupKinds[target.GroupVersionKind().GroupKind()] {
+		if err := instance.ensureSelector(project, existing, target, path); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -62,6 +107,99 @@ func (instance ensureKuborLabels) ensureKuborLabelsOfPath(project *model.Project
 	return unstructured.SetNestedStringMap(target.Object, labels, fields...)
 }
 
+type recommendedLabel struct {
+	model.Label
+	value string
+}
+
+// recommendedLabelsOf returns the recommended labels (app.kubernetes.io/*) with the
+// values for the given target.
+func (instance ensureKuborLabels) recommendedLabelsOf(project *model.Project, target *unstructured.Unstructured) ([]recommendedLabel, error) {
+	pl := project.Labels
+
+	// The stage annotation could already be dropped by apply-annotations; prefer the
+	// object as it was rendered.
+	source := target
+	if rendered := project.Rendered.Get(target.GroupVersionKind().GroupKind(), target.GetNamespace(), target.GetName()); rendered != nil {
+		source = rendered
+	}
+	stage, err := project.Annotations.GetStageFor(source)
+	if err != nil {
+		return nil, err
+	}
+
+	return []recommendedLabel{
+		{pl.Name, support.NormalizeLabelValue(project.ArtifactId.String())},
+		{pl.Instance, support.NormalizeLabelValue(project.ArtifactId.String() + "-" + project.Release)},
+		{pl.Version, support.NormalizeLabelValue(project.Release)},
+		{pl.Component, support.NormalizeLabelValue(stage.String())},
+		{pl.PartOf, support.NormalizeLabelValue(project.GroupId.String())},
+		{pl.ManagedBy, model.ManagedByValue},
+	}, nil
+}
+
+func (instance ensureKuborLabels) ensureRecommendedLabelsOfPath(recommended []recommendedLabel, target *unstructured.Unstructured, fields ...string) error {
+	labels, _, err := NestedStringMap(target.Object, fields...)
+	if err != nil {
+		return err
+	}
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+
+	for _, label := range recommended {
+		// Empty values (like of a missing groupId) are not worth a label.
+		if label.value != "" || label.Action == model.LabelActionDrop {
+			instance.ensureKuborLabel(&labels, label.Label, label.value)
+		}
+	}
+
+	return unstructured.SetNestedStringMap(target.Object, labels, fields...)
+}
+
+// ensureSelector adds the name label of the pod template to spec.selector.matchLabels
+// of the target. The instance label is never added as it changes with every release
+// while the selector is immutable. For the same reason the name label is only added
+// on create; on update it is only kept if it is already part of the selector of the
+// existing object.
+func (instance ensureKuborLabels) ensureSelector(project *model.Project, existing *unstructured.Unstructured, target *unstructured.Unstructured, templatePath []string) error {
+	selector, selectorExists, err := NestedStringMap(target.Object, "spec", "selector", "matchLabels")
+	if err != nil || !selectorExists {
+		return err
+	}
+	templateLabels, _, err := NestedStringMap(target.Object, append(append([]string{}, templatePath...), "metadata", "labels")...)
+	if err != nil {
+		return err
+	}
+	var existingSelector map[string]string
+	if existing != nil {
+		if existingSelector, _, err = NestedStringMap(existing.Object, "spec", "selector", "matchLabels"); err != nil {
+			return err
+		}
+	}
+
+	label := project.Labels.Name
+	if label.Action == model.LabelActionLeave || label.Action == model.LabelActionDrop {
+		return nil
+	}
+	name := label.Name.String()
+	if _, exist := selector[name]; exist {
+		return nil
+	}
+	value, exist := templateLabels[name]
+	if !exist {
+		return nil
+	}
+	if existing != nil {
+		if existingValue, exist := existingSelector[name]; !exist || existingValue != value {
+			return nil
+		}
+	}
+	selector[name] = value
+
+	return unstructured.SetNestedStringMap(target.Object, selector, "spec", "selector", "matchLabels")
+}
+
 func (instance ensureKuborLabels) ensureKuborLabel(labels *map[string]string, label model.Label, value string) {
 	switch label.Action {
 	case model.LabelActionDrop:
@@ -0,0 +1,149 @@
+package transformation
+
+import (
+	"github.com/echocat/kubor/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"testing"
+)
+
+var testEnsureKuborLabels = ensureKuborLabels{}
+
+func newKuborLabelsTestProject() model.Project {
+	project := model.NewProject()
+	project.GroupId = "shop"
+	project.ArtifactId = "api"
+	project.Release = "1.2.3"
+	return project
+}
+
+// setKuborLabelsTestSelector sets the selector of the target and labels its pod template
+// with app=api.
+func setKuborLabelsTestSelector(t *testing.T, target *unstructured.Unstructured, selector map[string]string) {
+	require.NoError(t, unstructured.SetNestedStringMap(target.Object, selector, "spec", "selector", "matchLabels"))
+	require.NoError(t, unstructured.SetNestedStringMap(target.Object, map[string]string{"app": "api"}, "spec", "template", "metadata", "labels"))
+}
+
+func Test_ensureKuborLabels_onCreate(t *testing.T) {
+	project := newKuborLabelsTestProject()
+	project.Labels.Component.Action = model.LabelActionSetIfAbsent
+	target := newTestDeployment("api", newTestPodSpec(map[string]interface{}{"name": "api"}))
+	target.SetAnnotations(map[string]string{model.AnnotationStage: "backend"})
+	setKuborLabelsTestSelector(t, target, map[string]string{"app": "api"})
+
+	err := testEnsureKuborLabels.TransformForCreate(&project, target, nil)
+
+	assert.NoError(t, err)
+	expectedLabels := map[string]string{
+		model.LabelGroupId:      "shop",
+		model.LabelArtifactId:   "api",
+		model.LabelRelease:      "1.2.3",
+		model.LabelAppName:      "api",
+		model.LabelAppInstance:  "api-1.2.3",
+		model.LabelAppVersion:   "1.2.3",
+		model.LabelAppComponent: "backend",
+		model.LabelAppPartOf:    "shop",
+		model.LabelAppManagedBy: "kubor",
+	}
+	labels, _, _ := NestedStringMap(target.Object, "metadata", "labels")
+	assert.Equal(t, expectedLabels, labels)
+
+	expectedLabels["app"] = "api"
+	templateLabels, _, _ := NestedStringMap(target.Object, "spec", "template", "metadata", "labels")
+	assert.Equal(t, expectedLabels, templateLabels)
+
+	selector, _, _ := NestedStringMap(target.Object, "spec", "selector", "matchLabels")
+	assert.Equal(t, map[string]string{
+		"app":              "api",
+		model.LabelAppName: "api",
+	}, selector)
+}
+
+func Test_ensureKuborLabels_onUpdate_keeps_selector_of_existing(t *testing.T) {
+	project := newKuborLabelsTestProject()
+	existing := newTestDeployment("api", newTestPodSpec(map[string]interface{}{"name": "api"}))
+	setKuborLabelsTestSelector(t, existing, map[string]string{
+		"app":              "api",
+		model.LabelAppName: "api",
+	})
+	target := newTestDeployment("api", newTestPodSpec(map[string]interface{}{"name": "api"}))
+	setKuborLabelsTestSelector(t, target, map[string]string{"app": "api"})
+
+	err := testEnsureKuborLabels.TransformForUpdate(&project, *existing, target, nil)
+
+	assert.NoError(t, err)
+	selector, _, _ := NestedStringMap(target.Object, "spec", "selector", "matchLabels")
+	assert.Equal(t, map[string]string{
+		"app":              "api",
+		model.LabelAppName: "api",
+	}, selector)
+}
+
+func Test_ensureKuborLabels_onUpdate_with_changed_release(t *testing.T) {
+	project := newKuborLabelsTestProject()
+	existing := newTestDeployment("api", newTestPodSpec(map[string]interface{}{"name": "api"}))
+	setKuborLabelsTestSelector(t, existing, map[string]string{"app": "api"})
+	assert.NoError(t, testEnsureKuborLabels.TransformForCreate(&project, existing, nil))
+	existingSelector, _, _ := NestedStringMap(existing.Object, "spec", "selector", "matchLabels")
+
+	project.Release = "1.2.4"
+	target := newTestDeployment("api", newTestPodSpec(map[string]interface{}{"name": "api"}))
+	setKuborLabelsTestSelector(t, target, map[string]string{"app": "api"})
+	err := testEnsureKuborLabels.TransformForUpdate(&project, *existing, target, nil)
+
+	assert.NoError(t, err)
+	selector, _, _ := NestedStringMap(target.Object, "spec", "selector", "matchLabels")
+	assert.Equal(t, existingSelector, selector, "selector is immutable")
+	templateLabels, _, _ := NestedStringMap(target.Object, "spec", "template", "metadata", "labels")
+	assert.Equal(t, "api-1.2.4", templateLabels[model.LabelAppInstance])
+	for name, value := range selector {
+		assert.Equal(t, value, templateLabels[name], "selector has to match the pod template")
+	}
+}
+
+func Test_ensureKuborLabels_onUpdate_does_not_add_name_to_existing_selector(t *testing.T) {
+	project := newKuborLabelsTestProject()
+	existing := newTestDeployment("api", newTestPodSpec(map[string]interface{}{"name": "api"}))
+	setKuborLabelsTestSelector(t, existing, map[string]string{"app": "api"})
+	target := newTestDeployment("api", newTestPodSpec(map[string]interface{}{"name": "api"}))
+	setKuborLabelsTestSelector(t, target, map[string]string{"app": "api"})
+
+	err := testEnsureKuborLabels.TransformForUpdate(&project, *existing, target, nil)
+
+	assert.NoError(t, err)
+	selector, _, _ := NestedStringMap(target.Object, "spec", "selector", "matchLabels")
+	assert.Equal(t, map[string]string{"app": "api"}, selector)
+}
+
+func Test_ensureKuborLabels_respects_actions(t *testing.T) {
+	project := newKuborLabelsTestProject()
+	project.GroupId = ""
+	project.Labels.Version.Action = model.LabelActionDrop
+	project.Labels.Name.Action = model.LabelActionSet
+	target := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata": map[string]interface{}{
+			"labels": map[string]interface{}{
+				model.LabelAppName:      "other",
+				model.LabelAppVersion:   "0.0.1",
+				model.LabelAppManagedBy: "helm",
+			},
+		},
+	}}
+
+	err := testEnsureKuborLabels.TransformForCreate(&project, target, nil)
+
+	assert.NoError(t, err)
+	labels, _, _ := NestedStringMap(target.Object, "metadata", "labels")
+	assert.Equal(t, map[string]string{
+		model.LabelGroupId:      "",
+		model.LabelArtifactId:   "api",
+		model.LabelRelease:      "1.2.3",
+		model.LabelAppName:      "api",
+		model.LabelAppInstance:  "api-1.2.3",
+		model.LabelAppManagedBy: "helm",
+	}, labels)
+	assert.NotContains(t, target.Object, "spec")
+}
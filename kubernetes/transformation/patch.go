@@ -0,0 +1,110 @@
+package transformation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/echocat/kubor/model"
+	jsonpatch "gopkg.in/evanphx/json-patch.v4"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+var ErrTransformationAlreadyExists = errors.New("transformation already exists")
+
+// For returns the Default transformations together with all patch and plugin
+// transformations declared by the given project (see model.TransformationPatch and
+// model.TransformationPlugin).
+func For(project *model.Project) (Transformations, error) {
+	result := Transformations{
+		Updates: append(Updates{}, Default.Updates...),
+		Creates: append(Creates{}, Default.Creates...),
+	}
+	for name, candidate := range project.Transformations {
+		var t interface {
+			Create
+			Update
+		}
+		var on model.TransformationEvents
+		if v := candidate.Patch; v != nil {
+			t, on = &patch{transformation{name}, *v}, v.On
+		} else if v := candidate.Plugin; v != nil {
+			t, on = &pluginTransformation{transformation{name}, *v}, v.On
+		} else {
+			continue
+		}
+		if Default.Contains(name) {
+			return Transformations{}, fmt.Errorf("%w: %v", ErrTransformationAlreadyExists, name)
+		}
+		if on.Contains(model.TransformationEventCreate) {
+			if err := result.RegisterCreate(t); err != nil {
+				return Transformations{}, err
+			}
+		}
+		if on.Contains(model.TransformationEventUpdate) {
+			if err := result.RegisterUpdate(t); err != nil {
+				return Transformations{}, err
+			}
+		}
+	}
+	return result, nil
+}
+
+type patch struct {
+	transformation
+	definition model.TransformationPatch
+}
+
+func (instance *patch) GetPriority() int32 {
+	return instance.definition.Priority
+}
+
+func (instance *patch) TransformForUpdate(p *model.Project, _ unstructured.Unstructured, target *unstructured.Unstructured, argument *string) error {
+	return instance.TransformForCreate(p, target, argument)
+}
+
+func (instance *patch) TransformForCreate(p *model.Project, target *unstructured.Unstructured, argument *string) error {
+	if matches, err := instance.definition.Selector.Matches(target); err != nil {
+		return err
+	} else if !matches {
+		return nil
+	}
+
+	content, err := instance.definition.Render(*p, target, argument)
+	if err != nil {
+		return err
+	}
+	original, err := json.Marshal(target.Object)
+	if err != nil {
+		return err
+	}
+
+	var patched []byte
+	switch instance.definition.Type {
+	case model.TransformationPatchTypeJson:
+		if operations, err := jsonpatch.DecodePatch(content); err != nil {
+			return err
+		} else if patched, err = operations.Apply(original); err != nil {
+			return err
+		}
+	case model.TransformationPatchTypeMerge:
+		if patched, err = jsonpatch.MergePatch(original, content); err != nil {
+			return err
+		}
+	case model.TransformationPatchTypeStrategic:
+		gvk := target.GroupVersionKind()
+		if dataStruct, err := scheme.Scheme.New(gvk); runtime.IsNotRegisteredError(err) {
+			return fmt.Errorf("%v patches are not supported for %v; use %v instead", instance.definition.Type, gvk, model.TransformationPatchTypeMerge)
+		} else if err != nil {
+			return err
+		} else if patched, err = strategicpatch.StrategicMergePatch(original, content, dataStruct); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("%w: %v", model.ErrIllegalTransformationPatchType, instance.definition.Type)
+	}
+
+	return target.UnmarshalJSON(patched)
+}
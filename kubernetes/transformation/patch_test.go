@@ -0,0 +1,156 @@
+package transformation
+
+import (
+	"github.com/echocat/kubor/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"testing"
+)
+
+func newPatchTestProject(t *testing.T, transformations string) *model.Project {
+	project := model.NewProject()
+	require.NoError(t, yaml.Unmarshal([]byte(transformations), &project.Transformations))
+	require.NoError(t, project.Transformations.Validate())
+	project.Values = model.Values{"sidecar": map[string]interface{}{"image": "proxy:1.0"}}
+	return &project
+}
+
+func Test_patch_strategic_with_rendered_values(t *testing.T) {
+	project := newPatchTestProject(t, `
+add-sidecar:
+  patch:
+    type: strategic
+    selector:
+      gvks: [{group: apps, version: v1, kind: Deployment}]
+      name: "web-.*"
+    content:
+      spec:
+        template:
+          spec:
+            containers:
+            - name: sidecar
+              image: "{{ .Values.sidecar.image }}"
+`)
+	transformations, err := For(project)
+	require.NoError(t, err)
+
+	target := newTestDeployment("web-a", newTestPodSpec(map[string]interface{}{"name": "app", "image": "app:1.0"}))
+	require.NoError(t, transformations.TransformForCreate(project, target))
+
+	containers, _, _ := unstructured.NestedSlice(target.Object, "spec", "template", "spec", "containers")
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"name": "sidecar", "image": "proxy:1.0"},
+		map[string]interface{}{"name": "app", "image": "app:1.0"},
+	}, containers)
+
+	other := newTestDeployment("api", newTestPodSpec(map[string]interface{}{"name": "app", "image": "app:1.0"}))
+	require.NoError(t, transformations.TransformForCreate(project, other))
+	containers, _, _ = unstructured.NestedSlice(other.Object, "spec", "template", "spec", "containers")
+	assert.Len(t, containers, 1)
+}
+
+func Test_patch_json_rendered_from_template_only_on_update(t *testing.T) {
+	project := newPatchTestProject(t, `
+scale:
+  patch:
+    type: json
+    on: [update]
+    content: |
+      - op: replace
+        path: /spec/replicas
+        value: {{ .Argument }}
+      - op: add
+        path: /metadata/labels
+        value: {name: "{{ .Object.metadata.name }}"}
+`)
+	transformations, err := For(project)
+	require.NoError(t, err)
+
+	created := newTestDeployment("web", newTestPodSpec(map[string]interface{}{"name": "app", "image": "app:1.0"}))
+	require.NoError(t, unstructured.SetNestedField(created.Object, int64(1), "spec", "replicas"))
+	created.SetAnnotations(map[string]string{"transformation.kubor.echocat.org/scale": "3"})
+	require.NoError(t, transformations.TransformForCreate(project, created))
+	replicas, _, _ := unstructured.NestedInt64(created.Object, "spec", "replicas")
+	assert.Equal(t, int64(1), replicas)
+
+	updated := newTestDeployment("web", newTestPodSpec(map[string]interface{}{"name": "app", "image": "app:1.0"}))
+	require.NoError(t, unstructured.SetNestedField(updated.Object, int64(1), "spec", "replicas"))
+	updated.SetAnnotations(map[string]string{"transformation.kubor.echocat.org/scale": "3"})
+	require.NoError(t, transformations.TransformForUpdate(project, unstructured.Unstructured{}, updated))
+	replicas, _, _ = unstructured.NestedInt64(updated.Object, "spec", "replicas")
+	assert.Equal(t, int64(3), replicas)
+	assert.Equal(t, "web", updated.GetLabels()["name"])
+}
+
+func Test_patch_merge_could_be_disabled_by_annotation(t *testing.T) {
+	project := newPatchTestProject(t, `
+drop-replicas:
+  patch:
+    type: merge
+    content:
+      spec:
+        replicas: null
+`)
+	transformations, err := For(project)
+	require.NoError(t, err)
+
+	target := newTestDeployment("web", newTestPodSpec(map[string]interface{}{"name": "app", "image": "app:1.0"}))
+	require.NoError(t, unstructured.SetNestedField(target.Object, int64(1), "spec", "replicas"))
+	require.NoError(t, transformations.TransformForCreate(project, target))
+	_, found, _ := unstructured.NestedFieldNoCopy(target.Object, "spec", "replicas")
+	assert.False(t, found)
+
+	disabled := newTestDeployment("web", newTestPodSpec(map[string]interface{}{"name": "app", "image": "app:1.0"}))
+	require.NoError(t, unstructured.SetNestedField(disabled.Object, int64(1), "spec", "replicas"))
+	disabled.SetAnnotations(map[string]string{"transformation.kubor.echocat.org/drop-replicas": "false"})
+	require.NoError(t, transformations.TransformForCreate(project, disabled))
+	_, found, _ = unstructured.NestedFieldNoCopy(disabled.Object, "spec", "replicas")
+	assert.True(t, found)
+}
+
+func Test_patch_strategic_is_not_supported_for_unknown_kinds(t *testing.T) {
+	project := newPatchTestProject(t, `
+foo:
+  patch:
+    type: strategic
+    content: {spec: {foo: bar}}
+`)
+	transformations, err := For(project)
+	require.NoError(t, err)
+
+	target := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "example.org/v1",
+		"kind":       "Foo",
+	}}
+	assert.EqualError(t, transformations.TransformForCreate(project, target),
+		"cannot evaluate transformation foo with argument nil: strategic patches are not supported for example.org/v1, Kind=Foo; use merge instead")
+}
+
+func Test_For_fails_on_patches_named_like_built_in_transformations(t *testing.T) {
+	project := newPatchTestProject(t, `
+apply-labels:
+  patch:
+    type: merge
+    content: {metadata: {labels: {a: b}}}
+`)
+	_, err := For(project)
+	assert.ErrorIs(t, err, ErrTransformationAlreadyExists)
+}
+
+func Test_For_slots_patches_in_by_priority(t *testing.T) {
+	project := newPatchTestProject(t, `
+early:
+  patch:
+    type: merge
+    priority: -10
+    content: {metadata: {labels: {a: b}}}
+`)
+	transformations, err := For(project)
+	require.NoError(t, err)
+
+	assert.Equal(t, model.TransformationName("early"), transformations.Creates[0].GetName())
+	assert.Equal(t, model.TransformationName("apply-labels"), transformations.Creates[len(transformations.Creates)-1].GetName())
+	assert.Len(t, Default.Creates, len(transformations.Creates)-1)
+}
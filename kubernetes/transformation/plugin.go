@@ -0,0 +1,59 @@
+package transformation
+
+import (
+	"github.com/echocat/kubor/kubernetes/transformation/plugin"
+	"github.com/echocat/kubor/model"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+type pluginTransformation struct {
+	transformation
+	definition model.TransformationPlugin
+}
+
+func (instance *pluginTransformation) GetPriority() int32 {
+	return instance.definition.Priority
+}
+
+func (instance *pluginTransformation) TransformForUpdate(p *model.Project, existing unstructured.Unstructured, target *unstructured.Unstructured, argument *string) error {
+	return instance.execute(p, model.TransformationEventUpdate, &existing, target, argument)
+}
+
+func (instance *pluginTransformation) TransformForCreate(p *model.Project, target *unstructured.Unstructured, argument *string) error {
+	return instance.execute(p, model.TransformationEventCreate, nil, target, argument)
+}
+
+func (instance *pluginTransformation) execute(p *model.Project, event model.TransformationEvent, existing *unstructured.Unstructured, target *unstructured.Unstructured, argument *string) error {
+	if matches, err := instance.definition.Selector.Matches(target); err != nil {
+		return err
+	} else if !matches {
+		return nil
+	}
+
+	request := plugin.Request{
+		Name:     instance.name,
+		Event:    event,
+		Argument: argument,
+		Project:  plugin.ProjectOf(*p),
+		Values:   p.ValuesProvenance.MaskSecrets(p.Values),
+		Object:   target.Object,
+	}
+	if instance.definition.PassSecrets {
+		request.Values = p.Values
+	}
+	if existing != nil {
+		request.Existing = existing.Object
+	}
+	executor := plugin.Executor{
+		Command: instance.definition.ResolveCommand(*p),
+		Args:    instance.definition.Args,
+		Timeout: instance.definition.GetTimeout(),
+	}
+
+	object, err := executor.Execute(request)
+	if err != nil {
+		return err
+	}
+	target.Object = object
+	return nil
+}
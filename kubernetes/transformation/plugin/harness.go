@@ -0,0 +1,56 @@
+package plugin
+
+import (
+	"github.com/echocat/kubor/model"
+	"time"
+)
+
+// Harness executes a plugin exactly like kubor does. It is meant to be used inside of
+// tests of plugins:
+//
+//	h := plugin.NewHarness("./my-plugin")
+//	h.Values = model.Values{"env": "prod"}
+//	object, err := h.Create(deployment, nil)
+type Harness struct {
+	Executor
+	// Name is sent as Request.Name; default is "test".
+	Name    model.TransformationName
+	Project Project
+	Values  model.Values
+}
+
+// NewHarness creates a Harness for the given executable.
+func NewHarness(command string, args ...string) *Harness {
+	return &Harness{
+		Executor: Executor{
+			Command: command,
+			Args:    args,
+			Timeout: 10 * time.Second,
+		},
+		Name:    "test",
+		Project: Project{ArtifactId: "test"},
+		Values:  model.Values{},
+	}
+}
+
+// Create executes the plugin for the given object as it would be created.
+func (instance *Harness) Create(object map[string]interface{}, argument *string) (map[string]interface{}, error) {
+	return instance.Execute(instance.request(model.TransformationEventCreate, object, nil, argument))
+}
+
+// Update executes the plugin for the given object as it would update existing.
+func (instance *Harness) Update(existing, object map[string]interface{}, argument *string) (map[string]interface{}, error) {
+	return instance.Execute(instance.request(model.TransformationEventUpdate, object, existing, argument))
+}
+
+func (instance *Harness) request(event model.TransformationEvent, object, existing map[string]interface{}, argument *string) Request {
+	return Request{
+		Name:     instance.Name,
+		Event:    event,
+		Argument: argument,
+		Project:  instance.Project,
+		Values:   instance.Values,
+		Object:   object,
+		Existing: existing,
+	}
+}
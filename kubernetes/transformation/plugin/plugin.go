@@ -0,0 +1,184 @@
+// Package plugin implements the protocol between kubor and transformation plugins
+// (see model.TransformationPlugin).
+//
+// For every object kubor executes the plugin once, writes a Request as JSON to its
+// stdin and expects a Response as JSON on its stdout. Everything the plugin writes to
+// stderr is only used for error messages and debug logging. The plugin has to exit
+// with code 0 if it was able to write a Response (even if this contains an error).
+//
+// The protocol is versioned by ProtocolVersion which is also provided to the plugin
+// by the environment variable KUBOR_PLUGIN_PROTOCOL. Kubor rejects every Response of
+// another version.
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/echocat/kubor/model"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+const (
+	ProtocolVersion    = "transformation.kubor.echocat.org/v1"
+	RequestKind        = "TransformationRequest"
+	ResponseKind       = "TransformationResponse"
+	EnvProtocolVersion = "KUBOR_PLUGIN_PROTOCOL"
+)
+
+var (
+	ErrFailed          = errors.New("plugin failed")
+	ErrTimeout         = errors.New("plugin timed out")
+	ErrIllegalResponse = errors.New("illegal plugin response")
+	ErrIllegalRequest  = errors.New("illegal plugin request")
+)
+
+// Request is sent by kubor to the plugin.
+type Request struct {
+	ApiVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+
+	// Name is the name of the transformation the plugin is declared for.
+	Name  model.TransformationName  `json:"name"`
+	Event model.TransformationEvent `json:"event"`
+	// Argument is the argument of the transformation (see
+	// transformation.kubor.echocat.org/<name> annotation); absent if there is none.
+	Argument *string `json:"argument,omitempty"`
+
+	Project Project `json:"project"`
+	// Values are the values of the project. Secret values are masked unless the
+	// plugin is declared with passSecrets.
+	Values model.Values `json:"values"`
+
+	// Object is the object to transform.
+	Object map[string]interface{} `json:"object"`
+	// Existing is the object as it currently exists in the cluster; only present on
+	// update.
+	Existing map[string]interface{} `json:"existing,omitempty"`
+}
+
+// Project is the identity of the project the object belongs to.
+type Project struct {
+	GroupId    string `json:"groupId,omitempty"`
+	ArtifactId string `json:"artifactId"`
+	Release    string `json:"release,omitempty"`
+	// Context is the name of the Kubernetes context.
+	Context string `json:"context,omitempty"`
+}
+
+// ProjectOf returns the Project for the given model.Project.
+func ProjectOf(project model.Project) Project {
+	return Project{
+		GroupId:    project.GroupId.String(),
+		ArtifactId: project.ArtifactId.String(),
+		Release:    project.Release,
+		Context:    project.Context,
+	}
+}
+
+// Response is sent by the plugin back to kubor. Either Object or Error has to be set.
+type Response struct {
+	ApiVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+
+	// Object is the transformed object. It has to keep apiVersion, kind, namespace
+	// and name of the object of the Request.
+	Object map[string]interface{} `json:"object,omitempty"`
+	// Error fails the transformation with the given message.
+	Error string `json:"error,omitempty"`
+}
+
+// Executor executes a plugin.
+type Executor struct {
+	Command string
+	Args    []string
+	Timeout time.Duration
+}
+
+// Execute executes the plugin with the given request and returns the transformed
+// object.
+func (instance Executor) Execute(request Request) (map[string]interface{}, error) {
+	request.ApiVersion, request.Kind = ProtocolVersion, RequestKind
+	in, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrIllegalRequest, err)
+	}
+
+	timeout := instance.Timeout
+	if timeout <= 0 {
+		timeout = model.DefaultTransformationPluginTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, instance.Command, instance.Args...)
+	cmd.Stdin = bytes.NewReader(in)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	cmd.Env = append(os.Environ(), EnvProtocolVersion+"="+ProtocolVersion)
+	// Do not wait for children of the plugin which still hold stdout or stderr.
+	cmd.WaitDelay = time.Second
+
+	if rErr := cmd.Run(); ctx.Err() == context.DeadlineExceeded {
+		return nil, fmt.Errorf("%w after %v", ErrTimeout, timeout)
+	} else if rErr != nil {
+		return nil, fmt.Errorf("%w: %v%s", ErrFailed, rErr, formatStderr(stderr))
+	}
+
+	var response Response
+	if err := json.Unmarshal(stdout.Bytes(), &response); err != nil {
+		return nil, fmt.Errorf("%w: %v%s", ErrIllegalResponse, err, formatStderr(stderr))
+	}
+	if response.ApiVersion != ProtocolVersion || response.Kind != ResponseKind {
+		return nil, fmt.Errorf("%w: expected apiVersion %s and kind %s but got %s and %s",
+			ErrIllegalResponse, ProtocolVersion, ResponseKind, response.ApiVersion, response.Kind)
+	}
+	if response.Error != "" {
+		return nil, fmt.Errorf("%w: %s", ErrFailed, response.Error)
+	}
+	if response.Object == nil {
+		return nil, fmt.Errorf("%w: neither object nor error", ErrIllegalResponse)
+	}
+	if err := checkIdentity(request.Object, response.Object); err != nil {
+		return nil, err
+	}
+	return response.Object, nil
+}
+
+func checkIdentity(expected, actual map[string]interface{}) error {
+	for _, path := range [][]string{{"apiVersion"}, {"kind"}, {"metadata", "namespace"}, {"metadata", "name"}} {
+		if e, a := nestedString(expected, path...), nestedString(actual, path...); e != a {
+			return fmt.Errorf("%w: %s of object should not be changed but it was changed from '%s' to '%s'",
+				ErrIllegalResponse, strings.Join(path, "."), e, a)
+		}
+	}
+	return nil
+}
+
+func nestedString(in map[string]interface{}, path ...string) string {
+	var current interface{} = in
+	for _, element := range path {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return ""
+		}
+		current = m[element]
+	}
+	if v, ok := current.(string); ok {
+		return v
+	}
+	return ""
+}
+
+func formatStderr(stderr bytes.Buffer) string {
+	if v := strings.TrimSpace(stderr.String()); v != "" {
+		return "\n" + v
+	}
+	return ""
+}
@@ -0,0 +1,143 @@
+package plugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"testing"
+	"time"
+)
+
+const envTestMode = "KUBOR_TEST_PLUGIN_MODE"
+
+// TestMain lets the test binary act as plugin if envTestMode is set.
+func TestMain(m *testing.M) {
+	switch os.Getenv(envTestMode) {
+	case "":
+		os.Exit(m.Run())
+	case "label":
+		Serve(func(request Request) (map[string]interface{}, error) {
+			metadata := request.Object["metadata"].(map[string]interface{})
+			metadata["labels"] = map[string]interface{}{
+				"event":    string(request.Event),
+				"env":      fmt.Sprint(request.Values["env"]),
+				"artifact": request.Project.ArtifactId,
+				"protocol": os.Getenv(EnvProtocolVersion),
+				"existing": fmt.Sprint(request.Existing != nil),
+			}
+			return request.Object, nil
+		})
+	case "fail":
+		Serve(func(request Request) (map[string]interface{}, error) {
+			return nil, errors.New("argument " + *request.Argument + " is not supported")
+		})
+	case "rename":
+		Serve(func(request Request) (map[string]interface{}, error) {
+			request.Object["kind"] = "StatefulSet"
+			return request.Object, nil
+		})
+	case "sleep":
+		time.Sleep(10 * time.Second)
+	case "crash":
+		_, _ = fmt.Fprintln(os.Stderr, "something went wrong")
+		os.Exit(3)
+	case "old":
+		_, _ = fmt.Fprintln(os.Stdout, `{"apiVersion":"transformation.kubor.echocat.org/v0","kind":"TransformationResponse","object":{}}`)
+	}
+}
+
+func newTestHarness(t *testing.T, mode string) *Harness {
+	t.Setenv(envTestMode, mode)
+	return NewHarness(os.Args[0])
+}
+
+func newTestObject() map[string]interface{} {
+	return map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   map[string]interface{}{"name": "app", "namespace": "shop"},
+	}
+}
+
+func Test_Harness_Create(t *testing.T) {
+	h := newTestHarness(t, "label")
+	h.Values["env"] = "prod"
+
+	actual, err := h.Create(newTestObject(), nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"event":    "create",
+		"env":      "prod",
+		"artifact": "test",
+		"protocol": ProtocolVersion,
+		"existing": "false",
+	}, actual["metadata"].(map[string]interface{})["labels"])
+}
+
+func Test_Harness_Update(t *testing.T) {
+	h := newTestHarness(t, "label")
+
+	actual, err := h.Update(newTestObject(), newTestObject(), nil)
+
+	require.NoError(t, err)
+	labels := actual["metadata"].(map[string]interface{})["labels"].(map[string]interface{})
+	assert.Equal(t, "update", labels["event"])
+	assert.Equal(t, "true", labels["existing"])
+}
+
+func Test_Executor_Execute_fails(t *testing.T) {
+	argument := "foo"
+	cases := []struct {
+		mode        string
+		expectedErr error
+		expected    string
+	}{
+		{"fail", ErrFailed, "plugin failed: argument foo is not supported"},
+		{"rename", ErrIllegalResponse, "illegal plugin response: kind of object should not be changed but it was changed from 'Deployment' to 'StatefulSet'"},
+		{"crash", ErrFailed, "plugin failed: exit status 3\nsomething went wrong"},
+		{"old", ErrIllegalResponse, "illegal plugin response: expected apiVersion transformation.kubor.echocat.org/v1 and kind TransformationResponse but got transformation.kubor.echocat.org/v0 and TransformationResponse"},
+	}
+	for _, c := range cases {
+		t.Run(c.mode, func(t *testing.T) {
+			h := newTestHarness(t, c.mode)
+
+			_, err := h.Create(newTestObject(), &argument)
+
+			assert.ErrorIs(t, err, c.expectedErr)
+			assert.EqualError(t, err, c.expected)
+		})
+	}
+}
+
+func Test_Executor_Execute_timeout(t *testing.T) {
+	h := newTestHarness(t, "sleep")
+	h.Timeout = 100 * time.Millisecond
+
+	started := time.Now()
+	_, err := h.Create(newTestObject(), nil)
+
+	assert.ErrorIs(t, err, ErrTimeout)
+	assert.Less(t, time.Since(started), 5*time.Second)
+}
+
+func Test_ServeWith_rejects_other_protocol_versions(t *testing.T) {
+	in, err := json.Marshal(Request{ApiVersion: "transformation.kubor.echocat.org/v2", Kind: RequestKind, Event: "create"})
+	require.NoError(t, err)
+	var out bytes.Buffer
+
+	code := ServeWith(bytes.NewReader(in), &out, func(Request) (map[string]interface{}, error) {
+		t.Fatal("handler should not be called")
+		return nil, nil
+	})
+
+	assert.Equal(t, 0, code)
+	var response Response
+	require.NoError(t, json.Unmarshal(out.Bytes(), &response))
+	assert.Equal(t, ProtocolVersion, response.ApiVersion)
+	assert.Contains(t, response.Error, "illegal plugin request: expected apiVersion transformation.kubor.echocat.org/v1")
+}
@@ -0,0 +1,45 @@
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Handler transforms the object of the given request. Returned errors are sent back
+// to kubor as Response.Error.
+type Handler func(request Request) (object map[string]interface{}, err error)
+
+// Serve implements the plugin side of the protocol for plugins written in Go: It reads
+// the Request from stdin, calls the handler and writes the Response to stdout. It
+// exits the process afterwards.
+func Serve(handler Handler) {
+	os.Exit(ServeWith(os.Stdin, os.Stdout, handler))
+}
+
+// ServeWith is like Serve but with explicit streams; it returns the exit code instead
+// of exiting the process.
+func ServeWith(in io.Reader, out io.Writer, handler Handler) int {
+	response := Response{
+		ApiVersion: ProtocolVersion,
+		Kind:       ResponseKind,
+	}
+	var request Request
+	if err := json.NewDecoder(in).Decode(&request); err != nil {
+		response.Error = fmt.Sprintf("%v: %v", ErrIllegalRequest, err)
+	} else if request.ApiVersion != ProtocolVersion || request.Kind != RequestKind {
+		response.Error = fmt.Sprintf("%v: expected apiVersion %s and kind %s but got %s and %s",
+			ErrIllegalRequest, ProtocolVersion, RequestKind, request.ApiVersion, request.Kind)
+	} else if object, err := handler(request); err != nil {
+		response.Error = err.Error()
+	} else {
+		response.Object = object
+	}
+
+	if err := json.NewEncoder(out).Encode(response); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "cannot write response: %v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,84 @@
+package transformation
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/echocat/kubor/kubernetes/transformation/plugin"
+	"github.com/echocat/kubor/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_pluginTransformation_replaces_object(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "plugin.sh"), []byte(`#!/bin/sh
+cat > /dev/null
+echo '{"apiVersion":"transformation.kubor.echocat.org/v1","kind":"TransformationResponse","object":{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"web-a","labels":{"patched":"'"$1"'"}}}}'
+`), 0755))
+	project := newPatchTestProject(t, `
+company-policies:
+  plugin:
+    command: ./plugin.sh
+    args: [yes]
+    priority: 100
+    on: [create]
+    selector:
+      name: "web-.*"
+`)
+	project.Root = root
+	transformations, err := For(project)
+	require.NoError(t, err)
+	assert.True(t, transformations.Contains("company-policies"))
+
+	selected := newTestDeployment("web-a", newTestPodSpec(map[string]interface{}{"name": "app", "image": "app:1.0"}))
+	require.NoError(t, transformations.TransformForCreate(project, selected))
+	other := newTestDeployment("other", newTestPodSpec(map[string]interface{}{"name": "app", "image": "app:1.0"}))
+	require.NoError(t, transformations.TransformForCreate(project, other))
+
+	assert.Equal(t, "yes", selected.GetLabels()["patched"])
+	assert.NotContains(t, selected.Object, "spec")
+	assert.NotContains(t, other.GetLabels(), "patched")
+	assert.Contains(t, other.Object, "spec")
+}
+
+func Test_pluginTransformation_masks_secret_values(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "plugin.sh"), []byte(`#!/bin/sh
+cat > "$1"
+echo '{"apiVersion":"transformation.kubor.echocat.org/v1","kind":"TransformationResponse","object":{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"app"}}}'
+`), 0755))
+	cases := []struct {
+		passSecrets bool
+		expected    string
+	}{
+		{false, "*****"},
+		{true, "s3cret"},
+	}
+	for _, c := range cases {
+		t.Run(fmt.Sprint("passSecrets=", c.passSecrets), func(t *testing.T) {
+			requestFile := filepath.Join(t.TempDir(), "request.json")
+			project := newPatchTestProject(t, fmt.Sprintf(`
+record-request:
+  plugin:
+    command: ./plugin.sh
+    args: [%q]
+    passSecrets: %v
+`, requestFile, c.passSecrets))
+			project.Root = root
+			project.Values = model.Values{"db": map[string]interface{}{"host": "db", "password": "s3cret"}}
+			transformations, err := For(project)
+			require.NoError(t, err)
+
+			require.NoError(t, transformations.TransformForCreate(project, newTestDeployment("app", newTestPodSpec())))
+
+			content, err := os.ReadFile(requestFile)
+			require.NoError(t, err)
+			var request plugin.Request
+			require.NoError(t, json.Unmarshal(content, &request))
+			assert.Equal(t, model.Values{"db": map[string]interface{}{"host": "db", "password": c.expected}}, request.Values)
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package transformation
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var podGroupKind = schema.GroupKind{Kind: "Pod"}
+
+// podTemplatePaths are the locations of the pod template inside of the kinds which
+// manage pods.
+var podTemplatePaths = map[schema.GroupKind][]string{
+	{Group: "apps", Kind: "Deployment"}:        {"spec", "template"},
+	{Group: "extensions", Kind: "Deployment"}:  {"spec", "template"},
+	{Group: "apps", Kind: "StatefulSet"}:       {"spec", "template"},
+	{Group: "apps", Kind: "DaemonSet"}:         {"spec", "template"},
+	{Group: "extensions", Kind: "DaemonSet"}:   {"spec", "template"},
+	{Group: "apps", Kind: "ReplicaSet"}:        {"spec", "template"},
+	{Group: "extensions", Kind: "ReplicaSet"}:  {"spec", "template"},
+	{Group: "", Kind: "ReplicationController"}: {"spec", "template"},
+	{Group: "batch", Kind: "Job"}:              {"spec", "template"},
+	{Group: "batch", Kind: "CronJob"}:          {"spec", "jobTemplate", "spec", "template"},
+}
+
+// PodTemplatePathOf returns the location of the pod template inside of the target if
+// it is a kind which manages pods.
+func PodTemplatePathOf(target *unstructured.Unstructured) ([]string, bool) {
+	path, ok := podTemplatePaths[target.GroupVersionKind().GroupKind()]
+	return path, ok
+}
+
+// PodSpecPathOf returns the location of the pod spec inside of the target if it is
+// either a Pod itself or a kind which manages pods.
+func PodSpecPathOf(target *unstructured.Unstructured) ([]string, bool) {
+	if target.GroupVersionKind().GroupKind() == podGroupKind {
+		return []string{"spec"}, true
+	}
+	path, ok := PodTemplatePathOf(target)
+	if !ok {
+		return nil, false
+	}
+	return append(path[:len(path):len(path)], "spec"), true
+}
@@ -0,0 +1,150 @@
+package transformation
+
+import (
+	"fmt"
+	"github.com/echocat/kubor/model"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const podDefaultsTransformationName = model.TransformationName("pod-defaults")
+
+func init() {
+	Default.MustRegisterUpdateFunc(podDefaultsTransformationName, injectPodDefaultsOnUpdate)
+	Default.MustRegisterCreateFunc(podDefaultsTransformationName, injectPodDefaults)
+}
+
+func injectPodDefaultsOnUpdate(project *model.Project, _ unstructured.Unstructured, target *unstructured.Unstructured, argument *string) error {
+	return injectPodDefaults(project, target, argument)
+}
+
+// injectPodDefaults sets all values of the model.Project.PodDefaults which select the
+// target into its pod spec if they are not already defined.
+func injectPodDefaults(project *model.Project, target *unstructured.Unstructured, _ *string) error {
+	path, ok := PodSpecPathOf(target)
+	if !ok {
+		return nil
+	}
+	spec, exist, err := NestedMap(target.Object, path...)
+	if err != nil || !exist {
+		return err
+	}
+
+	for i, pd := range project.PodDefaults {
+		if matches, err := pd.Selector.Matches(target); err != nil {
+			return fmt.Errorf("cannot evaluate selector of podDefaults[%d]: %w", i, err)
+		} else if !matches {
+			continue
+		}
+		if err := injectPodDefault(pd, spec); err != nil {
+			return fmt.Errorf("cannot inject podDefaults[%d]: %w", i, err)
+		}
+	}
+
+	return unstructured.SetNestedMap(target.Object, spec, path...)
+}
+
+func injectPodDefault(pd model.PodDefault, spec map[string]interface{}) error {
+	if v := pd.RunAsNonRoot; v != nil {
+		if err := setNestedFieldIfAbsent(spec, *v, "securityContext", "runAsNonRoot"); err != nil {
+			return err
+		}
+	}
+	if v := pd.SeccompProfile; v != nil {
+		profile := map[string]interface{}{"type": string(v.Type)}
+		if v.LocalhostProfile != "" {
+			profile["localhostProfile"] = v.LocalhostProfile
+		}
+		if err := setNestedFieldIfAbsent(spec, profile, "securityContext", "seccompProfile"); err != nil {
+			return err
+		}
+	}
+	if v := pd.AutomountServiceAccountToken; v != nil {
+		if err := setNestedFieldIfAbsent(spec, *v, "automountServiceAccountToken"); err != nil {
+			return err
+		}
+	}
+
+	for _, field := range imageContainerFields {
+		containers, exist, err := unstructured.NestedSlice(spec, field)
+		if err != nil {
+			return err
+		}
+		if !exist {
+			continue
+		}
+		for i, pContainer := range containers {
+			name, container, err := getNameOfUncheckedNamedMap(pContainer, i)
+			if err != nil {
+				return err
+			}
+			if err := injectContainerDefault(pd, container); err != nil {
+				return fmt.Errorf("container '%s': %w", name, err)
+			}
+			containers[i] = container
+		}
+		if err := unstructured.SetNestedSlice(spec, containers, field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func injectContainerDefault(pd model.PodDefault, container map[string]interface{}) error {
+	if v := pd.ReadOnlyRootFilesystem; v != nil {
+		if err := setNestedFieldIfAbsent(container, *v, "securityContext", "readOnlyRootFilesystem"); err != nil {
+			return err
+		}
+	}
+	if v := pd.DropAllCapabilities; v != nil && *v {
+		if err := setNestedFieldIfAbsent(container, []interface{}{"ALL"}, "securityContext", "capabilities", "drop"); err != nil {
+			return err
+		}
+	}
+
+	for name, value := range pd.Resources.Requests {
+		// Kubernetes uses an explicit limit as request if there is none; a default
+		// request would replace it.
+		if _, exist, err := unstructured.NestedFieldNoCopy(container, "resources", "limits", name); err != nil {
+			return err
+		} else if exist {
+			continue
+		}
+		if err := setNestedFieldIfAbsent(container, value, "resources", "requests", name); err != nil {
+			return err
+		}
+	}
+	for name, value := range pd.Resources.Limits {
+		// A request which is greater than the limit would make the container invalid.
+		if request, exist := nestedQuantity(container, "resources", "requests", name); exist && request.Cmp(quantityOf(value)) > 0 {
+			continue
+		}
+		if err := setNestedFieldIfAbsent(container, value, "resources", "limits", name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func setNestedFieldIfAbsent(obj map[string]interface{}, value interface{}, fields ...string) error {
+	if _, exist, err := unstructured.NestedFieldNoCopy(obj, fields...); err != nil || exist {
+		return err
+	}
+	return unstructured.SetNestedField(obj, value, fields...)
+}
+
+// quantityOf returns the parsed quantity or zero if it could not be parsed.
+func quantityOf(plain string) resource.Quantity {
+	result, _ := resource.ParseQuantity(plain)
+	return result
+}
+
+// nestedQuantity returns the quantity at the given location which could be either a
+// string or a number.
+func nestedQuantity(obj map[string]interface{}, fields ...string) (resource.Quantity, bool) {
+	v, exist, err := unstructured.NestedFieldNoCopy(obj, fields...)
+	if err != nil || !exist || v == nil {
+		return resource.Quantity{}, false
+	}
+	return quantityOf(fmt.Sprint(v)), true
+}
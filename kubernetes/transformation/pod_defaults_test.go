@@ -0,0 +1,148 @@
+package transformation
+
+import (
+	"github.com/echocat/kubor/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"testing"
+)
+
+func newPodDefaultsTestProject(t *testing.T, plain string) *model.Project {
+	project := model.NewProject()
+	require.NoError(t, yaml.Unmarshal([]byte(plain), &project.PodDefaults))
+	require.NoError(t, project.PodDefaults.Validate())
+	return &project
+}
+
+func podSpecOf(t *testing.T, target *unstructured.Unstructured) map[string]interface{} {
+	result, _, err := NestedMap(target.Object, "spec", "template", "spec")
+	require.NoError(t, err)
+	return result
+}
+
+func Test_injectPodDefaults(t *testing.T) {
+	project := newPodDefaultsTestProject(t, `
+- runAsNonRoot: true
+  seccompProfile:
+    type: RuntimeDefault
+  automountServiceAccountToken: false
+  readOnlyRootFilesystem: true
+  dropAllCapabilities: true
+  resources:
+    requests:
+      cpu: 100m
+      memory: 128Mi
+    limits:
+      memory: 256Mi
+`)
+	target := newTestDeployment("app", newTestPodSpec(
+		map[string]interface{}{"name": "app"},
+		map[string]interface{}{
+			"name": "explicit",
+			"securityContext": map[string]interface{}{
+				"readOnlyRootFilesystem": false,
+				"capabilities":           map[string]interface{}{"drop": []interface{}{"NET_RAW"}},
+			},
+			"resources": map[string]interface{}{
+				"requests": map[string]interface{}{"memory": "512Mi"},
+				"limits":   map[string]interface{}{"cpu": int64(2)},
+			},
+		},
+	))
+	unstructured.SetNestedField(target.Object, false, "spec", "template", "spec", "securityContext", "runAsNonRoot")
+
+	err := injectPodDefaults(project, target, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"automountServiceAccountToken": false,
+		"securityContext": map[string]interface{}{
+			"runAsNonRoot":   false,
+			"seccompProfile": map[string]interface{}{"type": "RuntimeDefault"},
+		},
+		"containers": []interface{}{
+			map[string]interface{}{
+				"name": "app",
+				"securityContext": map[string]interface{}{
+					"readOnlyRootFilesystem": true,
+					"capabilities":           map[string]interface{}{"drop": []interface{}{"ALL"}},
+				},
+				"resources": map[string]interface{}{
+					"requests": map[string]interface{}{"cpu": "100m", "memory": "128Mi"},
+					"limits":   map[string]interface{}{"memory": "256Mi"},
+				},
+			},
+			map[string]interface{}{
+				"name": "explicit",
+				"securityContext": map[string]interface{}{
+					"readOnlyRootFilesystem": false,
+					"capabilities":           map[string]interface{}{"drop": []interface{}{"NET_RAW"}},
+				},
+				"resources": map[string]interface{}{
+					"requests": map[string]interface{}{"memory": "512Mi"},
+					"limits":   map[string]interface{}{"cpu": int64(2)},
+				},
+			},
+		},
+	}, podSpecOf(t, target))
+}
+
+func Test_injectPodDefaults_respects_selectors_and_order(t *testing.T) {
+	project := newPodDefaultsTestProject(t, `
+- selector:
+    name: "legacy-.*"
+  runAsNonRoot: false
+- runAsNonRoot: true
+  automountServiceAccountToken: false
+- automountServiceAccountToken: true
+`)
+	legacy := newTestDeployment("legacy-app", newTestPodSpec(map[string]interface{}{"name": "app"}))
+	other := newTestDeployment("app", newTestPodSpec(map[string]interface{}{"name": "app"}))
+
+	assert.NoError(t, injectPodDefaults(project, legacy, nil))
+	assert.NoError(t, injectPodDefaults(project, other, nil))
+
+	assert.Equal(t, map[string]interface{}{
+		"automountServiceAccountToken": false,
+		"securityContext":              map[string]interface{}{"runAsNonRoot": false},
+		"containers":                   []interface{}{map[string]interface{}{"name": "app"}},
+	}, podSpecOf(t, legacy))
+	assert.Equal(t, map[string]interface{}{
+		"automountServiceAccountToken": false,
+		"securityContext":              map[string]interface{}{"runAsNonRoot": true},
+		"containers":                   []interface{}{map[string]interface{}{"name": "app"}},
+	}, podSpecOf(t, other))
+}
+
+func Test_injectPodDefaults_ignores_objects_without_pod_spec(t *testing.T) {
+	project := newPodDefaultsTestProject(t, `
+- runAsNonRoot: true
+`)
+	target := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Service",
+		"spec":       map[string]interface{}{"type": "ClusterIP"},
+	}}
+	expected := target.DeepCopy()
+
+	assert.NoError(t, injectPodDefaults(project, target, nil))
+	assert.Equal(t, expected, target)
+}
+
+func Test_injectPodDefaults_could_be_disabled_per_object(t *testing.T) {
+	project := newPodDefaultsTestProject(t, `
+- runAsNonRoot: true
+`)
+	target := newTestDeployment("app", newTestPodSpec(map[string]interface{}{"name": "app"}))
+	target.SetAnnotations(map[string]string{
+		model.AnnotationTransformationPrefix + string(podDefaultsTransformationName): "disabled",
+	})
+
+	assert.NoError(t, Default.TransformForCreate(project, target))
+
+	_, exist, err := unstructured.NestedFieldNoCopy(target.Object, "spec", "template", "spec", "securityContext")
+	assert.NoError(t, err)
+	assert.False(t, exist)
+}
@@ -0,0 +1,65 @@
+package transformation
+
+import (
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"testing"
+)
+
+func Test_PodSpecPathOf(t *testing.T) {
+	cases := []struct {
+		apiVersion       string
+		kind             string
+		expectedTemplate []string
+		expectedSpec     []string
+	}{
+		{"v1", "Pod", nil, []string{"spec"}},
+		{"v1", "ReplicationController", []string{"spec", "template"}, []string{"spec", "template", "spec"}},
+		{"apps/v1", "Deployment", []string{"spec", "template"}, []string{"spec", "template", "spec"}},
+		{"extensions/v1beta1", "DaemonSet", []string{"spec", "template"}, []string{"spec", "template", "spec"}},
+		{"batch/v1", "CronJob", []string{"spec", "jobTemplate", "spec", "template"}, []string{"spec", "jobTemplate", "spec", "template", "spec"}},
+		{"batch/v1beta1", "CronJob", []string{"spec", "jobTemplate", "spec", "template"}, []string{"spec", "jobTemplate", "spec", "template", "spec"}},
+		{"v1", "Service", nil, nil},
+		{"example.org/v1", "Deployment", nil, nil},
+	}
+	for _, c := range cases {
+		t.Run(c.apiVersion+"/"+c.kind, func(t *testing.T) {
+			target := &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": c.apiVersion,
+				"kind":       c.kind,
+			}}
+
+			template, templateOk := PodTemplatePathOf(target)
+			spec, specOk := PodSpecPathOf(target)
+
+			assert.Equal(t, c.expectedTemplate, template)
+			assert.Equal(t, c.expectedTemplate != nil, templateOk)
+			assert.Equal(t, c.expectedSpec, spec)
+			assert.Equal(t, c.expectedSpec != nil, specOk)
+		})
+	}
+}
+
+// newTestDeployment returns an apps/v1 Deployment with the given name whose pod
+// template has the given spec. Further fields could be set on the returned object.
+func newTestDeployment(name string, podSpec map[string]interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   map[string]interface{}{"name": name},
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"spec": podSpec,
+			},
+		},
+	}}
+}
+
+// newTestPodSpec returns a pod spec with the given containers.
+func newTestPodSpec(containers ...map[string]interface{}) map[string]interface{} {
+	result := make([]interface{}, len(containers))
+	for i, container := range containers {
+		result[i] = container
+	}
+	return map[string]interface{}{"containers": result}
+}
@@ -35,3 +35,18 @@ func (instance transformation) DefaultEnabled(*unstructured.Unstructured) bool {
 func (instance transformation) GetPriority() int32 {
 	return 0
 }
+
+// Contains returns true if there is a transformation with the given name.
+func (instance Transformations) Contains(name model.TransformationName) bool {
+	for _, candidate := range instance.Updates {
+		if candidate.GetName() == name {
+			return true
+		}
+	}
+	for _, candidate := range instance.Creates {
+		if candidate.GetName() == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,183 @@
+package transformation
+
+import (
+	"github.com/echocat/kubor/model"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	restartedAtAnnotation = "kubectl.kubernetes.io/restartedAt"
+)
+
+var (
+	horizontalPodAutoscalerGroupKind = schema.GroupKind{Group: "autoscaling", Kind: "HorizontalPodAutoscaler"}
+	verticalPodAutoscalerGroupKind   = schema.GroupKind{Group: "autoscaling.k8s.io", Kind: "VerticalPodAutoscaler"}
+
+	// scalableGroupKinds are the kinds which have spec.replicas.
+	scalableGroupKinds = map[schema.GroupKind]bool{
+		{Group: "apps", Kind: "Deployment"}:        true,
+		{Group: "extensions", Kind: "Deployment"}:  true,
+		{Group: "apps", Kind: "StatefulSet"}:       true,
+		{Group: "apps", Kind: "ReplicaSet"}:        true,
+		{Group: "extensions", Kind: "ReplicaSet"}:  true,
+		{Group: "", Kind: "ReplicationController"}: true,
+	}
+)
+
+func init() {
+	Default.MustRegisterUpdateFunc("workload-preserve-replicas", preserveWorkloadReplicas)
+	Default.MustRegisterUpdateFunc("workload-preserve-vpa-resources", preserveWorkloadVpaResources)
+	Default.MustRegisterUpdateFunc("workload-preserve-restarted-at", preserveWorkloadRestartedAt)
+}
+
+// preserveWorkloadReplicas keeps spec.replicas of the existing object if the target
+// does not define it (which would reset it to 1) or if a HorizontalPodAutoscaler which
+// was rendered together with the target scales it.
+func preserveWorkloadReplicas(project *model.Project, existing unstructured.Unstructured, target *unstructured.Unstructured, _ *string) error {
+	if !groupVersionKindMatches(&existing, target) {
+		return nil
+	}
+	if !scalableGroupKinds[target.GroupVersionKind().GroupKind()] {
+		return nil
+	}
+
+	replicas, exist, err := unstructured.NestedInt64(existing.Object, "spec", "replicas")
+	if err != nil {
+		return err
+	}
+	if !exist {
+		return nil
+	}
+
+	if _, exist, err := unstructured.NestedInt64(target.Object, "spec", "replicas"); err != nil {
+		return err
+	} else if exist && findRenderedScaler(project, horizontalPodAutoscalerGroupKind, "scaleTargetRef", target) == nil {
+		return nil
+	}
+
+	return unstructured.SetNestedField(target.Object, replicas, "spec", "replicas")
+}
+
+// preserveWorkloadVpaResources keeps the resources of all containers of the pod template
+// of the existing object if a VerticalPodAutoscaler which was rendered together with
+// the target updates its pods. The VPA overrides the resources of the pods anyway;
+// changing them inside of the template would only cause needless rollouts.
+func preserveWorkloadVpaResources(project *model.Project, existing unstructured.Unstructured, target *unstructured.Unstructured, _ *string) error {
+	if !groupVersionKindMatches(&existing, target) {
+		return nil
+	}
+	path, ok := PodTemplatePathOf(target)
+	if !ok {
+		return nil
+	}
+	vpa := findRenderedScaler(project, verticalPodAutoscalerGroupKind, "targetRef", target)
+	if vpa == nil {
+		return nil
+	}
+	if mode, _, _ := unstructured.NestedString(vpa.Object, "spec", "updatePolicy", "updateMode"); mode == "Off" || mode == "Initial" {
+		return nil
+	}
+
+	fields := append(append([]string{}, path...), "spec", "containers")
+	existingContainers, _, err := unstructured.NestedSlice(existing.Object, fields...)
+	if err != nil {
+		return err
+	}
+	targetContainers, exist, err := unstructured.NestedSlice(target.Object, fields...)
+	if err != nil || !exist {
+		return err
+	}
+
+	for i, pExistingContainer := range existingContainers {
+		name, existingContainer, err := getNameOfUncheckedNamedMap(pExistingContainer, i)
+		if err != nil {
+			return err
+		}
+		targetContainer, targetIndex, err := findByNameOfUncheckedNamedMap(targetContainers, name)
+		if err != nil {
+			return err
+		}
+		if targetIndex < 0 {
+			continue
+		}
+		if resources, exist := existingContainer["resources"]; exist {
+			targetContainer["resources"] = resources
+		} else {
+			delete(targetContainer, "resources")
+		}
+		targetContainers[targetIndex] = targetContainer
+	}
+
+	return unstructured.SetNestedSlice(target.Object, targetContainers, fields...)
+}
+
+// preserveWorkloadRestartedAt keeps the kubectl.kubernetes.io/restartedAt annotation
+// of the pod template which is set by "kubectl rollout restart"; otherwise every
+// deployment would roll out the pods again.
+func preserveWorkloadRestartedAt(_ *model.Project, existing unstructured.Unstructured, target *unstructured.Unstructured, _ *string) error {
+	if !groupVersionKindMatches(&existing, target) {
+		return nil
+	}
+	path, ok := PodTemplatePathOf(target)
+	if !ok {
+		return nil
+	}
+	fields := append(append([]string{}, path...), "metadata", "annotations")
+
+	existingAnnotations, _, err := NestedStringMap(existing.Object, fields...)
+	if err != nil {
+		return err
+	}
+	restartedAt, exist := existingAnnotations[restartedAtAnnotation]
+	if !exist {
+		return nil
+	}
+
+	if _, exist, err := NestedMap(target.Object, path...); err != nil || !exist {
+		return err
+	}
+	annotations, _, err := NestedStringMap(target.Object, fields...)
+	if err != nil {
+		return err
+	}
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	if _, exist := annotations[restartedAtAnnotation]; exist {
+		return nil
+	}
+	annotations[restartedAtAnnotation] = restartedAt
+
+	return unstructured.SetNestedStringMap(target.Object, annotations, fields...)
+}
+
+// findRenderedScaler returns the first object of the given kind which was rendered
+// together with the target and which references the target with the given field of
+// its spec.
+//
+// Only the objects of the current project are considered; transformations have no
+// access to the cluster. A HorizontalPodAutoscaler or VerticalPodAutoscaler which
+// was created outside of the project (like by another project or by hand) is not
+// found. In this case the target has to omit spec.replicas to keep the replicas of
+// the existing object and the resources of its containers are always applied.
+func findRenderedScaler(project *model.Project, groupKind schema.GroupKind, referenceField string, target *unstructured.Unstructured) *unstructured.Unstructured {
+	targetGroupKind := target.GroupVersionKind().GroupKind()
+	for key, candidate := range project.Rendered {
+		if key.GroupKind != groupKind || key.Namespace != target.GetNamespace() {
+			continue
+		}
+		reference, _, _ := NestedMap(candidate.Object, "spec", referenceField)
+		apiVersion, _, _ := unstructured.NestedString(reference, "apiVersion")
+		kind, _, _ := unstructured.NestedString(reference, "kind")
+		name, _, _ := unstructured.NestedString(reference, "name")
+		gv, err := schema.ParseGroupVersion(apiVersion)
+		if err != nil {
+			continue
+		}
+		if gv.Group == targetGroupKind.Group && kind == targetGroupKind.Kind && name == target.GetName() {
+			return candidate
+		}
+	}
+	return nil
+}
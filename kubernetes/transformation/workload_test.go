@@ -0,0 +1,102 @@
+package transformation
+
+import (
+	"github.com/echocat/kubor/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"testing"
+)
+
+func newWorkloadTestScaler(apiVersion, kind, referenceField string, spec map[string]interface{}) *unstructured.Unstructured {
+	spec[referenceField] = map[string]interface{}{"apiVersion": "apps/v1", "kind": "Deployment", "name": "app"}
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": apiVersion,
+		"kind":       kind,
+		"metadata":   map[string]interface{}{"name": "app"},
+		"spec":       spec,
+	}}
+}
+
+func replicasOf(t *testing.T, target *unstructured.Unstructured) int64 {
+	result, _, err := unstructured.NestedInt64(target.Object, "spec", "replicas")
+	require.NoError(t, err)
+	return result
+}
+
+func Test_preserveWorkloadReplicas(t *testing.T) {
+	hpa := newWorkloadTestScaler("autoscaling/v2", "HorizontalPodAutoscaler", "scaleTargetRef", map[string]interface{}{})
+	cases := []struct {
+		name     string
+		project  *model.Project
+		target   interface{}
+		expected int64
+	}{
+		{"target defines replicas", newConfigChecksumTestProject(), int64(2), 2},
+		{"target omits replicas", newConfigChecksumTestProject(), nil, 12},
+		{"hpa targets object", newConfigChecksumTestProject(hpa), int64(2), 12},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			existing := newTestDeployment("app", newTestPodSpec(map[string]interface{}{"name": "app"}))
+			require.NoError(t, unstructured.SetNestedField(existing.Object, int64(12), "spec", "replicas"))
+			target := newTestDeployment("app", newTestPodSpec(map[string]interface{}{"name": "app"}))
+			if c.target != nil {
+				require.NoError(t, unstructured.SetNestedField(target.Object, c.target, "spec", "replicas"))
+			}
+
+			err := preserveWorkloadReplicas(c.project, *existing, target, nil)
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.expected, replicasOf(t, target))
+		})
+	}
+}
+
+func Test_preserveWorkloadVpaResources(t *testing.T) {
+	existingResources := map[string]interface{}{"requests": map[string]interface{}{"cpu": "750m"}}
+	targetResources := map[string]interface{}{"requests": map[string]interface{}{"cpu": "100m"}}
+	cases := []struct {
+		name     string
+		mode     string
+		expected interface{}
+	}{
+		{"auto", "Auto", existingResources},
+		{"off", "Off", targetResources},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			vpa := newWorkloadTestScaler("autoscaling.k8s.io/v1", "VerticalPodAutoscaler", "targetRef", map[string]interface{}{
+				"updatePolicy": map[string]interface{}{"updateMode": c.mode},
+			})
+			existing := newTestDeployment("app", newTestPodSpec(map[string]interface{}{"name": "app", "resources": existingResources}))
+			target := newTestDeployment("app", newTestPodSpec(map[string]interface{}{"name": "app", "resources": targetResources}))
+
+			err := preserveWorkloadVpaResources(newConfigChecksumTestProject(vpa), *existing, target, nil)
+
+			assert.NoError(t, err)
+			containers, _, _ := unstructured.NestedSlice(target.Object, "spec", "template", "spec", "containers")
+			assert.Equal(t, c.expected, containers[0].(map[string]interface{})["resources"])
+		})
+	}
+}
+
+func Test_preserveWorkloadRestartedAt(t *testing.T) {
+	existing := newTestDeployment("app", newTestPodSpec(map[string]interface{}{"name": "app"}))
+	require.NoError(t, unstructured.SetNestedStringMap(existing.Object, map[string]string{
+		restartedAtAnnotation: "2026-10-01T12:00:00Z",
+	}, "spec", "template", "metadata", "annotations"))
+	target := newTestDeployment("app", newTestPodSpec(map[string]interface{}{"name": "app"}))
+	require.NoError(t, unstructured.SetNestedStringMap(target.Object, map[string]string{
+		"foo": "bar",
+	}, "spec", "template", "metadata", "annotations"))
+
+	err := preserveWorkloadRestartedAt(nil, *existing, target, nil)
+
+	assert.NoError(t, err)
+	annotations, _, _ := NestedStringMap(target.Object, "spec", "template", "metadata", "annotations")
+	assert.Equal(t, map[string]string{
+		"foo":                 "bar",
+		restartedAtAnnotation: "2026-10-01T12:00:00Z",
+	}, annotations)
+}
@@ -0,0 +1,245 @@
+package lint
+
+import (
+	"fmt"
+	"github.com/echocat/kubor/kubernetes"
+	"github.com/echocat/kubor/kubernetes/openapi"
+	"github.com/echocat/kubor/model"
+	"github.com/echocat/kubor/template"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sort"
+	"strings"
+)
+
+const (
+	RuleProject          = RuleName("project")
+	RuleTemplate         = RuleName("template")
+	RuleAnnotations      = RuleName("annotations")
+	RuleClaim            = RuleName("claim")
+	RuleResourceRequests = RuleName("resourceRequests")
+	RuleLatestTag        = RuleName("latestTag")
+	RuleProbes           = RuleName("probes")
+	RuleSchema           = RuleName("schema")
+)
+
+// RuleName identifies a rule.
+type RuleName string
+
+// Rule describes a check of the linter.
+type Rule struct {
+	Name            RuleName
+	Description     string
+	DefaultSeverity model.LintSeverity
+}
+
+// Rules are all rules the linter knows about.
+var Rules = []Rule{{
+	Name:            RuleProject,
+	Description:     "The project file has to be valid: no unknown keys, legal stages, predicates, ...",
+	DefaultSeverity: model.LintSeverityError,
+}, {
+	Name:            RuleTemplate,
+	Description:     "All templates have to be rendered and parsed as Kubernetes objects without errors.",
+	DefaultSeverity: model.LintSeverityError,
+}, {
+	Name:            RuleAnnotations,
+	Description:     "All kubor annotations (stage, apply-on, dry-run-on, wait-until, cleanup-on and transformations) of objects have to be valid.",
+	DefaultSeverity: model.LintSeverityError,
+}, {
+	Name:            RuleClaim,
+	Description:     "All objects have to match the claim of the project.",
+	DefaultSeverity: model.LintSeverityError,
+}, {
+	Name:            RuleResourceRequests,
+	Description:     "Every container should request cpu and memory.",
+	DefaultSeverity: model.LintSeverityWarning,
+}, {
+	Name:            RuleLatestTag,
+	Description:     "Images should neither use the latest tag nor no tag at all.",
+	DefaultSeverity: model.LintSeverityWarning,
+}, {
+	Name:            RuleProbes,
+	Description:     "Every container of long running workloads should have a readiness and a liveness probe.",
+	DefaultSeverity: model.LintSeverityWarning,
+}, {
+	Name:            RuleSchema,
+	Description:     "All objects have to match the OpenAPI schema of their kind. Objects of kinds without known schema are not validated.",
+	DefaultSeverity: model.LintSeverityError,
+}}
+
+// RuleByName returns the rule with the given name.
+func RuleByName(name RuleName) (Rule, bool) {
+	for _, candidate := range Rules {
+		if candidate.Name == name {
+			return candidate, true
+		}
+	}
+	return Rule{}, false
+}
+
+// Finding is one problem found by the linter.
+type Finding struct {
+	Rule     RuleName           `json:"rule"`
+	Severity model.LintSeverity `json:"severity"`
+	Message  string             `json:"message"`
+	// Source is the file (or the file with the index of the document inside it
+	// like "deployment.yml#0") the finding belongs to.
+	Source string `json:"source,omitempty"`
+	// Line is the line inside of Source (if known).
+	Line int `json:"line,omitempty"`
+	// Object is the reference of the object the finding belongs to (if any).
+	Object string `json:"object,omitempty"`
+}
+
+func (instance Finding) String() string {
+	result := fmt.Sprintf("%s[%s]", instance.Severity, instance.Rule)
+	if location := instance.Location(); location != "" {
+		result += " " + location
+	}
+	if instance.Object != "" {
+		result += " (" + instance.Object + ")"
+	}
+	return result + ": " + instance.Message
+}
+
+// Location returns the Source together with the Line (if any).
+func (instance Finding) Location() string {
+	if instance.Line > 0 {
+		return fmt.Sprintf("%s:%d", instance.Source, instance.Line)
+	}
+	return instance.Source
+}
+
+// File returns the Source without the index of the document.
+func (instance Finding) File() string {
+	file, _, _ := strings.Cut(instance.Source, "#")
+	return file
+}
+
+type Findings []Finding
+
+// CountBySeverity returns the amount of findings of the given severity.
+func (instance Findings) CountBySeverity(severity model.LintSeverity) (result int) {
+	for _, candidate := range instance {
+		if candidate.Severity == severity {
+			result++
+		}
+	}
+	return
+}
+
+// AtLeast returns all findings which have at least the given severity.
+func (instance Findings) AtLeast(severity model.LintSeverity) (result Findings) {
+	for _, candidate := range instance {
+		if candidate.Severity.IsAtLeast(severity) {
+			result = append(result, candidate)
+		}
+	}
+	return
+}
+
+// Linter checks a project, its templates and all rendered objects without touching
+// any cluster.
+type Linter struct {
+	// Severities overrides the severities of the rules (see Rules); it takes precedence
+	// over Lint.Rules of the project.
+	Severities map[RuleName]model.LintSeverity
+	// Validator validates all objects against the schema of their kind (see
+	// RuleSchema). If nil no object is validated.
+	Validator *openapi.Validator
+
+	findings Findings
+}
+
+// LintSource checks the given project file strictly. It returns true if there were
+// problems found.
+func (instance *Linter) LintSource(project *model.Project, source string) bool {
+	errs := model.CheckProjectSource(source)
+	for _, err := range errs {
+		instance.report(project, Finding{Rule: RuleProject, Source: source, Message: err.Error()})
+	}
+	return len(errs) > 0
+}
+
+// LintProjectError reports an error which occurred while creating the project.
+func (instance *Linter) LintProjectError(source string, err error) {
+	instance.reportError(nil, RuleProject, source, err)
+}
+
+// Lint renders all templates of the given project and checks every resulting object.
+func (instance *Linter) Lint(project *model.Project) error {
+	oh, err := model.NewObjectHandler(func(source string, object runtime.Object, unstructured *unstructured.Unstructured) error {
+		instance.lintObject(project, source, object, unstructured)
+		return nil
+	}, project)
+	if err != nil {
+		return err
+	}
+	oh.ContinueOnError = true
+	oh.Prepare = kubernetes.NewNamespaceDefaulter(project, nil).Default
+
+	cp, err := project.RenderedTemplatesProvider()
+	if err != nil {
+		instance.reportError(project, RuleTemplate, "", err)
+		return nil
+	}
+	if err := oh.Handle(cp); err != nil {
+		instance.reportError(project, RuleTemplate, "", err)
+	}
+	return nil
+}
+
+// Findings returns all findings sorted by their source.
+func (instance *Linter) Findings() Findings {
+	result := make(Findings, len(instance.findings))
+	copy(result, instance.findings)
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Source != result[j].Source {
+			return result[i].Source < result[j].Source
+		}
+		return result[i].Line < result[j].Line
+	})
+	return result
+}
+
+func (instance *Linter) severityOf(project *model.Project, rule RuleName) model.LintSeverity {
+	if v, ok := instance.Severities[rule]; ok {
+		return v
+	}
+	if project != nil {
+		if v, ok := project.Lint.Rules[string(rule)]; ok {
+			return v
+		}
+	}
+	if r, ok := RuleByName(rule); ok {
+		return r.DefaultSeverity
+	}
+	return model.LintSeverityError
+}
+
+func (instance *Linter) report(project *model.Project, finding Finding) {
+	finding.Severity = instance.severityOf(project, finding.Rule)
+	if finding.Severity == model.LintSeverityOff {
+		return
+	}
+	instance.findings = append(instance.findings, finding)
+}
+
+// reportError reports every error contained in err (see errors.Join) as a single
+// finding. Template errors are reported with their location.
+func (instance *Linter) reportError(project *model.Project, rule RuleName, source string, err error) {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, candidate := range joined.Unwrap() {
+			instance.reportError(project, rule, source, candidate)
+		}
+		return
+	}
+	if tes := template.CollectTemplateErrors(err); len(tes) > 0 {
+		for _, te := range tes {
+			instance.report(project, Finding{Rule: rule, Source: te.Source, Line: te.Line, Message: te.Message})
+		}
+		return
+	}
+	instance.report(project, Finding{Rule: rule, Source: source, Message: err.Error()})
+}
@@ -0,0 +1,95 @@
+package lint
+
+import (
+	"errors"
+	"fmt"
+	"github.com/echocat/kubor/kubernetes"
+	"github.com/echocat/kubor/kubernetes/openapi"
+	"github.com/echocat/kubor/kubernetes/transformation"
+	"github.com/echocat/kubor/model"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sort"
+	"strings"
+)
+
+func (instance *Linter) lintObject(project *model.Project, source string, object runtime.Object, target *unstructured.Unstructured) {
+	reference, err := kubernetes.GetObjectReference(object, project.Scheme)
+	if err != nil {
+		instance.report(project, Finding{Rule: RuleTemplate, Source: source, Message: err.Error()})
+		return
+	}
+	report := func(rule RuleName, format string, args ...interface{}) {
+		instance.report(project, Finding{
+			Rule:    rule,
+			Source:  source,
+			Object:  reference.String(),
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+
+	for _, err := range annotationErrorsOf(project, target) {
+		report(RuleAnnotations, "%v", err)
+	}
+	if err := project.Claim.Validate(reference); err != nil {
+		report(RuleClaim, "%v", err)
+	}
+	for _, smell := range smellsOf(target) {
+		report(smell.rule, "%s", smell.message)
+	}
+	if instance.Validator != nil {
+		var violations openapi.Violations
+		if err := instance.Validator.Validate(target); errors.As(err, &violations) {
+			for _, violation := range violations.Violations {
+				report(RuleSchema, "%v", violation)
+			}
+		} else if err != nil && !errors.Is(err, openapi.ErrNoSchema) {
+			report(RuleSchema, "%v", err)
+		}
+	}
+}
+
+func annotationErrorsOf(project *model.Project, target *unstructured.Unstructured) (result []error) {
+	as := project.Annotations
+	if stage, err := as.GetStageFor(target); err != nil {
+		result = append(result, fmt.Errorf("%s: %w", as.Stage.Name, err))
+	} else if !project.Stages.Contains(stage) {
+		result = append(result, fmt.Errorf("%s: unknown stage %v; project defines: %v", as.Stage.Name, stage, project.Stages))
+	}
+	if _, err := as.GetApplyOnFor(target); err != nil {
+		result = append(result, fmt.Errorf("%s: %w", as.ApplyOn.Name, err))
+	}
+	if _, err := as.GetDryRunOnFor(target, model.DryRunOnServerIfPossible); err != nil {
+		result = append(result, fmt.Errorf("%s: %w", as.DryRunOn.Name, err))
+	}
+	if _, err := as.GetWaitUntilFor(target); err != nil {
+		result = append(result, fmt.Errorf("%s: %w", as.WaitUntil.Name, err))
+	}
+	if _, err := as.GetCleanupOn(target); err != nil {
+		result = append(result, fmt.Errorf("%s: %w", as.CleanupOn.Name, err))
+	}
+
+	transformations, err := transformation.For(project)
+	if err != nil {
+		return append(result, err)
+	}
+	prefix := string(as.Transformations.Name)
+	var keys []string
+	for key := range target.GetAnnotations() {
+		if prefix != "" && strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		var name model.TransformationName
+		if err := name.Set(strings.TrimPrefix(key, prefix)); err != nil {
+			result = append(result, fmt.Errorf("%s: %w", key, err))
+		} else if !transformations.Contains(name) {
+			result = append(result, fmt.Errorf("%s: unknown transformation %v", key, name))
+		} else if _, err := project.GetTransformation(target, name); err != nil {
+			result = append(result, fmt.Errorf("%s: %w", key, err))
+		}
+	}
+	return
+}
@@ -0,0 +1,163 @@
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/echocat/kubor/model"
+	"io"
+	"path/filepath"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+// WriteText writes the given findings human readable (one per line) followed by a summary.
+func WriteText(w io.Writer, findings Findings) error {
+	for _, finding := range findings {
+		if _, err := fmt.Fprintln(w, finding); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "%d findings (%d errors, %d warnings, %d infos)\n",
+		len(findings),
+		findings.CountBySeverity(model.LintSeverityError),
+		findings.CountBySeverity(model.LintSeverityWarning),
+		findings.CountBySeverity(model.LintSeverityInfo),
+	)
+	return err
+}
+
+// WriteJson writes the given findings as JSON.
+func WriteJson(w io.Writer, findings Findings) error {
+	if findings == nil {
+		findings = Findings{}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(struct {
+		Findings Findings `json:"findings"`
+	}{findings})
+}
+
+// WriteSarif writes the given findings as SARIF 2.1.0 log which could be consumed by
+// code scanning tools.
+func WriteSarif(w io.Writer, findings Findings, version string) error {
+	rules := make([]sarifRule, len(Rules))
+	for i, rule := range Rules {
+		rules[i] = sarifRule{
+			Id:               string(rule.Name),
+			ShortDescription: sarifMessage{rule.Description},
+			DefaultConfiguration: sarifConfiguration{
+				Level: sarifLevelOf(rule.DefaultSeverity),
+			},
+		}
+	}
+	results := make([]sarifResult, len(findings))
+	for i, finding := range findings {
+		message := finding.Message
+		if finding.Object != "" {
+			message = finding.Object + ": " + message
+		}
+		result := sarifResult{
+			RuleId:  string(finding.Rule),
+			Level:   sarifLevelOf(finding.Severity),
+			Message: sarifMessage{message},
+		}
+		if file := finding.File(); file != "" {
+			location := sarifLocation{}
+			location.PhysicalLocation.ArtifactLocation.Uri = filepath.ToSlash(file)
+			if finding.Line > 0 {
+				location.PhysicalLocation.Region = &sarifRegion{StartLine: finding.Line}
+			}
+			result.Locations = []sarifLocation{location}
+		}
+		results[i] = result
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(sarifLog{
+		Version: sarifVersion,
+		Schema:  sarifSchema,
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           "kubor",
+				Version:        version,
+				InformationUri: "https://github.com/echocat/kubor",
+				Rules:          rules,
+			}},
+			Results: results,
+		}},
+	})
+}
+
+func sarifLevelOf(severity model.LintSeverity) string {
+	switch severity {
+	case model.LintSeverityError:
+		return "error"
+	case model.LintSeverityWarning:
+		return "warning"
+	case model.LintSeverityOff:
+		return "none"
+	default:
+		return "note"
+	}
+}
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationUri string      `json:"informationUri,omitempty"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	Id                   string             `json:"id"`
+	ShortDescription     sarifMessage       `json:"shortDescription"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleId    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation struct {
+		ArtifactLocation struct {
+			Uri string `json:"uri"`
+		} `json:"artifactLocation"`
+		Region *sarifRegion `json:"region,omitempty"`
+	} `json:"physicalLocation"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
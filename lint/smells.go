@@ -0,0 +1,83 @@
+package lint
+
+import (
+	"fmt"
+	"github.com/echocat/kubor/kubernetes/transformation"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"strings"
+)
+
+type smell struct {
+	rule    RuleName
+	message string
+}
+
+// finiteWorkloadKinds are kinds which are expected to terminate; probes are not
+// required for them.
+var finiteWorkloadKinds = map[string]bool{
+	"Job":     true,
+	"CronJob": true,
+}
+
+func smellsOf(target *unstructured.Unstructured) (result []smell) {
+	path, ok := transformation.PodSpecPathOf(target)
+	if !ok {
+		return nil
+	}
+	spec, ok, _ := unstructured.NestedMap(target.Object, path...)
+	if !ok {
+		return nil
+	}
+	add := func(rule RuleName, format string, args ...interface{}) {
+		result = append(result, smell{rule, fmt.Sprintf(format, args...)})
+	}
+
+	for _, field := range []string{"initContainers", "containers"} {
+		containers, _, _ := unstructured.NestedSlice(spec, field)
+		for _, plain := range containers {
+			container, ok := plain.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			name, _, _ := unstructured.NestedString(container, "name")
+
+			image, _, _ := unstructured.NestedString(container, "image")
+			if tag, ok := imageTagOf(image); !ok {
+				add(RuleLatestTag, "container '%s' uses image '%s' without tag", name, image)
+			} else if tag == "latest" {
+				add(RuleLatestTag, "container '%s' uses image '%s' with tag latest", name, image)
+			}
+
+			for _, resource := range []string{"cpu", "memory"} {
+				if _, ok, _ := unstructured.NestedFieldNoCopy(container, "resources", "requests", resource); !ok {
+					add(RuleResourceRequests, "container '%s' does not request %s", name, resource)
+				}
+			}
+
+			if field == "containers" && !finiteWorkloadKinds[target.GetKind()] {
+				for _, probe := range []string{"readinessProbe", "livenessProbe"} {
+					if _, ok, _ := unstructured.NestedFieldNoCopy(container, probe); !ok {
+						add(RuleProbes, "container '%s' has no %s", name, probe)
+					}
+				}
+			}
+		}
+	}
+	return
+}
+
+// imageTagOf returns the tag of the given image. If the image is pinned by a digest
+// the digest is returned as tag. If there is no tag at all false is returned.
+func imageTagOf(image string) (string, bool) {
+	if _, digest, ok := strings.Cut(image, "@"); ok {
+		return digest, true
+	}
+	name := image
+	if i := strings.LastIndexByte(image, '/'); i >= 0 {
+		name = image[i+1:]
+	}
+	if _, tag, ok := strings.Cut(name, ":"); ok && tag != "" {
+		return tag, true
+	}
+	return "", false
+}
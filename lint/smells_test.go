@@ -0,0 +1,107 @@
+package lint
+
+import (
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"testing"
+)
+
+func Test_smellsOf_ignores_kinds_without_pod_spec(t *testing.T) {
+	target := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+		},
+	}
+
+	assert.Empty(t, smellsOf(target))
+}
+
+func Test_smellsOf_reports_all_smells_of_deployment(t *testing.T) {
+	target := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"spec": map[string]interface{}{
+				"template": map[string]interface{}{
+					"spec": map[string]interface{}{
+						"containers": []interface{}{
+							map[string]interface{}{
+								"name":  "app",
+								"image": "registry:5000/app",
+								"resources": map[string]interface{}{
+									"requests": map[string]interface{}{
+										"cpu": "100m",
+									},
+								},
+								"readinessProbe": map[string]interface{}{},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	assert.Equal(t, []smell{
+		{RuleLatestTag, "container 'app' uses image 'registry:5000/app' without tag"},
+		{RuleResourceRequests, "container 'app' does not request memory"},
+		{RuleProbes, "container 'app' has no livenessProbe"},
+	}, smellsOf(target))
+}
+
+func Test_smellsOf_does_not_require_probes_for_jobs(t *testing.T) {
+	target := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "batch/v1",
+			"kind":       "CronJob",
+			"spec": map[string]interface{}{
+				"jobTemplate": map[string]interface{}{
+					"spec": map[string]interface{}{
+						"template": map[string]interface{}{
+							"spec": map[string]interface{}{
+								"containers": []interface{}{
+									map[string]interface{}{
+										"name":  "job",
+										"image": "job:latest",
+										"resources": map[string]interface{}{
+											"requests": map[string]interface{}{
+												"cpu":    "100m",
+												"memory": "64Mi",
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	assert.Equal(t, []smell{
+		{RuleLatestTag, "container 'job' uses image 'job:latest' with tag latest"},
+	}, smellsOf(target))
+}
+
+func Test_imageTagOf(t *testing.T) {
+	cases := []struct {
+		image    string
+		expected string
+		ok       bool
+	}{
+		{"app", "", false},
+		{"app:1.0", "1.0", true},
+		{"registry:5000/app", "", false},
+		{"registry:5000/app:latest", "latest", true},
+		{"app@sha256:abc", "sha256:abc", true},
+	}
+	for _, c := range cases {
+		t.Run(c.image, func(t *testing.T) {
+			actual, ok := imageTagOf(c.image)
+			assert.Equal(t, c.expected, actual)
+			assert.Equal(t, c.ok, ok)
+		})
+	}
+}
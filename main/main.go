@@ -7,6 +7,7 @@ import (
 	"github.com/echocat/kubor/common"
 	"github.com/echocat/kubor/kubernetes"
 	"github.com/echocat/kubor/model"
+	"github.com/echocat/kubor/template"
 	"github.com/echocat/slf4g/native"
 	"github.com/echocat/slf4g/native/facade/value"
 	"os"
@@ -74,5 +75,10 @@ func main() {
 	app.Command("version", "Print the actual version and other useful information.").
 		Action(version)
 
-	kingpin.MustParse(app.Parse(os.Args[1:]))
+	selected, err := app.Parse(os.Args[1:])
+	if len(template.CollectTemplateErrors(err)) > 0 {
+		command.ReportError(os.Stderr, err)
+		os.Exit(1)
+	}
+	kingpin.MustParse(selected, err)
 }
@@ -0,0 +1,10 @@
+package model
+
+// Ci configures the ci-discovery transformation which annotates workloads and their
+// pod templates with information of the CI system kubor runs in.
+type Ci struct {
+	// Annotations maps annotation keys to the names of the environment variables
+	// their values are taken from (like "example.org/build: BUILD_TAG"). Annotations
+	// whose variables are empty or absent are not set.
+	Annotations map[string]string `yaml:"annotations,omitempty" json:"annotations,omitempty"`
+}
@@ -5,10 +5,28 @@ import (
 	"github.com/echocat/kubor/template/functions"
 )
 
+const (
+	// AnnotationNamespaceCreatedBy marks namespaces which were created by kubor (see
+	// Claim.ManageNamespaces). Its value is the project which created it.
+	AnnotationNamespaceCreatedBy = "kubor.echocat.org/created-by"
+)
+
 type Claim struct {
 	GroupVersionKinds GroupVersionKinds `yaml:"gvks,omitempty" json:"gvks,omitempty"`
 	SourceNamespaces  []string          `yaml:"namespaces,omitempty" json:"namespaces,omitempty"`
 
+	// ManageNamespaces lets kubor create all claimed namespaces which are missing
+	// before the first stage is applied. kubor delete removes them again if they were
+	// created by kubor for this project and are empty. Namespaces created for other
+	// projects are never touched. Nothing is created by kubor apply --dryRun=only,
+	// therefore its dry run on the server fails inside of missing namespaces.
+	ManageNamespaces bool `yaml:"manageNamespaces,omitempty" json:"manageNamespaces,omitempty"`
+	// NamespaceLabels are set on all namespaces created by kubor (like
+	// "pod-security.kubernetes.io/enforce: restricted").
+	NamespaceLabels map[string]string `yaml:"namespaceLabels,omitempty" json:"namespaceLabels,omitempty"`
+	// NamespaceAnnotations are set on all namespaces created by kubor.
+	NamespaceAnnotations map[string]string `yaml:"namespaceAnnotations,omitempty" json:"namespaceAnnotations,omitempty"`
+
 	// Values set using implicitly.
 	Namespaces Namespaces `yaml:"-" json:"-"`
 }
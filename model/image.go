@@ -0,0 +1,263 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	defaultImageRegistry  = "docker.io"
+	defaultImageNamespace = "library"
+)
+
+var (
+	ErrIllegalImageOverride = errors.New("illegal image override")
+	ErrIllegalImageMirror   = errors.New("illegal image mirror")
+)
+
+// ImageReference is a parsed reference to a container image like
+// "registry.example.org/shop/api:1.2.3@sha256:...".
+type ImageReference struct {
+	// Repository is the image without tag and digest as written (like "nginx" or
+	// "registry.example.org/shop/api").
+	Repository string
+	Tag        string
+	Digest     string
+}
+
+// ParseImageReference parses the given image. It does not validate the single parts.
+func ParseImageReference(plain string) ImageReference {
+	var result ImageReference
+	rest := plain
+	if v, digest, ok := strings.Cut(rest, "@"); ok {
+		rest = v
+		result.Digest = digest
+	}
+	if i := strings.LastIndexByte(rest, ':'); i > strings.LastIndexByte(rest, '/') {
+		result.Tag = rest[i+1:]
+		rest = rest[:i]
+	}
+	result.Repository = rest
+	return result
+}
+
+// NormalizedRepository returns the repository including the registry like the
+// container runtime resolves it ("nginx" becomes "docker.io/library/nginx").
+func (instance ImageReference) NormalizedRepository() string {
+	first, rest, ok := strings.Cut(instance.Repository, "/")
+	if ok && (strings.ContainsAny(first, ".:") || first == "localhost") {
+		return instance.Repository
+	}
+	if !ok {
+		return defaultImageRegistry + "/" + defaultImageNamespace + "/" + first
+	}
+	return defaultImageRegistry + "/" + first + "/" + rest
+}
+
+// IsPinned returns true if the image is referenced by its digest.
+func (instance ImageReference) IsPinned() bool {
+	return instance.Digest != ""
+}
+
+// WithVersion returns a copy of this reference which tag and digest are replaced by
+// the given version. The version could be a tag ("1.2.3"), a digest
+// ("sha256:..." or "@sha256:...") or both ("1.2.3@sha256:...").
+func (instance ImageReference) WithVersion(version string) ImageReference {
+	result := ImageReference{Repository: instance.Repository}
+	if tag, digest, ok := strings.Cut(version, "@"); ok {
+		result.Tag, result.Digest = tag, digest
+	} else if isImageDigest(version) {
+		result.Digest = version
+	} else {
+		result.Tag = version
+	}
+	return result
+}
+
+func (instance ImageReference) String() string {
+	result := instance.Repository
+	if instance.Tag != "" {
+		result += ":" + instance.Tag
+	}
+	if instance.Digest != "" {
+		result += "@" + instance.Digest
+	}
+	return result
+}
+
+func isImageDigest(plain string) bool {
+	algorithm, hex, ok := strings.Cut(plain, ":")
+	return ok && algorithm != "" && hex != "" && !strings.ContainsAny(hex, "/:")
+}
+
+// ImageOverrides maps repositories (like "registry.example.org/shop/api") to the tag
+// or digest which should be used for them instead of the ones of the templates. A
+// repository matches if it is equal to the repository of an image as written or to
+// its normalized form (see ImageReference.NormalizedRepository).
+type ImageOverrides map[string]string
+
+func (instance ImageOverrides) Validate() error {
+	for repository, version := range instance {
+		if repository == "" || strings.ContainsAny(repository, "@") {
+			return fmt.Errorf("%w: illegal repository '%s'", ErrIllegalImageOverride, repository)
+		}
+		if version == "" {
+			return fmt.Errorf("%w: no tag or digest for repository '%s'", ErrIllegalImageOverride, repository)
+		}
+		if tag, digest, ok := strings.Cut(version, "@"); ok && (!isImageDigest(digest) || strings.ContainsAny(tag, ":/")) {
+			return fmt.Errorf("%w: illegal tag or digest '%s' for repository '%s'", ErrIllegalImageOverride, version, repository)
+		}
+	}
+	return nil
+}
+
+// Find returns the version configured for the given image (if any).
+func (instance ImageOverrides) Find(image ImageReference) (string, bool) {
+	if v, ok := instance[image.Repository]; ok {
+		return v, true
+	}
+	v, ok := instance[image.NormalizedRepository()]
+	return v, ok
+}
+
+// Merge returns a copy of this instance with all entries of the given one which
+// replace the existing ones.
+func (instance ImageOverrides) Merge(with ImageOverrides) ImageOverrides {
+	return ImageOverrides(mergeStringMaps(instance, with))
+}
+
+// Set sets an override in format <repository>=<tag or digest>.
+func (instance *ImageOverrides) Set(plain string) error {
+	repository, version, _ := strings.Cut(plain, "=")
+	candidate := ImageOverrides{repository: version}
+	if err := candidate.Validate(); err != nil {
+		return err
+	}
+	*instance = instance.Merge(candidate)
+	return nil
+}
+
+func (instance ImageOverrides) String() string {
+	return formatStringMap(instance)
+}
+
+func (instance *ImageOverrides) IsCumulative() bool {
+	return true
+}
+
+// ImageMirrors maps prefixes of images (like "docker.io/") to the prefixes which
+// should be used instead (like "mirror.example.org/docker.io/"). The longest matching
+// prefix wins. Prefixes are matched against the image as written and against its
+// normalized form (see ImageReference.NormalizedRepository).
+type ImageMirrors map[string]string
+
+func (instance ImageMirrors) Validate() error {
+	for prefix, replacement := range instance {
+		if prefix == "" || replacement == "" {
+			return fmt.Errorf("%w: neither prefix nor replacement should be empty: '%s=%s'", ErrIllegalImageMirror, prefix, replacement)
+		}
+	}
+	return nil
+}
+
+// Apply returns the given image with the prefix rewritten by the longest matching
+// mirror. If no mirror matches the image is returned unchanged.
+func (instance ImageMirrors) Apply(image ImageReference) ImageReference {
+	for _, repository := range []string{image.Repository, image.NormalizedRepository()} {
+		var bestPrefix string
+		for prefix := range instance {
+			if len(prefix) > len(bestPrefix) && strings.HasPrefix(repository, prefix) {
+				bestPrefix = prefix
+			}
+		}
+		if bestPrefix != "" {
+			result := image
+			result.Repository = instance[bestPrefix] + strings.TrimPrefix(repository, bestPrefix)
+			return result
+		}
+	}
+	return image
+}
+
+// Merge returns a copy of this instance with all entries of the given one which
+// replace the existing ones.
+func (instance ImageMirrors) Merge(with ImageMirrors) ImageMirrors {
+	return ImageMirrors(mergeStringMaps(instance, with))
+}
+
+// Set sets a mirror in format <prefix>=<replacement>.
+func (instance *ImageMirrors) Set(plain string) error {
+	prefix, replacement, _ := strings.Cut(plain, "=")
+	candidate := ImageMirrors{prefix: replacement}
+	if err := candidate.Validate(); err != nil {
+		return err
+	}
+	*instance = instance.Merge(candidate)
+	return nil
+}
+
+func (instance ImageMirrors) String() string {
+	return formatStringMap(instance)
+}
+
+func (instance *ImageMirrors) IsCumulative() bool {
+	return true
+}
+
+// ImagePolicy configures the image-policy transformation which rejects images with
+// mutable tags while applying.
+type ImagePolicy struct {
+	// DenyMutableTags rejects images which are not pinned by a digest and either have
+	// no tag at all or one of MutableTags.
+	DenyMutableTags bool `yaml:"denyMutableTags,omitempty" json:"denyMutableTags,omitempty"`
+	// MutableTags are the tags which are considered as mutable. If empty only
+	// "latest" is.
+	MutableTags []string `yaml:"mutableTags,omitempty" json:"mutableTags,omitempty"`
+}
+
+// Violation returns a description why the given image violates this policy or an
+// empty string if it does not.
+func (instance ImagePolicy) Violation(image ImageReference) string {
+	if !instance.DenyMutableTags || image.IsPinned() {
+		return ""
+	}
+	if image.Tag == "" {
+		return "has neither a tag nor a digest"
+	}
+	mutableTags := instance.MutableTags
+	if len(mutableTags) == 0 {
+		mutableTags = []string{"latest"}
+	}
+	for _, candidate := range mutableTags {
+		if candidate == image.Tag {
+			return fmt.Sprintf("uses the mutable tag '%s' without a digest", image.Tag)
+		}
+	}
+	return ""
+}
+
+func mergeStringMaps(a, b map[string]string) map[string]string {
+	result := make(map[string]string, len(a)+len(b))
+	for k, v := range a {
+		result[k] = v
+	}
+	for k, v := range b {
+		result[k] = v
+	}
+	return result
+}
+
+func formatStringMap(in map[string]string) string {
+	keys := make([]string, 0, len(in))
+	for key := range in {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		parts[i] = key + "=" + in[key]
+	}
+	return strings.Join(parts, ",")
+}
@@ -4,12 +4,40 @@ const (
 	LabelGroupId    = "kubor.echocat.org/group-id"
 	LabelArtifactId = "kubor.echocat.org/artifact-id"
 	LabelRelease    = "kubor.echocat.org/release"
+
+	// Recommended labels of Kubernetes, see
+	// https://kubernetes.io/docs/concepts/overview/working-with-objects/common-labels/
+	LabelAppName      = "app.kubernetes.io/name"
+	LabelAppInstance  = "app.kubernetes.io/instance"
+	LabelAppVersion   = "app.kubernetes.io/version"
+	LabelAppComponent = "app.kubernetes.io/component"
+	LabelAppPartOf    = "app.kubernetes.io/part-of"
+	LabelAppManagedBy = "app.kubernetes.io/managed-by"
+
+	// ManagedByValue is the value of LabelAppManagedBy.
+	ManagedByValue = "kubor"
 )
 
+// Labels configures the labels kubor sets on every object. Only the kubor.echocat.org
+// labels (GroupId, ArtifactId and Release) are used to find the objects of a project
+// (like while cleaning up); the recommended labels are for other tools only.
 type Labels struct {
 	GroupId    Label `yaml:"groupId,omitempty" json:"groupId,omitempty"`
 	ArtifactId Label `yaml:"artifactId,omitempty" json:"artifactId,omitempty"`
 	Release    Label `yaml:"release,omitempty" json:"release,omitempty"`
+
+	// Name is set to the artifactId.
+	Name Label `yaml:"name,omitempty" json:"name,omitempty"`
+	// Instance is set to <artifactId>-<release>.
+	Instance Label `yaml:"instance,omitempty" json:"instance,omitempty"`
+	// Version is set to the release.
+	Version Label `yaml:"version,omitempty" json:"version,omitempty"`
+	// Component is set to the stage of the object; left untouched by default.
+	Component Label `yaml:"component,omitempty" json:"component,omitempty"`
+	// PartOf is set to the groupId (if any).
+	PartOf Label `yaml:"partOf,omitempty" json:"partOf,omitempty"`
+	// ManagedBy is set to ManagedByValue.
+	ManagedBy Label `yaml:"managedBy,omitempty" json:"managedBy,omitempty"`
 }
 
 func NewLabels() Labels {
@@ -17,5 +45,12 @@ func NewLabels() Labels {
 		GroupId:    Label{LabelGroupId, LabelActionSetIfAbsent},
 		ArtifactId: Label{LabelArtifactId, LabelActionSetIfAbsent},
 		Release:    Label{LabelRelease, LabelActionSetIfAbsent},
+
+		Name:      Label{LabelAppName, LabelActionSetIfAbsent},
+		Instance:  Label{LabelAppInstance, LabelActionSetIfAbsent},
+		Version:   Label{LabelAppVersion, LabelActionSetIfAbsent},
+		Component: Label{LabelAppComponent, LabelActionLeave},
+		PartOf:    Label{LabelAppPartOf, LabelActionSetIfAbsent},
+		ManagedBy: Label{LabelAppManagedBy, LabelActionSetIfAbsent},
 	}
 }
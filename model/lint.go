@@ -0,0 +1,67 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	LintSeverityOff     = LintSeverity("off")
+	LintSeverityInfo    = LintSeverity("info")
+	LintSeverityWarning = LintSeverity("warning")
+	LintSeverityError   = LintSeverity("error")
+)
+
+var (
+	ErrIllegalLintSeverity = errors.New("illegal lint severity")
+
+	lintSeverityOrder = map[LintSeverity]int{
+		LintSeverityOff:     0,
+		LintSeverityInfo:    1,
+		LintSeverityWarning: 2,
+		LintSeverityError:   3,
+	}
+)
+
+// Lint configures kubor lint.
+type Lint struct {
+	// Rules overrides the severity of rules by their name (like "probes: off" or
+	// "latestTag: error").
+	Rules map[string]LintSeverity `yaml:"rules,omitempty" json:"rules,omitempty"`
+}
+
+// LintSeverity is the severity of a lint finding. It could be off, info, warning or error.
+type LintSeverity string
+
+func (instance *LintSeverity) Set(plain string) error {
+	return instance.UnmarshalText([]byte(plain))
+}
+
+func (instance LintSeverity) String() string {
+	if v, err := instance.MarshalText(); err != nil {
+		return fmt.Sprintf("illegal-lint-severity-%s", string(instance))
+	} else {
+		return string(v)
+	}
+}
+
+func (instance LintSeverity) MarshalText() (text []byte, err error) {
+	if _, ok := lintSeverityOrder[instance]; !ok {
+		return nil, fmt.Errorf("%w: %s", ErrIllegalLintSeverity, string(instance))
+	}
+	return []byte(instance), nil
+}
+
+func (instance *LintSeverity) UnmarshalText(text []byte) error {
+	v := LintSeverity(text)
+	if _, ok := lintSeverityOrder[v]; !ok {
+		return fmt.Errorf("%w: %s", ErrIllegalLintSeverity, string(text))
+	}
+	*instance = v
+	return nil
+}
+
+// IsAtLeast returns true if this severity is the same or more severe than the given one.
+func (instance LintSeverity) IsAtLeast(other LintSeverity) bool {
+	return lintSeverityOrder[instance] >= lintSeverityOrder[other]
+}
@@ -2,6 +2,7 @@ package model
 
 import (
 	"bytes"
+	goerrors "errors"
 	"fmt"
 	"github.com/echocat/kubor/common"
 	"io"
@@ -15,10 +16,15 @@ import (
 )
 
 // ContentProvider provides the next resource and returns an error of io.EOF if no more element is available.
+// If an error other than io.EOF is returned, a following call will continue with the next resource.
 type ContentProvider func() (name string, content []byte, err error)
 
 type OnObject func(source string, object runtime.Object, unstructured *unstructured.Unstructured) error
 
+// PrepareObject is called for every object before it is validated and returns true if
+// it has modified the given object.
+type PrepareObject func(unstructured *unstructured.Unstructured) (modified bool, err error)
+
 func NewObjectHandler(onObject OnObject, project *Project) (*ObjectHandler, error) {
 	return &ObjectHandler{
 		OnObject:     onObject,
@@ -31,25 +37,37 @@ type ObjectHandler struct {
 	OnObject OnObject
 	Project  *Project
 
+	// Prepare is optional and is called for every object before OnObject (like to
+	// default the namespace).
+	Prepare PrepareObject
+
+	// ContinueOnError if true, every resource will be handled even if a previous one failed.
+	// All errors are collected and returned combined at the end.
+	ContinueOnError bool
+
 	Deserializer runtime.Decoder
 }
 
 func (instance *ObjectHandler) Handle(cp ContentProvider) error {
-	var name string
-	var content []byte
-	var err error
-	for name, content, err = cp(); err == nil; name, content, err = cp() {
-		if err := instance.handleContent(name, content); err != nil {
-			return err
+	var errs []error
+	for {
+		name, content, err := cp()
+		if err == io.EOF {
+			return goerrors.Join(errs...)
+		} else if se, ok := err.(*errors.StatusError); ok {
+			err = se
+		} else if err != nil {
+			err = fmt.Errorf("cannot handle '%s': %w", name, err)
+		} else {
+			err = instance.handleContent(name, content)
+		}
+		if err != nil {
+			if !instance.ContinueOnError {
+				return err
+			}
+			errs = append(errs, err)
 		}
 	}
-	if err == io.EOF {
-		return nil
-	}
-	if se, ok := err.(*errors.StatusError); ok {
-		return se
-	}
-	return fmt.Errorf("cannot handle '%s': %w", name, err)
 }
 
 func (instance *ObjectHandler) handleContent(source string, content []byte) error {
@@ -70,14 +88,14 @@ func (instance *ObjectHandler) handleContent(source string, content []byte) erro
 					return fmt.Errorf("%s: %w", fSource, err)
 				} else if !instance.Project.Scheme.IsIgnored(GroupVersionKind(unstr.GroupVersionKind())) {
 					return fmt.Errorf("%s: %w", fSource, err)
-				} else if err := instance.OnObject(fSource, unstr, unstr); err != nil {
+				} else if err := instance.onObject(fSource, unstr, unstr); err != nil {
 					return fmt.Errorf("%s: %w", fSource, err)
 				}
 			} else if err != nil {
 				return fmt.Errorf("%s: %w", fSource, err)
 			} else if unstr, err := instance.decodeUnstructured([]byte(part)); err != nil {
 				return fmt.Errorf("%s: %w", fSource, err)
-			} else if err := instance.OnObject(fSource, object, unstr); err != nil {
+			} else if err := instance.onObject(fSource, object, unstr); err != nil {
 				return fmt.Errorf("%s: %w", fSource, err)
 			}
 		}
@@ -85,6 +103,20 @@ func (instance *ObjectHandler) handleContent(source string, content []byte) erro
 	return nil
 }
 
+func (instance *ObjectHandler) onObject(source string, object runtime.Object, unstructured *unstructured.Unstructured) error {
+	if instance.Prepare != nil {
+		if modified, err := instance.Prepare(unstructured); err != nil {
+			return err
+		} else if modified && object != unstructured {
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(unstructured.Object, object); err != nil {
+				return err
+			}
+		}
+	}
+	instance.Project.Rendered.Add(unstructured)
+	return instance.OnObject(source, object, unstructured)
+}
+
 func (instance *ObjectHandler) decodeUnstructured(content []byte) (*unstructured.Unstructured, error) {
 	result := &unstructured.Unstructured{}
 
@@ -0,0 +1,70 @@
+package model
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"testing"
+)
+
+func newObjectsTestContentProvider(contents ...string) ContentProvider {
+	return func() (string, []byte, error) {
+		if len(contents) == 0 {
+			return "", nil, io.EOF
+		}
+		content := contents[0]
+		contents = contents[1:]
+		return "test.yml", []byte(content), nil
+	}
+}
+
+func Test_ObjectHandler_Prepare_resyncs_typed_object(t *testing.T) {
+	project := NewProject()
+	var handled []runtime.Object
+	instance, err := NewObjectHandler(func(_ string, object runtime.Object, unstr *unstructured.Unstructured) error {
+		assert.Equal(t, "shop", unstr.GetNamespace())
+		handled = append(handled, object)
+		return nil
+	}, &project)
+	require.NoError(t, err)
+	instance.Prepare = func(unstr *unstructured.Unstructured) (bool, error) {
+		if unstr.GetNamespace() != "" {
+			return false, nil
+		}
+		unstr.SetNamespace("shop")
+		return true, nil
+	}
+
+	err = instance.Handle(newObjectsTestContentProvider(
+		"apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: a\ndata:\n  key: value\n",
+		"apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: b\n  namespace: shop\n",
+	))
+
+	require.NoError(t, err)
+	require.Len(t, handled, 2)
+	configMap, ok := handled[0].(*corev1.ConfigMap)
+	require.True(t, ok, "expected typed object but got %T", handled[0])
+	assert.Equal(t, "shop", configMap.Namespace)
+	assert.Equal(t, "a", configMap.Name)
+	assert.Equal(t, map[string]string{"key": "value"}, configMap.Data)
+	assert.NotNil(t, project.Rendered.Get(corev1.SchemeGroupVersion.WithKind("ConfigMap").GroupKind(), "shop", "a"))
+}
+
+func Test_ObjectHandler_Prepare_fails(t *testing.T) {
+	project := NewProject()
+	instance, err := NewObjectHandler(func(string, runtime.Object, *unstructured.Unstructured) error {
+		t.Fatal("should not be called")
+		return nil
+	}, &project)
+	require.NoError(t, err)
+	instance.Prepare = func(*unstructured.Unstructured) (bool, error) {
+		return false, io.ErrUnexpectedEOF
+	}
+
+	err = instance.Handle(newObjectsTestContentProvider("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: a\n"))
+
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+}
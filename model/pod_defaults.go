@@ -0,0 +1,131 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const (
+	SeccompProfileTypeRuntimeDefault = SeccompProfileType("RuntimeDefault")
+	SeccompProfileTypeLocalhost      = SeccompProfileType("Localhost")
+	SeccompProfileTypeUnconfined     = SeccompProfileType("Unconfined")
+)
+
+var (
+	ErrIllegalSeccompProfileType = errors.New("illegal seccomp profile type")
+)
+
+// PodDefaults are injected by the pod-defaults transformation into the pod specs of
+// all objects they select. Every value is only set if the pod spec does not define it
+// already; if several entries select the same object the earlier ones win.
+type PodDefaults []PodDefault
+
+func (instance PodDefaults) Validate() error {
+	for i, candidate := range instance {
+		if err := candidate.Validate(); err != nil {
+			return fmt.Errorf("podDefaults[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+type PodDefault struct {
+	// Selector selects the objects these defaults are applied to. If empty all
+	// objects with pod specs are selected.
+	Selector TransformationSelector `yaml:"selector,omitempty" json:"selector,omitempty"`
+
+	// RunAsNonRoot is set as spec.securityContext.runAsNonRoot of the pod.
+	RunAsNonRoot *bool `yaml:"runAsNonRoot,omitempty" json:"runAsNonRoot,omitempty"`
+	// SeccompProfile is set as spec.securityContext.seccompProfile of the pod.
+	SeccompProfile *SeccompProfile `yaml:"seccompProfile,omitempty" json:"seccompProfile,omitempty"`
+	// AutomountServiceAccountToken is set as spec.automountServiceAccountToken of the pod.
+	AutomountServiceAccountToken *bool `yaml:"automountServiceAccountToken,omitempty" json:"automountServiceAccountToken,omitempty"`
+
+	// ReadOnlyRootFilesystem is set as securityContext.readOnlyRootFilesystem of every
+	// (init) container.
+	ReadOnlyRootFilesystem *bool `yaml:"readOnlyRootFilesystem,omitempty" json:"readOnlyRootFilesystem,omitempty"`
+	// DropAllCapabilities sets securityContext.capabilities.drop of every (init)
+	// container to ALL.
+	DropAllCapabilities *bool `yaml:"dropAllCapabilities,omitempty" json:"dropAllCapabilities,omitempty"`
+	// Resources are set for every (init) container per resource (like cpu or memory).
+	Resources PodDefaultResources `yaml:"resources,omitempty" json:"resources,omitempty"`
+}
+
+func (instance PodDefault) Validate() error {
+	if err := instance.Selector.Validate(); err != nil {
+		return err
+	}
+	if v := instance.SeccompProfile; v != nil {
+		if err := v.Validate(); err != nil {
+			return err
+		}
+	}
+	return instance.Resources.Validate()
+}
+
+type SeccompProfile struct {
+	Type SeccompProfileType `yaml:"type" json:"type"`
+	// LocalhostProfile is required if Type is Localhost.
+	LocalhostProfile string `yaml:"localhostProfile,omitempty" json:"localhostProfile,omitempty"`
+}
+
+func (instance SeccompProfile) Validate() error {
+	if _, err := instance.Type.MarshalText(); err != nil {
+		return err
+	}
+	if (instance.Type == SeccompProfileTypeLocalhost) != (instance.LocalhostProfile != "") {
+		return fmt.Errorf("seccompProfile.localhostProfile is required for and only allowed with type %v", SeccompProfileTypeLocalhost)
+	}
+	return nil
+}
+
+type SeccompProfileType string
+
+func (instance *SeccompProfileType) Set(plain string) error {
+	return instance.UnmarshalText([]byte(plain))
+}
+
+func (instance SeccompProfileType) String() string {
+	if v, err := instance.MarshalText(); err != nil {
+		return fmt.Sprintf("illegal-seccomp-profile-type-%s", string(instance))
+	} else {
+		return string(v)
+	}
+}
+
+func (instance SeccompProfileType) MarshalText() (text []byte, err error) {
+	switch instance {
+	case SeccompProfileTypeRuntimeDefault, SeccompProfileTypeLocalhost, SeccompProfileTypeUnconfined:
+		return []byte(instance), nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrIllegalSeccompProfileType, string(instance))
+	}
+}
+
+func (instance *SeccompProfileType) UnmarshalText(text []byte) error {
+	v := SeccompProfileType(text)
+	if _, err := v.MarshalText(); err != nil {
+		return err
+	}
+	*instance = v
+	return nil
+}
+
+// PodDefaultResources are the default requests and limits of containers by the name
+// of the resource (like cpu or memory).
+type PodDefaultResources struct {
+	Requests map[string]string `yaml:"requests,omitempty" json:"requests,omitempty"`
+	Limits   map[string]string `yaml:"limits,omitempty" json:"limits,omitempty"`
+}
+
+func (instance PodDefaultResources) Validate() error {
+	for kind, values := range map[string]map[string]string{"requests": instance.Requests, "limits": instance.Limits} {
+		for name, value := range values {
+			if _, err := resource.ParseQuantity(value); err != nil {
+				return fmt.Errorf("illegal quantity '%s' of resources.%s.%s: %w", value, kind, name, err)
+			}
+		}
+	}
+	return nil
+}
@@ -1,29 +1,49 @@
 package model
 
 import (
+	"errors"
 	"fmt"
 	"github.com/echocat/kubor/common"
+	"github.com/echocat/kubor/template/functions"
 	"github.com/echocat/slf4g"
 	"gopkg.in/yaml.v2"
 	"io"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 )
 
 type Project struct {
 	// Values set using Load() method.
+	Extends           ProjectIncludes     `yaml:"extends,omitempty" json:"extends,omitempty"`
+	Include           ProjectIncludes     `yaml:"include,omitempty" json:"include,omitempty"`
 	GroupId           Name                `yaml:"groupId,omitempty" json:"groupId,omitempty"`
 	ArtifactId        Name                `yaml:"artifactId" json:"artifactId"`
 	Release           string              `yaml:"release,omitempty" json:"release,omitempty"`
 	Claim             Claim               `yaml:"claim,omitempty" json:"claim,omitempty"`
 	Stages            Stages              `yaml:"stages,omitempty" json:"stages,omitempty"`
 	Templating        Templating          `yaml:"templating,omitempty" json:"templating,omitempty"`
+	ValuesFiles       ValuesFiles         `yaml:"valuesFiles,omitempty" json:"valuesFiles,omitempty"`
 	ConditionalValues []ConditionalValues `yaml:"values,omitempty" json:"values,omitempty"`
+	ValuesMerge       ValuesMerge         `yaml:"valuesMerge,omitempty" json:"valuesMerge,omitempty"`
+	ValuesSchema      ValuesSchema        `yaml:"valuesSchema,omitempty" json:"valuesSchema,omitempty"`
+	Secrets           Secrets             `yaml:"secrets,omitempty" json:"secrets,omitempty"`
 	Labels            Labels              `yaml:"labels,omitempty" json:"labels,omitempty"`
 	Annotations       Annotations         `yaml:"annotations,omitempty" json:"annotations,omitempty"`
 	Transformations   Transformations     `yaml:"transformations,omitempty" json:"transformations,omitempty"`
 	Scheme            Scheme              `yaml:"scheme,omitempty" json:"scheme,omitempty"`
+	Lint              Lint                `yaml:"lint,omitempty" json:"lint,omitempty"`
+	Ci                Ci                  `yaml:"ci,omitempty" json:"ci,omitempty"`
+	Images            ImageOverrides      `yaml:"images,omitempty" json:"images,omitempty"`
+	ImageMirrors      ImageMirrors        `yaml:"imageMirrors,omitempty" json:"imageMirrors,omitempty"`
+	ImagePolicy       ImagePolicy         `yaml:"imagePolicy,omitempty" json:"imagePolicy,omitempty"`
+	PodDefaults       PodDefaults         `yaml:"podDefaults,omitempty" json:"podDefaults,omitempty"`
+	// SourceDefaultNamespace is a template of the namespace which is set on all
+	// namespaced objects without metadata.namespace. If empty the only claimed
+	// namespace is used (see DefaultNamespace).
+	SourceDefaultNamespace string `yaml:"defaultNamespace,omitempty" json:"defaultNamespace,omitempty"`
 
 	// Values set using implicitly.
 	Source  string            `yaml:"-" json:"-"`
@@ -31,6 +51,27 @@ type Project struct {
 	Values  Values            `yaml:"-" json:"-"`
 	Env     map[string]string `yaml:"-" json:"-"`
 	Context string            `yaml:"-" json:"-"`
+
+	// Bases are all project files which were merged into Source (see Extends and
+	// Include) in the order they were applied.
+	Bases []string `yaml:"-" json:"-"`
+	// WorkspaceMember is the name of the member of the workspace this project was
+	// created for; empty if not created as part of a workspace.
+	WorkspaceMember string `yaml:"-" json:"-"`
+
+	// ValuesProvenance records which sources have set the Values.
+	ValuesProvenance ValuesProvenance `yaml:"-" json:"-"`
+	// ValuesSchemaViolations holds the violations of the Values against the
+	// ValuesSchema; nil if there are none. Only a project created by
+	// ProjectFactory.CreateToleratingViolations could have some.
+	ValuesSchemaViolations *ValuesSchemaViolations `yaml:"-" json:"-"`
+
+	// DefaultNamespace is the rendered SourceDefaultNamespace or - if this is empty
+	// and exactly one namespace is claimed - this namespace; otherwise empty.
+	DefaultNamespace Namespace `yaml:"-" json:"-"`
+
+	// Rendered holds all objects rendered by an ObjectHandler of this project so far.
+	Rendered RenderedObjects `yaml:"-" json:"-"`
 }
 
 func NewProject() Project {
@@ -43,6 +84,8 @@ func NewProject() Project {
 		Annotations:       NewAnnotations(),
 		Transformations:   NewTransformations(),
 		Values:            NewValues(),
+		ValuesProvenance:  ValuesProvenance{},
+		Secrets:           NewSecrets(),
 		Env:               make(map[string]string),
 	}
 }
@@ -51,10 +94,25 @@ func (instance Project) Validate() error {
 	if instance.ArtifactId == "" {
 		return fmt.Errorf("artifactId should not be empty")
 	}
+	if err := instance.Transformations.Validate(); err != nil {
+		return err
+	}
+	if err := instance.Images.Validate(); err != nil {
+		return err
+	}
+	if err := instance.ImageMirrors.Validate(); err != nil {
+		return err
+	}
+	if err := instance.PodDefaults.Validate(); err != nil {
+		return err
+	}
 	return nil
 }
 
+// Save writes this project to Source. Top level properties which are equal to the
+// ones of NewProject are omitted; so the file still follows changed defaults.
 func (instance *Project) Save() error {
+	document := instance.withoutDefaults()
 	dir := filepath.Dir(instance.Source)
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return fmt.Errorf("cannot create parent directory for source file '%s': %w", instance.Source, err)
@@ -64,13 +122,35 @@ func (instance *Project) Save() error {
 		//noinspection GoUnhandledErrorResult
 		defer f.Close()
 		encoder := yaml.NewEncoder(f)
-		if err := encoder.Encode(instance); err != nil {
+		if err := encoder.Encode(document); err != nil {
 			return fmt.Errorf("cannot save source file '%s': %w", instance.Source, err)
 		}
 		return nil
 	}
 }
 
+func (instance *Project) withoutDefaults() yaml.MapSlice {
+	defaults := reflect.ValueOf(NewProject())
+	v := reflect.ValueOf(*instance)
+	t := v.Type()
+	var result yaml.MapSlice
+	for i := 0; i < t.NumField(); i++ {
+		name, options, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		value := v.Field(i)
+		if value.IsZero() && strings.Contains(options, "omitempty") {
+			continue
+		}
+		if reflect.DeepEqual(value.Interface(), defaults.Field(i).Interface()) {
+			continue
+		}
+		result = append(result, yaml.MapItem{Key: name, Value: value.Interface()})
+	}
+	return result
+}
+
 func (instance Project) RenderedTemplatesProvider() (ContentProvider, error) {
 	return instance.Templating.RenderedTemplatesProvider(instance)
 }
@@ -97,9 +177,16 @@ type ProjectFactory struct {
 	source         string
 	sourceRequired bool
 	values         Values
+	valuesFiles    []string
+	secretsKeyFile string
+	workspace      string
+	memberOf       *Workspace
 	artifactId     Name
 	groupId        Name
 	release        string
+	images         ImageOverrides
+	imageMirrors   ImageMirrors
+	denyMutable    bool
 }
 
 func NewProjectFactory() *ProjectFactory {
@@ -107,6 +194,125 @@ func NewProjectFactory() *ProjectFactory {
 }
 
 func (instance *ProjectFactory) Create(context string) (*Project, error) {
+	return failOnValuesSchemaViolations(instance.create(context, false))
+}
+
+// CreateToleratingViolations creates the project like Create does but does not fail
+// if the values violate the values schema. The violations are available at
+// Project.ValuesSchemaViolations instead.
+func (instance *ProjectFactory) CreateToleratingViolations(context string) (*Project, error) {
+	return instance.create(context, false)
+}
+
+// GroupId returns the groupId set by --groupId (if any).
+func (instance *ProjectFactory) GroupId() Name {
+	return instance.groupId
+}
+
+// ArtifactId returns the artifactId set by --artifactId (if any).
+func (instance *ProjectFactory) ArtifactId() Name {
+	return instance.artifactId
+}
+
+// Source returns the location of the project file (see --source).
+func (instance *ProjectFactory) Source() (string, error) {
+	return instance.resolveSource()
+}
+
+// Workspace returns the workspace configured by --workspace or - if not set - by the
+// environment variable KUBOR_WORKSPACE; nil if there is none.
+func (instance *ProjectFactory) Workspace() (*Workspace, error) {
+	source := instance.workspace
+	if source == "" {
+		source = os.Getenv("KUBOR_WORKSPACE")
+	}
+	if source == "" {
+		return nil, nil
+	}
+	return LoadWorkspace(source)
+}
+
+// HasExplicitWorkspace returns true if the workspace was set using --workspace and
+// not only by the environment variable KUBOR_WORKSPACE.
+func (instance *ProjectFactory) HasExplicitWorkspace() bool {
+	return instance.workspace != ""
+}
+
+// CreateAll creates the projects of all members of the workspace (see Workspace) in
+// the order of their dependencies or - if reverse is true - in the reverse order (see
+// Workspace.ReverseOrdered). If there is no workspace it only returns the project which
+// is created by Create.
+func (instance *ProjectFactory) CreateAll(context string, reverse bool) ([]*Project, error) {
+	workspace, err := instance.Workspace()
+	if err != nil {
+		return nil, err
+	} else if workspace == nil {
+		if project, err := instance.Create(context); err != nil {
+			return nil, err
+		} else {
+			return []*Project{project}, nil
+		}
+	}
+
+	members, err := workspace.Ordered()
+	if reverse {
+		members, err = workspace.ReverseOrdered()
+	}
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*Project, len(members))
+	for i, member := range members {
+		factory := *instance
+		factory.source = member.Source
+		factory.sourceRequired = true
+		factory.memberOf = workspace
+		// Every member keeps its own identity.
+		factory.groupId = ""
+		factory.artifactId = ""
+		if project, err := factory.Create(context); err != nil {
+			return nil, fmt.Errorf("cannot create project of workspace member '%s': %w", member.Name, err)
+		} else {
+			project.WorkspaceMember = member.Name
+			result[i] = project
+		}
+	}
+	return result, nil
+}
+
+// CreateWithoutSecrets creates the project like Create does but without
+// decrypting and merging its secrets files into the values.
+func (instance *ProjectFactory) CreateWithoutSecrets(context string) (*Project, error) {
+	return failOnValuesSchemaViolations(instance.create(context, true))
+}
+
+func failOnValuesSchemaViolations(project *Project, err error) (*Project, error) {
+	if err != nil {
+		return nil, err
+	} else if project.ValuesSchemaViolations != nil {
+		return nil, *project.ValuesSchemaViolations
+	}
+	return project, nil
+}
+
+// SecretKeys returns the keys to decrypt secrets files. They are taken from the
+// environment variable KUBOR_SECRETS_KEY or from --secretsKeyFile.
+func (instance *ProjectFactory) SecretKeys() (SecretKeys, error) {
+	passphrase := []byte(os.Getenv("KUBOR_SECRETS_PASSPHRASE"))
+	if plain := os.Getenv("KUBOR_SECRETS_KEY"); plain != "" {
+		return ParseSecretKeys([]byte(plain), passphrase)
+	}
+	if instance.secretsKeyFile != "" {
+		if content, err := os.ReadFile(instance.secretsKeyFile); err != nil {
+			return SecretKeys{}, fmt.Errorf("cannot read secrets key file '%s': %w", instance.secretsKeyFile, err)
+		} else {
+			return ParseSecretKeys(content, passphrase)
+		}
+	}
+	return SecretKeys{}, nil
+}
+
+func (instance *ProjectFactory) create(context string, skipSecrets bool) (*Project, error) {
 	result := NewProject()
 	result.Context = context
 
@@ -116,21 +322,25 @@ func (instance *ProjectFactory) Create(context string) (*Project, error) {
 		}
 	} else if err != nil {
 		return nil, fmt.Errorf("cannot open source file '%s': %w", instance.source, err)
-	} else if f, err := os.Open(source); err != nil {
-		return nil, fmt.Errorf("cannot open source file '%s': %w", source, err)
+	} else if document, bases, err := resolveProjectDocument(source); err != nil {
+		return nil, err
 	} else {
-		//noinspection GoUnhandledErrorResult
-		defer f.Close()
-		if err := yaml.NewDecoder(f).Decode(&result); err != nil {
+		if b, err := yaml.Marshal(document); err != nil {
+			return nil, fmt.Errorf("cannot read source file '%s': %w", source, err)
+		} else if err := yaml.Unmarshal(b, &result); err != nil {
 			return nil, fmt.Errorf("cannot read source file '%s': %w", source, err)
 		} else if err := result.Validate(); err != nil {
 			return nil, fmt.Errorf("cannot read source file '%s': %w", source, err)
 		}
+		result.Bases = bases
 
 		if result, err = instance.populateStage1(source, result); err != nil {
 			return nil, err
 		}
-		if result, err = instance.populateStage2(result); err != nil {
+		if result, err = instance.populateStage2(result, skipSecrets); err != nil {
+			return nil, err
+		}
+		if result, err = instance.populateValuesSchema(result); err != nil {
 			return nil, err
 		}
 		if result, err = instance.populateStage3(result); err != nil {
@@ -198,10 +408,7 @@ func (instance *ProjectFactory) populateStage1(source string, input Project) (Pr
 	result := input
 	result.Source = source
 	result.Root = filepath.Dir(result.Source)
-	result.Values = Values{}
-	for k, v := range instance.values {
-		result.Values[k] = v
-	}
+	result.Values = NewValues()
 	if instance.groupId != "" {
 		result.GroupId = instance.groupId
 	}
@@ -214,18 +421,107 @@ func (instance *ProjectFactory) populateStage1(source string, input Project) (Pr
 	if result.Release == "" {
 		result.Release = "latest"
 	}
+	result.Images = result.Images.Merge(instance.images)
+	result.ImageMirrors = result.ImageMirrors.Merge(instance.imageMirrors)
+	if instance.denyMutable {
+		result.ImagePolicy.DenyMutableTags = true
+	}
 	result.Env = common.Environ()
 	return result, nil
 }
 
-func (instance *ProjectFactory) populateStage2(input Project) (Project, error) {
+// populateStage2 merges the values of all sources in the following order (later ones
+// win): defaults, values files, values of the workspace, secrets files, conditional values and at last the values
+// of the CLI. If skipSecrets is true the secrets files are not decrypted and merged.
+// The values of the CLI and of all previous files are also visible while evaluating the
+// locations of the values files and the conditions.
+func (instance *ProjectFactory) populateStage2(input Project, skipSecrets bool) (Project, error) {
 	result := input
-	for _, candidate := range input.ConditionalValues {
-		if ok, err := candidate.On.Matches(result); err != nil {
+	withCliValues := func() Project {
+		view := result
+		view.Values = result.ValuesMerge.Merge(result.Values, instance.values)
+		return view
+	}
+
+	result.ValuesProvenance = ValuesProvenance{}
+	merge := func(source ValueSource, values Values) {
+		result.Values = result.ValuesMerge.Merge(result.Values, values)
+		result.ValuesProvenance.Record(source, values)
+	}
+
+	mergeFile := func(file string) error {
+		if values, err := ValuesFromFile(file); err != nil {
+			return err
+		} else {
+			merge(ValueSource{Kind: ValueSourceValuesFile, Reference: file}, values)
+			return nil
+		}
+	}
+	for _, candidate := range input.ValuesFiles {
+		if file, err := candidate.Resolve(result.Root, withCliValues()); err != nil {
+			return Project{}, err
+		} else if file == "" {
+			continue
+		} else if err := mergeFile(file); err != nil {
+			return Project{}, err
+		}
+	}
+	for _, file := range instance.valuesFiles {
+		if err := mergeFile(file); err != nil {
+			return Project{}, err
+		}
+	}
+
+	if ws := instance.memberOf; ws != nil && len(ws.Values) > 0 {
+		merge(ValueSource{Kind: ValueSourceWorkspace, Reference: ws.Source}, ws.Values)
+	}
+
+	if !skipSecrets && len(input.Secrets.Files) > 0 {
+		keys, err := instance.SecretKeys()
+		if err != nil {
+			return Project{}, err
+		}
+		for _, candidate := range input.Secrets.Files {
+			if file, err := candidate.Resolve(result.Root, withCliValues()); err != nil {
+				return Project{}, err
+			} else if file == "" {
+				continue
+			} else if values, err := keys.ValuesFromSecretsFile(file); err != nil {
+				return Project{}, err
+			} else {
+				merge(ValueSource{Kind: ValueSourceSecretsFile, Reference: file}, Values{input.Secrets.GetValuesKey(): values})
+			}
+		}
+	}
+
+	for i, candidate := range input.ConditionalValues {
+		if ok, err := candidate.On.Matches(withCliValues()); err != nil {
 			return Project{}, err
 		} else if ok {
-			result.Values = result.Values.MergeWith(candidate.Values)
-			result.Values = result.Values.MergeWith(instance.values)
+			merge(ValueSource{Kind: ValueSourceConditional, Reference: conditionalValueSourceReference(i, candidate)}, candidate.Values)
+		}
+	}
+
+	merge(ValueSource{Kind: ValueSourceCli, Reference: "--value"}, instance.values)
+	return result, nil
+}
+
+// populateValuesSchema injects the defaults of the values schema (if any) into the
+// values and validates them afterwards against it. Violations are recorded at
+// Project.ValuesSchemaViolations.
+func (instance *ProjectFactory) populateValuesSchema(input Project) (Project, error) {
+	result := input
+	if schema, err := result.ValuesSchema.Load(result.Root); err != nil {
+		return Project{}, err
+	} else if schema != nil {
+		before := result.Values
+		result.Values = schema.ApplyDefaults(result.Values)
+		result.ValuesProvenance.RecordAdded(ValueSource{Kind: ValueSourceDefault, Reference: schema.Source}, before, result.Values)
+		var violations ValuesSchemaViolations
+		if err := schema.Validate(result.Values); errors.As(err, &violations) {
+			result.ValuesSchemaViolations = &violations
+		} else if err != nil {
+			return Project{}, err
 		}
 	}
 	return result, nil
@@ -238,6 +534,25 @@ func (instance *ProjectFactory) populateStage3(input Project) (Project, error) {
 		return Project{}, err
 	}
 	result.Claim = c
+	if result.DefaultNamespace, err = input.evaluateDefaultNamespace(c); err != nil {
+		return Project{}, err
+	}
+	return result, nil
+}
+
+func (instance Project) evaluateDefaultNamespace(claim Claim) (Namespace, error) {
+	var result Namespace
+	if source := instance.SourceDefaultNamespace; source == "" {
+		if len(claim.Namespaces) == 1 {
+			result = claim.Namespaces[0]
+		}
+	} else if tmpl, err := functions.DefaultTemplateFactory().New(source, source); err != nil {
+		return "", fmt.Errorf("cannot handle defaultNamespace '%s': %w", source, err)
+	} else if rendered, err := tmpl.ExecuteToString(instance); err != nil {
+		return "", fmt.Errorf("cannot handle defaultNamespace '%s': %w", source, err)
+	} else if err := result.Set(rendered); err != nil {
+		return "", fmt.Errorf("cannot handle defaultNamespace '%s': %w", source, err)
+	}
 	return result, nil
 }
 
@@ -265,6 +580,36 @@ func (instance *ProjectFactory) ConfigureFlags(hf common.HasFlags) {
 		BoolVar(&instance.sourceRequired)
 	hf.Flag("value", "Specifies values which should be provided to the runtime.").
 		Short('v').
-		PlaceHolder("<name>=[<value>]").
+		PlaceHolder("<name>[:<type>]=[<value>]").
 		SetValue(&instance.values)
+	hf.Flag("secretsKeyFile", "File which contains the keys (age identities or an armored OpenPGP private key)"+
+		" to decrypt the secrets files. Alternatively the keys could be provided using the environment variable"+
+		" KUBOR_SECRETS_KEY. The passphrase of an OpenPGP key is taken from KUBOR_SECRETS_PASSPHRASE.").
+		Envar("KUBOR_SECRETS_KEY_FILE").
+		PlaceHolder("<file>").
+		StringVar(&instance.secretsKeyFile)
+	hf.Flag("workspace", "Specifies the location of a workspace file (like "+DefaultWorkspaceSource+") which lists several"+
+		" projects (members) and the dependencies between them. If set apply, delete and evaluate are executed for every"+
+		" member in the order of their dependencies instead of for --source. Alternatively the workspace could be"+
+		" provided using the environment variable KUBOR_WORKSPACE which is ignored by commands without workspace support.").
+		PlaceHolder("<workspace file>").
+		StringVar(&instance.workspace)
+	hf.Flag("valuesFile", "Specifies YAML or JSON files which values should be provided to the runtime."+
+		" They are applied in the given order after the values files of the source file and before the conditional values.").
+		PlaceHolder("<file>").
+		Envar("KUBOR_VALUES_FILE").
+		StringsVar(&instance.valuesFiles)
+	hf.Flag("image", "Overrides the tag or digest of all images of the given repository (like"+
+		" registry.example.org/shop/api=1.2.3 or registry.example.org/shop/api=sha256:...). Entries win over the"+
+		" images of the source file.").
+		PlaceHolder("<repository>=<tag or digest>").
+		SetValue(&instance.images)
+	hf.Flag("imageMirror", "Rewrites all images starting with the given prefix to start with the replacement"+
+		" instead (like docker.io/=mirror.example.org/docker.io/). Entries win over the imageMirrors of the source file.").
+		PlaceHolder("<prefix>=<replacement>").
+		SetValue(&instance.imageMirrors)
+	hf.Flag("denyMutableImageTags", "If set apply fails for images which are not pinned by a digest and use a mutable"+
+		" tag (see imagePolicy.mutableTags of the source file) or no tag at all.").
+		Envar("KUBOR_DENY_MUTABLE_IMAGE_TAGS").
+		BoolVar(&instance.denyMutable)
 }
@@ -0,0 +1,255 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v2"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const (
+	projectExtendsKey = "extends"
+	projectIncludeKey = "include"
+)
+
+var (
+	ErrCyclicProjectIncludes = errors.New("cyclic project includes")
+
+	// projectDocumentLineRegexp matches the line information of YAML errors which is
+	// meaningless for documents which were merged out of several files.
+	projectDocumentLineRegexp = regexp.MustCompile(`^line \d+: `)
+)
+
+// projectConcatenatedLists are the lists of project files which are concatenated
+// (bases first) instead of replaced while resolving includes.
+var projectConcatenatedLists = map[string]bool{
+	"valuesFiles":   true,
+	"values":        true,
+	"secrets.files": true,
+}
+
+// ProjectIncludes are the locations of other project files which are merged into a
+// project file. It could either be a single location or a list of them. Relative
+// locations are resolved against the directory of the file which contains them.
+type ProjectIncludes []string
+
+func (instance *ProjectIncludes) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var single string
+	if err := unmarshal(&single); err == nil {
+		if single == "" {
+			*instance = nil
+		} else {
+			*instance = ProjectIncludes{single}
+		}
+		return nil
+	}
+	var multiple []string
+	if err := unmarshal(&multiple); err != nil {
+		return err
+	}
+	*instance = multiple
+	return nil
+}
+
+// resolveProjectDocument reads the given project file and merges all files it
+// extends or includes (recursively) into it. The bases are merged in the order
+// extends, include and at last the file itself; later ones win:
+//
+//   - Maps (like claim, stages, labels, annotations, transformations or scheme) are
+//     merged deeply. A key set to null removes the inherited value.
+//   - valuesFiles, values and secrets.files are concatenated, bases first.
+//   - Every other value (including all other lists) replaces the inherited one.
+//
+// Relative paths inside of base files (like valuesFiles or valuesSchema) are always
+// resolved against the root of the resulting project, not against the base file.
+//
+// A file which is reached more than once (like the shared base of two includes) is
+// only merged at its first occurrence.
+//
+// It returns the merged document and all files which were merged into it in the
+// order they were applied.
+func resolveProjectDocument(file string) (map[string]interface{}, []string, error) {
+	r := projectDocumentResolver{
+		loaded:  map[string]map[string]interface{}{},
+		applied: map[string]bool{},
+	}
+	document, err := r.resolve(file, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return document, r.bases, nil
+}
+
+// CheckProjectSource reads the given project file (including all files it extends or
+// includes) strictly and returns every problem found, like unknown keys or values of
+// the wrong type.
+func CheckProjectSource(file string) []error {
+	document, _, err := resolveProjectDocument(file)
+	if err != nil {
+		return []error{err}
+	}
+	b, err := yaml.Marshal(document)
+	if err != nil {
+		return []error{err}
+	}
+	project := NewProject()
+	if err := yaml.UnmarshalStrict(b, &project); err != nil {
+		var te *yaml.TypeError
+		if errors.As(err, &te) {
+			result := make([]error, len(te.Errors))
+			for i, message := range te.Errors {
+				result[i] = errors.New(projectDocumentLineRegexp.ReplaceAllString(message, ""))
+			}
+			return result
+		}
+		return []error{err}
+	} else if err := project.Validate(); err != nil {
+		return []error{err}
+	}
+	return nil
+}
+
+type projectDocumentResolver struct {
+	loaded  map[string]map[string]interface{}
+	applied map[string]bool
+	bases   []string
+}
+
+func (instance *projectDocumentResolver) resolve(file string, chain []string) (map[string]interface{}, error) {
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read source file '%s': %w", file, err)
+	}
+	for i, candidate := range chain {
+		if candidate == abs {
+			return nil, fmt.Errorf("%w: %s", ErrCyclicProjectIncludes, strings.Join(append(chain[i:], abs), " -> "))
+		}
+	}
+	chain = append(chain[:len(chain):len(chain)], abs)
+
+	document, err := instance.load(file, abs)
+	if err != nil {
+		return nil, err
+	}
+
+	var includes struct {
+		Extends ProjectIncludes `yaml:"extends"`
+		Include ProjectIncludes `yaml:"include"`
+	}
+	if b, err := yaml.Marshal(document); err != nil {
+		return nil, fmt.Errorf("cannot read source file '%s': %w", file, err)
+	} else if err := yaml.Unmarshal(b, &includes); err != nil {
+		return nil, fmt.Errorf("cannot read source file '%s': illegal %s or %s: %w", file, projectExtendsKey, projectIncludeKey, err)
+	}
+
+	result := map[string]interface{}{}
+	for _, candidate := range append(includes.Extends, includes.Include...) {
+		if !filepath.IsAbs(candidate) {
+			candidate = filepath.Join(filepath.Dir(file), candidate)
+		}
+		if candidateAbs, err := filepath.Abs(candidate); err != nil {
+			return nil, fmt.Errorf("cannot read source file '%s': %w", candidate, err)
+		} else if instance.applied[candidateAbs] {
+			continue
+		} else if base, err := instance.resolve(candidate, chain); err != nil {
+			return nil, err
+		} else {
+			result = mergeProjectDocuments(nil, result, base)
+			instance.applied[candidateAbs] = true
+		}
+		instance.bases = append(instance.bases, candidate)
+	}
+	own := make(map[string]interface{}, len(document))
+	for key, value := range document {
+		if key != projectExtendsKey && key != projectIncludeKey {
+			own[key] = value
+		}
+	}
+	return mergeProjectDocuments(nil, result, own), nil
+}
+
+func (instance *projectDocumentResolver) load(file string, abs string) (map[string]interface{}, error) {
+	if document, ok := instance.loaded[abs]; ok {
+		return document, nil
+	}
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read source file '%s': %w", file, err)
+	}
+	var plain projectDocumentValue
+	if err := yaml.Unmarshal(content, &plain); err != nil {
+		return nil, fmt.Errorf("cannot read source file '%s': %w", file, err)
+	}
+	document, ok := plain.value.(map[string]interface{})
+	if !ok && plain.value != nil {
+		return nil, fmt.Errorf("cannot read source file '%s': expected a map at root", file)
+	} else if document == nil {
+		document = map[string]interface{}{}
+	}
+	instance.loaded[abs] = document
+	return document, nil
+}
+
+func mergeProjectDocuments(path []string, target map[string]interface{}, source map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(target)+len(source))
+	for key, value := range target {
+		result[key] = value
+	}
+	for key, value := range source {
+		current := append(path[:len(path):len(path)], key)
+		if value == nil {
+			delete(result, key)
+		} else if s, ok := value.(map[string]interface{}); ok {
+			t, _ := result[key].(map[string]interface{})
+			result[key] = mergeProjectDocuments(current, t, s)
+		} else if s, ok := value.([]interface{}); ok && projectConcatenatedLists[strings.Join(current, ".")] {
+			t, _ := result[key].([]interface{})
+			result[key] = append(t[:len(t):len(t)], s...)
+		} else {
+			result[key] = value
+		}
+	}
+	return result
+}
+
+// projectDocumentValue decodes a YAML value while keeping the plain text of all keys.
+// Decoding into interface{} would turn keys like "on" into booleans which then would
+// no longer match the fields of the project.
+type projectDocumentValue struct {
+	value interface{}
+}
+
+func (instance *projectDocumentValue) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var plain interface{}
+	if err := unmarshal(&plain); err != nil {
+		return err
+	}
+	switch plain.(type) {
+	case map[interface{}]interface{}:
+		var m map[string]projectDocumentValue
+		if err := unmarshal(&m); err != nil {
+			return err
+		}
+		result := make(map[string]interface{}, len(m))
+		for key, value := range m {
+			result[key] = value.value
+		}
+		instance.value = result
+	case []interface{}:
+		var l []projectDocumentValue
+		if err := unmarshal(&l); err != nil {
+			return err
+		}
+		result := make([]interface{}, len(l))
+		for i, value := range l {
+			result[i] = value.value
+		}
+		instance.value = result
+	default:
+		instance.value = plain
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package model
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"path/filepath"
+	"testing"
+)
+
+func Test_resolveProjectDocument_merge_rules(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, root, "base/.kubor.yml", `groupId: shop
+artifactId: base
+release: "1"
+claim:
+  namespaces: [base]
+labels:
+  groupId: {action: set}
+  artifactId: {action: set}
+stages: [pre, deploy]
+valuesFiles: [base.yml]
+values:
+- on: ["{{.Env.A}}=.+"]
+  a: 1
+secrets:
+  files: [base.age]
+  valuesKey: base
+`)
+	writeTestFile(t, root, "shared.yml", `stages: [deploy]
+valuesFiles: [shared.yml]
+`)
+	file := writeTestFile(t, root, "project/.kubor.yml", `extends: ../base/.kubor.yml
+include: [../shared.yml]
+artifactId: svc
+release: ~
+labels:
+  artifactId: {action: leave}
+valuesFiles: [own.yml]
+secrets:
+  files: [own.age]
+`)
+
+	actual, bases, err := resolveProjectDocument(file)
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"groupId":    "shop",
+		"artifactId": "svc",
+		"claim":      map[string]interface{}{"namespaces": []interface{}{"base"}},
+		"labels": map[string]interface{}{
+			"groupId":    map[string]interface{}{"action": "set"},
+			"artifactId": map[string]interface{}{"action": "leave"},
+		},
+		"stages":      []interface{}{"deploy"},
+		"valuesFiles": []interface{}{"base.yml", "shared.yml", "own.yml"},
+		"values": []interface{}{
+			map[string]interface{}{"on": []interface{}{"{{.Env.A}}=.+"}, "a": 1},
+		},
+		"secrets": map[string]interface{}{
+			"files":     []interface{}{"base.age", "own.age"},
+			"valuesKey": "base",
+		},
+	}, actual)
+	assert.Equal(t, []string{
+		filepath.Join(root, "base", ".kubor.yml"),
+		filepath.Join(root, "shared.yml"),
+	}, bases)
+}
+
+func Test_resolveProjectDocument_diamond(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, root, "base.yml", "stages: [deploy]\nvaluesFiles: [base.yml]\nrelease: base\n")
+	writeTestFile(t, root, "a.yml", "extends: base.yml\nvaluesFiles: [a.yml]\nrelease: a\n")
+	writeTestFile(t, root, "b.yml", "extends: base.yml\nvaluesFiles: [b.yml]\n")
+	file := writeTestFile(t, root, ".kubor.yml", "include: [a.yml, b.yml]\nartifactId: test\n")
+
+	actual, bases, err := resolveProjectDocument(file)
+
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"base.yml", "a.yml", "b.yml"}, actual["valuesFiles"])
+	assert.Equal(t, "a", actual["release"], "shared base should not override a.yml again")
+	assert.Equal(t, []string{
+		filepath.Join(root, "base.yml"),
+		filepath.Join(root, "a.yml"),
+		filepath.Join(root, "b.yml"),
+	}, bases)
+}
+
+func Test_resolveProjectDocument_detects_cycles(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, root, "a.yml", "extends: b.yml\n")
+	writeTestFile(t, root, "b.yml", "include: [c.yml]\n")
+	writeTestFile(t, root, "c.yml", "extends: a.yml\n")
+	file := writeTestFile(t, root, ".kubor.yml", "extends: a.yml\nartifactId: test\n")
+
+	_, _, err := resolveProjectDocument(file)
+
+	assert.ErrorIs(t, err, ErrCyclicProjectIncludes)
+	assert.ErrorContains(t, err, filepath.Join(root, "a.yml")+" -> "+
+		filepath.Join(root, "b.yml")+" -> "+
+		filepath.Join(root, "c.yml")+" -> "+
+		filepath.Join(root, "a.yml"))
+}
+
+func Test_resolveProjectDocument_detects_self_include(t *testing.T) {
+	root := t.TempDir()
+	file := writeTestFile(t, root, ".kubor.yml", "include: .kubor.yml\nartifactId: test\n")
+
+	_, _, err := resolveProjectDocument(file)
+
+	assert.ErrorIs(t, err, ErrCyclicProjectIncludes)
+}
+
+func Test_resolveProjectDocument_fails(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, root, "list.yml", "- foo\n")
+	writeTestFile(t, root, "illegal.yml", "extends: {foo: bar}\n")
+
+	_, _, err := resolveProjectDocument(writeTestFile(t, root, "a.yml", "extends: missing.yml\n"))
+	assert.ErrorContains(t, err, "cannot read source file '"+filepath.Join(root, "missing.yml")+"'")
+
+	_, _, err = resolveProjectDocument(writeTestFile(t, root, "b.yml", "extends: list.yml\n"))
+	assert.ErrorContains(t, err, "expected a map at root")
+
+	_, _, err = resolveProjectDocument(filepath.Join(root, "illegal.yml"))
+	assert.ErrorContains(t, err, "illegal extends or include")
+}
+
+func Test_CheckProjectSource(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, root, "base.yml", "stags: [deploy]\n")
+	file := writeTestFile(t, root, ".kubor.yml", "extends: base.yml\nartifactId: test\nrelease: [1]\n")
+
+	actual := CheckProjectSource(file)
+
+	require.Len(t, actual, 2)
+	assert.ErrorContains(t, actual[0], "cannot unmarshal !!seq into string")
+	assert.ErrorContains(t, actual[1], "field stags not found")
+	assert.NotContains(t, actual[0].Error(), "line ")
+}
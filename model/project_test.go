@@ -0,0 +1,53 @@
+package model
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func Test_ProjectFactory_Create_DefaultNamespace(t *testing.T) {
+	cases := []struct {
+		name     string
+		source   string
+		expected Namespace
+	}{{
+		name:     "single claimed namespace",
+		source:   "groupId: shop\nartifactId: api\n",
+		expected: "shop",
+	}, {
+		name:     "single templated claimed namespace",
+		source:   "groupId: shop\nartifactId: api\nclaim:\n  namespaces: ['{{.ArtifactId}}-ns']\n",
+		expected: "api-ns",
+	}, {
+		name:     "multiple claimed namespaces",
+		source:   "groupId: shop\nartifactId: api\nclaim:\n  namespaces: [a, b]\n",
+		expected: "",
+	}, {
+		name:     "templated defaultNamespace",
+		source:   "groupId: shop\nartifactId: api\nclaim:\n  namespaces: [a, b]\ndefaultNamespace: '{{.GroupId}}-{{.ArtifactId}}'\n",
+		expected: "shop-api",
+	}, {
+		name:     "defaultNamespace wins over single claimed namespace",
+		source:   "groupId: shop\nartifactId: api\ndefaultNamespace: other\n",
+		expected: "other",
+	}}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			instance := &ProjectFactory{source: writeTestFile(t, t.TempDir(), ".kubor.yml", c.source)}
+
+			actual, err := instance.Create("")
+
+			require.NoError(t, err)
+			assert.Equal(t, c.expected, actual.DefaultNamespace)
+		})
+	}
+}
+
+func Test_ProjectFactory_Create_fails_on_illegal_DefaultNamespace(t *testing.T) {
+	instance := &ProjectFactory{source: writeTestFile(t, t.TempDir(), ".kubor.yml", "artifactId: api\ndefaultNamespace: '{{.GroupId'\n")}
+
+	_, err := instance.Create("")
+
+	assert.ErrorContains(t, err, "cannot handle defaultNamespace '{{.GroupId'")
+}
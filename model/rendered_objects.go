@@ -0,0 +1,40 @@
+package model
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// RenderedObjects holds all objects which were rendered from the templates of a project
+// during the current run. It allows transformations to look at other objects than the
+// one they transform.
+type RenderedObjects map[RenderedObjectKey]*unstructured.Unstructured
+
+// RenderedObjectKey identifies a rendered object by its kind, namespace and name. The
+// namespace is empty if the template did not define one.
+type RenderedObjectKey struct {
+	GroupKind schema.GroupKind
+	Namespace string
+	Name      string
+}
+
+// Add records the given object. A former object with the same key is replaced.
+func (instance *RenderedObjects) Add(object *unstructured.Unstructured) {
+	if *instance == nil {
+		*instance = RenderedObjects{}
+	}
+	(*instance)[RenderedObjectKey{
+		GroupKind: object.GroupVersionKind().GroupKind(),
+		Namespace: object.GetNamespace(),
+		Name:      object.GetName(),
+	}] = object
+}
+
+// Get returns the object of the given kind, namespace and name; nil if there is none.
+func (instance RenderedObjects) Get(groupKind schema.GroupKind, namespace, name string) *unstructured.Unstructured {
+	return instance[RenderedObjectKey{
+		GroupKind: groupKind,
+		Namespace: namespace,
+		Name:      name,
+	}]
+}
@@ -0,0 +1,185 @@
+package model
+
+import (
+	"bytes"
+	"filippo.io/age"
+	"github.com/ProtonMail/go-crypto/openpgp"
+	pgpArmor "github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"path/filepath"
+	"testing"
+)
+
+const testSecretsContent = "db:\n  password: geheim\n"
+
+func Test_Secrets_age_roundtrip(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	instance := Secrets{Recipients: []string{identity.Recipient().String()}}
+
+	encrypted, err := instance.Encrypt(t.TempDir(), []byte(testSecretsContent))
+	require.NoError(t, err)
+	assert.NotContains(t, string(encrypted), "geheim")
+	format, err := DetectSecretsFormat(encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, SecretsFormatAge, format)
+
+	keys, err := ParseSecretKeys([]byte("# comment\n"+identity.String()+"\n"), nil)
+	require.NoError(t, err)
+	decrypted, err := keys.Decrypt(encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, testSecretsContent, string(decrypted))
+}
+
+func Test_Secrets_age_with_wrong_identity(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	other, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	encrypted, err := Secrets{Recipients: []string{identity.Recipient().String()}}.Encrypt(t.TempDir(), []byte(testSecretsContent))
+	require.NoError(t, err)
+
+	_, err = SecretKeys{Age: []age.Identity{other}}.Decrypt(encrypted)
+
+	assert.Error(t, err)
+}
+
+func Test_Secrets_OpenPgp_roundtrip(t *testing.T) {
+	root := t.TempDir()
+	entity := newTestPgpEntity(t)
+	writeTestFile(t, root, "keys/public.asc", armoredPgpKey(t, pgpPublicKeyType, entity.Serialize))
+	instance := Secrets{Recipients: []string{"keys/public.asc"}}
+
+	encrypted, err := instance.Encrypt(root, []byte(testSecretsContent))
+	require.NoError(t, err)
+	format, err := DetectSecretsFormat(encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, SecretsFormatOpenPgp, format)
+
+	keys, err := ParseSecretKeys([]byte(armoredPgpKey(t, pgpPrivateKeyType, func(buf io.Writer) error {
+		return entity.SerializePrivate(buf, nil)
+	})), nil)
+	require.NoError(t, err)
+	decrypted, err := keys.Decrypt(encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, testSecretsContent, string(decrypted))
+}
+
+func Test_Secrets_OpenPgp_with_encrypted_private_key(t *testing.T) {
+	root := t.TempDir()
+	entity := newTestPgpEntity(t)
+	writeTestFile(t, root, "public.asc", armoredPgpKey(t, pgpPublicKeyType, entity.Serialize))
+	encrypted, err := Secrets{Recipients: []string{"public.asc"}}.Encrypt(root, []byte(testSecretsContent))
+	require.NoError(t, err)
+	require.NoError(t, entity.EncryptPrivateKeys([]byte("passphrase"), nil))
+	privateKey := []byte(armoredPgpKey(t, pgpPrivateKeyType, func(buf io.Writer) error {
+		return entity.SerializePrivateWithoutSigning(buf, nil)
+	}))
+
+	withoutPassphrase, err := ParseSecretKeys(privateKey, nil)
+	require.NoError(t, err)
+	_, err = withoutPassphrase.Decrypt(encrypted)
+	assert.ErrorContains(t, err, "KUBOR_SECRETS_PASSPHRASE")
+
+	withPassphrase, err := ParseSecretKeys(privateKey, []byte("passphrase"))
+	require.NoError(t, err)
+	decrypted, err := withPassphrase.Decrypt(encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, testSecretsContent, string(decrypted))
+}
+
+func Test_Secrets_Encrypt_fails(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	root := t.TempDir()
+	writeTestFile(t, root, "public.asc", armoredPgpKey(t, pgpPublicKeyType, newTestPgpEntity(t).Serialize))
+
+	_, err = Secrets{}.Encrypt(root, []byte(testSecretsContent))
+	assert.EqualError(t, err, "there are no secrets.recipients configured")
+
+	_, err = Secrets{Recipients: []string{identity.Recipient().String(), "public.asc"}}.Encrypt(root, []byte(testSecretsContent))
+	assert.EqualError(t, err, "age and OpenPGP recipients cannot be mixed")
+
+	_, err = Secrets{Recipients: []string{"missing.asc"}}.Encrypt(root, []byte(testSecretsContent))
+	assert.ErrorContains(t, err, "cannot read OpenPGP recipient 'missing.asc'")
+}
+
+func Test_SecretKeys_Decrypt_fails(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+
+	_, err = SecretKeys{}.Decrypt([]byte("whatever"))
+	assert.ErrorIs(t, err, ErrNoSecretKeys)
+
+	_, err = SecretKeys{Age: []age.Identity{identity}}.Decrypt([]byte(testSecretsContent))
+	assert.ErrorIs(t, err, ErrUnknownSecretsFile)
+}
+
+func Test_SecretKeys_ValuesFromSecretsFile(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	root := t.TempDir()
+	encrypted, err := Secrets{Recipients: []string{identity.Recipient().String()}}.Encrypt(root, []byte(testSecretsContent))
+	require.NoError(t, err)
+	file := writeTestFile(t, root, "secrets.yml.age", string(encrypted))
+
+	actual, err := SecretKeys{Age: []age.Identity{identity}}.ValuesFromSecretsFile(file)
+
+	require.NoError(t, err)
+	assert.Equal(t, Values{"db": map[string]interface{}{"password": "geheim"}}, actual)
+
+	_, err = SecretKeys{Age: []age.Identity{identity}}.ValuesFromSecretsFile(filepath.Join(root, "missing"))
+	assert.ErrorContains(t, err, "cannot read secrets file")
+}
+
+const (
+	pgpPublicKeyType  = "PGP PUBLIC KEY BLOCK"
+	pgpPrivateKeyType = "PGP PRIVATE KEY BLOCK"
+)
+
+func newTestPgpEntity(t *testing.T) *openpgp.Entity {
+	t.Helper()
+	result, err := openpgp.NewEntity("test", "", "test@example.org", nil)
+	require.NoError(t, err)
+	return result
+}
+
+func armoredPgpKey(t *testing.T, blockType string, serialize func(io.Writer) error) string {
+	t.Helper()
+	raw := new(bytes.Buffer)
+	require.NoError(t, serialize(raw))
+	buf := new(bytes.Buffer)
+	w, err := pgpArmor.Encode(buf, blockType, nil)
+	require.NoError(t, err)
+	_, err = w.Write(raw.Bytes())
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return buf.String()
+}
+
+func Test_ProjectFactory_CreateWithoutSecrets_does_not_affect_Create(t *testing.T) {
+	t.Setenv("KUBOR_SECRETS_KEY", "")
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	root := t.TempDir()
+	encrypted, err := Secrets{Recipients: []string{identity.Recipient().String()}}.Encrypt(root, []byte(testSecretsContent))
+	require.NoError(t, err)
+	writeTestFile(t, root, "secrets.yml.age", string(encrypted))
+	writeTestFile(t, root, "identity.txt", identity.String())
+	writeTestFile(t, root, ".kubor.yml", "artifactId: test\nsecrets:\n  files: [secrets.yml.age]\n")
+	instance := &ProjectFactory{source: filepath.Join(root, ".kubor.yml")}
+
+	withoutSecrets, err := instance.CreateWithoutSecrets("")
+	require.NoError(t, err)
+	assert.Equal(t, Values{}, withoutSecrets.Values)
+
+	_, err = instance.Create("")
+	assert.ErrorIs(t, err, ErrNoSecretKeys)
+
+	instance.secretsKeyFile = filepath.Join(root, "identity.txt")
+	withSecrets, err := instance.Create("")
+	require.NoError(t, err)
+	assert.Equal(t, Values{"secrets": map[string]interface{}{"db": map[string]interface{}{"password": "geheim"}}}, withSecrets.Values)
+}
@@ -3,6 +3,7 @@ package model
 import (
 	"bytes"
 	"fmt"
+	"github.com/echocat/kubor/template"
 	"github.com/echocat/kubor/template/functions"
 	"io"
 	"path/filepath"
@@ -11,6 +12,7 @@ import (
 
 type Templating struct {
 	TemplateFilePattern []string `yaml:"templateFilePattern" json:"templateFilePattern"`
+	LibraryPattern      []string `yaml:"libraryPattern,omitempty" json:"libraryPattern,omitempty"`
 }
 
 func NewTemplating() Templating {
@@ -19,16 +21,65 @@ func NewTemplating() Templating {
 			"?{{ .Root }}/kubernetes/templates/*.yml",
 			"?{{ .Root }}/kubernetes/templates/*.yaml",
 		},
+		LibraryPattern: []string{
+			"?{{ .Root }}/kubernetes/templates/_*.tpl",
+		},
 	}
 }
 
 func (instance Templating) TemplateFiles(data interface{}) ([]string, error) {
-	return instance.renderFiles(instance.TemplateFilePattern, "template", data)
+	candidates, err := instance.renderFiles(instance.TemplateFilePattern, "template", data)
+	if err != nil {
+		return nil, err
+	}
+	libraryFiles, err := instance.LibraryFiles(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(libraryFiles) == 0 {
+		return candidates, nil
+	}
+	isLibraryFile := make(map[string]bool, len(libraryFiles))
+	for _, libraryFile := range libraryFiles {
+		isLibraryFile[libraryFile] = true
+	}
+	result := make([]string, 0, len(candidates))
+	for _, candidate := range candidates {
+		if !isLibraryFile[candidate] {
+			result = append(result, candidate)
+		}
+	}
+	return result, nil
+}
+
+func (instance Templating) LibraryFiles(data interface{}) ([]string, error) {
+	return instance.renderFiles(instance.LibraryPattern, "library", data)
+}
+
+// TemplateFactory creates a new template.Factory which provides every definition
+// of the library files (see LibraryPattern) to all of its created templates.
+func (instance Templating) TemplateFactory(data interface{}) (template.Factory, error) {
+	files, err := instance.LibraryFiles(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return functions.DefaultTemplateFactory(), nil
+	}
+	library := functions.DefaultTemplateLibrary()
+	for _, file := range files {
+		if err := library.AddFromFile(file); err != nil {
+			return nil, err
+		}
+	}
+	return functions.DefaultTemplateFactoryWithLibrary(library), nil
 }
 
 func (instance Templating) RenderedTemplatesProvider(data interface{}) (ContentProvider, error) {
 	if files, err := instance.TemplateFiles(data); err != nil {
 		return nil, err
+	} else if factory, err := instance.TemplateFactory(data); err != nil {
+		return nil, err
 	} else {
 		i := 0
 		return func() (string, []byte, error) {
@@ -38,7 +89,7 @@ func (instance Templating) RenderedTemplatesProvider(data interface{}) (ContentP
 			buf := new(bytes.Buffer)
 			file := files[i]
 			i++
-			if err := instance.RenderTemplateFile(file, data, buf); err != nil {
+			if err := instance.renderTemplateFileWith(factory, file, data, buf); err != nil {
 				return file, nil, err
 			}
 			return file, buf.Bytes(), nil
@@ -47,7 +98,15 @@ func (instance Templating) RenderedTemplatesProvider(data interface{}) (ContentP
 }
 
 func (instance Templating) RenderTemplateFile(file string, data interface{}, writer io.Writer) error {
-	if tmpl, err := functions.DefaultTemplateFactory().NewFromFile(file); err != nil {
+	if factory, err := instance.TemplateFactory(data); err != nil {
+		return err
+	} else {
+		return instance.renderTemplateFileWith(factory, file, data, writer)
+	}
+}
+
+func (instance Templating) renderTemplateFileWith(factory template.Factory, file string, data interface{}, writer io.Writer) error {
+	if tmpl, err := factory.NewFromFile(file); err != nil {
 		return fmt.Errorf("cannot parse template file '%s': %w", file, err)
 	} else if err := tmpl.Execute(data, writer); err != nil {
 		return fmt.Errorf("cannot render template file '%s': %w", file, err)
@@ -3,6 +3,11 @@ package model
 type Transformation struct {
 	Enabled  *bool   `yaml:"enabled,omitempty" json:"enabled,omitempty"`
 	Argument *string `yaml:"argument,omitempty" json:"argument,omitempty"`
+	// Patch declares a custom transformation; only allowed inside of the project.
+	Patch *TransformationPatch `yaml:"patch,omitempty" json:"patch,omitempty"`
+	// Plugin declares a custom transformation implemented by a local executable; only
+	// allowed inside of the project.
+	Plugin *TransformationPlugin `yaml:"plugin,omitempty" json:"plugin,omitempty"`
 }
 
 func (instance Transformation) Merge(with Transformation) Transformation {
@@ -14,6 +19,12 @@ func (instance Transformation) Merge(with Transformation) Transformation {
 	if v := with.Argument; v != nil {
 		result.Argument = v
 	}
+	if v := with.Patch; v != nil {
+		result.Patch = v
+	}
+	if v := with.Plugin; v != nil {
+		result.Plugin = v
+	}
 
 	return result
 }
@@ -0,0 +1,327 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/echocat/kubor/common"
+	"github.com/echocat/kubor/template/functions"
+	"gopkg.in/yaml.v2"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"regexp"
+)
+
+var (
+	ErrIllegalTransformationPatchType = errors.New("illegal transformation patch type")
+	ErrIllegalTransformationEvent     = errors.New("illegal transformation event")
+)
+
+// TransformationPatch declares a transformation of a project which patches all
+// selected objects while they are created and/or updated.
+//
+//	transformations:
+//	  add-sidecar:
+//	    patch:
+//	      type: strategic
+//	      priority: 100
+//	      on: [create, update]
+//	      selector:
+//	        gvks: [{group: apps, version: v1, kind: Deployment}]
+//	        name: "web-.*"
+//	      content:
+//	        spec:
+//	          template:
+//	            spec:
+//	              containers:
+//	              - name: sidecar
+//	                image: "{{ .Values.sidecar.image }}"
+type TransformationPatch struct {
+	Type TransformationPatchType `yaml:"type" json:"type"`
+	// Content is the patch itself. If it is a string it is rendered as template and
+	// the result parsed as YAML; otherwise every string inside it is rendered as
+	// template. Templates have access to the project (like .Values), the .Object
+	// which is patched and the .Argument of the transformation.
+	Content TransformationPatchContent `yaml:"content" json:"content"`
+	// Priority defines when this patch is applied relative to all other
+	// transformations. The built-in transformations have a priority of 0 (except
+	// image-policy, apply-annotations and apply-labels, which are applied at the very
+	// end).
+	Priority int32 `yaml:"priority,omitempty" json:"priority,omitempty"`
+	// On defines the events this patch is applied on. Empty means all events.
+	On       TransformationEvents   `yaml:"on,omitempty" json:"on,omitempty"`
+	Selector TransformationSelector `yaml:"selector,omitempty" json:"selector,omitempty"`
+}
+
+func (instance TransformationPatch) Validate() error {
+	if _, err := instance.Type.MarshalText(); err != nil {
+		return err
+	}
+	if instance.Content.IsEmpty() {
+		return fmt.Errorf("patch content should not be empty")
+	}
+	for _, event := range instance.On {
+		if _, err := event.MarshalText(); err != nil {
+			return err
+		}
+	}
+	return instance.Selector.Validate()
+}
+
+type transformationPatchData struct {
+	Project
+	Object   map[string]interface{}
+	Argument string
+}
+
+// Render renders the content of this patch for the given target and returns it as JSON.
+func (instance TransformationPatch) Render(project Project, target *unstructured.Unstructured, argument *string) ([]byte, error) {
+	data := transformationPatchData{
+		Project: project,
+		Object:  target.Object,
+	}
+	if argument != nil {
+		data.Argument = *argument
+	}
+	value, err := instance.Content.render(data)
+	if err != nil {
+		return nil, err
+	}
+	switch instance.Type {
+	case TransformationPatchTypeJson:
+		if _, ok := value.([]interface{}); !ok {
+			return nil, fmt.Errorf("%v patch has to be a list of operations", instance.Type)
+		}
+	default:
+		if _, ok := value.(map[string]interface{}); !ok {
+			return nil, fmt.Errorf("%v patch has to be an object", instance.Type)
+		}
+	}
+	return json.Marshal(value)
+}
+
+// TransformationPatchContent holds either a Template or a structured Value.
+type TransformationPatchContent struct {
+	Template string
+	Value    interface{}
+}
+
+func (instance TransformationPatchContent) IsEmpty() bool {
+	return instance.Template == "" && instance.Value == nil
+}
+
+func (instance *TransformationPatchContent) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var plain interface{}
+	if err := unmarshal(&plain); err != nil {
+		return err
+	}
+	if str, ok := plain.(string); ok {
+		*instance = TransformationPatchContent{Template: str}
+	} else {
+		*instance = TransformationPatchContent{Value: normalizeValue(plain)}
+	}
+	return nil
+}
+
+func (instance TransformationPatchContent) MarshalYAML() (interface{}, error) {
+	if instance.Template != "" {
+		return instance.Template, nil
+	}
+	return instance.Value, nil
+}
+
+func (instance *TransformationPatchContent) UnmarshalJSON(b []byte) error {
+	var plain interface{}
+	if err := json.Unmarshal(b, &plain); err != nil {
+		return err
+	}
+	if str, ok := plain.(string); ok {
+		*instance = TransformationPatchContent{Template: str}
+	} else {
+		*instance = TransformationPatchContent{Value: plain}
+	}
+	return nil
+}
+
+func (instance TransformationPatchContent) MarshalJSON() ([]byte, error) {
+	if instance.Template != "" {
+		return json.Marshal(instance.Template)
+	}
+	return json.Marshal(instance.Value)
+}
+
+func (instance TransformationPatchContent) render(data interface{}) (interface{}, error) {
+	if instance.Template == "" {
+		return renderStrings(instance.Value, data)
+	}
+	var plain interface{}
+	if tmpl, err := functions.DefaultTemplateFactory().New("patch", instance.Template); err != nil {
+		return nil, err
+	} else if rendered, err := tmpl.ExecuteToString(data); err != nil {
+		return nil, err
+	} else if err := yaml.Unmarshal([]byte(rendered), &plain); err != nil {
+		return nil, fmt.Errorf("cannot parse rendered patch: %w", err)
+	}
+	return normalizeValue(plain), nil
+}
+
+func renderStrings(value interface{}, data interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		if tmpl, err := functions.DefaultTemplateFactory().New(v, v); err != nil {
+			return nil, err
+		} else {
+			return tmpl.ExecuteToString(data)
+		}
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, element := range v {
+			rendered, err := renderStrings(element, data)
+			if err != nil {
+				return nil, err
+			}
+			result[key] = rendered
+		}
+		return result, nil
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, element := range v {
+			rendered, err := renderStrings(element, data)
+			if err != nil {
+				return nil, err
+			}
+			result[i] = rendered
+		}
+		return result, nil
+	default:
+		return v, nil
+	}
+}
+
+// TransformationSelector selects the objects a TransformationPatch is applied to. All
+// of its (non-empty) criteria have to match.
+type TransformationSelector struct {
+	GroupVersionKinds GroupVersionKinds `yaml:"gvks,omitempty" json:"gvks,omitempty"`
+	// Name is a regular expression the name of the object has to match completely.
+	Name      string                     `yaml:"name,omitempty" json:"name,omitempty"`
+	Predicate common.EvaluatingPredicate `yaml:"predicate,omitempty" json:"predicate,omitempty"`
+}
+
+func (instance TransformationSelector) Validate() error {
+	if _, err := instance.nameRegexp(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (instance TransformationSelector) Matches(target *unstructured.Unstructured) (bool, error) {
+	if !instance.GroupVersionKinds.Contains(GroupVersionKind(target.GroupVersionKind())) {
+		return false, nil
+	}
+	if r, err := instance.nameRegexp(); err != nil {
+		return false, err
+	} else if r != nil && !r.MatchString(target.GetName()) {
+		return false, nil
+	}
+	return instance.Predicate.Matches(target.Object)
+}
+
+func (instance TransformationSelector) nameRegexp() (*regexp.Regexp, error) {
+	if instance.Name == "" {
+		return nil, nil
+	}
+	r, err := regexp.Compile("^(?:" + instance.Name + ")$")
+	if err != nil {
+		return nil, fmt.Errorf("illegal name pattern '%s': %w", instance.Name, err)
+	}
+	return r, nil
+}
+
+type TransformationPatchType string
+
+const (
+	// TransformationPatchTypeJson is a JSON Patch (RFC 6902).
+	TransformationPatchTypeJson = TransformationPatchType("json")
+	// TransformationPatchTypeMerge is a JSON Merge Patch (RFC 7386).
+	TransformationPatchTypeMerge = TransformationPatchType("merge")
+	// TransformationPatchTypeStrategic is a strategic merge patch as known from
+	// kubectl patch; it is only supported for kinds built into Kubernetes.
+	TransformationPatchTypeStrategic = TransformationPatchType("strategic")
+)
+
+func (instance *TransformationPatchType) Set(plain string) error {
+	return instance.UnmarshalText([]byte(plain))
+}
+
+func (instance TransformationPatchType) String() string {
+	v, _ := instance.MarshalText()
+	return string(v)
+}
+
+func (instance TransformationPatchType) MarshalText() (text []byte, err error) {
+	switch instance {
+	case TransformationPatchTypeJson, TransformationPatchTypeMerge, TransformationPatchTypeStrategic:
+		return []byte(instance), nil
+	default:
+		return []byte(fmt.Sprintf("illegal-transformation-patch-type-%s", string(instance))),
+			fmt.Errorf("%w: %s", ErrIllegalTransformationPatchType, string(instance))
+	}
+}
+
+func (instance *TransformationPatchType) UnmarshalText(text []byte) error {
+	v := TransformationPatchType(text)
+	if _, err := v.MarshalText(); err != nil {
+		return err
+	}
+	*instance = v
+	return nil
+}
+
+type TransformationEvent string
+
+const (
+	TransformationEventCreate = TransformationEvent("create")
+	TransformationEventUpdate = TransformationEvent("update")
+)
+
+func (instance *TransformationEvent) Set(plain string) error {
+	return instance.UnmarshalText([]byte(plain))
+}
+
+func (instance TransformationEvent) String() string {
+	v, _ := instance.MarshalText()
+	return string(v)
+}
+
+func (instance TransformationEvent) MarshalText() (text []byte, err error) {
+	switch instance {
+	case TransformationEventCreate, TransformationEventUpdate:
+		return []byte(instance), nil
+	default:
+		return []byte(fmt.Sprintf("illegal-transformation-event-%s", string(instance))),
+			fmt.Errorf("%w: %s", ErrIllegalTransformationEvent, string(instance))
+	}
+}
+
+func (instance *TransformationEvent) UnmarshalText(text []byte) error {
+	v := TransformationEvent(text)
+	if _, err := v.MarshalText(); err != nil {
+		return err
+	}
+	*instance = v
+	return nil
+}
+
+type TransformationEvents []TransformationEvent
+
+// Contains returns true if the given event is contained or if this instance is empty.
+func (instance TransformationEvents) Contains(event TransformationEvent) bool {
+	if len(instance) == 0 {
+		return true
+	}
+	for _, candidate := range instance {
+		if candidate == event {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,102 @@
+package model
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+)
+
+const (
+	DefaultTransformationPluginTimeout = 30 * time.Second
+)
+
+// TransformationPlugin declares a transformation of a project which is implemented by
+// a local executable. The executable receives a request as JSON on stdin and has to
+// write a response as JSON to stdout (see package
+// github.com/echocat/kubor/kubernetes/transformation/plugin for the protocol).
+//
+//	transformations:
+//	  company-policies:
+//	    plugin:
+//	      command: ./hack/company-policies
+//	      args: [--strict]
+//	      priority: 100
+//	      timeout: 10s
+//	      on: [create, update]
+//	      selector:
+//	        gvks: [{group: apps, version: v1, kind: Deployment}]
+type TransformationPlugin struct {
+	// Command is the executable; relative paths are resolved against the directory
+	// of the project file.
+	Command string   `yaml:"command" json:"command"`
+	Args    []string `yaml:"args,omitempty" json:"args,omitempty"`
+	// Priority defines when this plugin is executed relative to all other
+	// transformations (see TransformationPatch.Priority).
+	Priority int32 `yaml:"priority,omitempty" json:"priority,omitempty"`
+	// Timeout is the maximum duration of one execution. Default is
+	// DefaultTransformationPluginTimeout.
+	Timeout Duration `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+	// On defines the events this plugin is executed on. Empty means all events.
+	On       TransformationEvents   `yaml:"on,omitempty" json:"on,omitempty"`
+	Selector TransformationSelector `yaml:"selector,omitempty" json:"selector,omitempty"`
+	// PassSecrets defines if the values are sent to the plugin as they are. By default
+	// secret values (see ValuesProvenance.IsSecret) are masked, because the plugin is
+	// not part of kubor and could hand them to anyone.
+	PassSecrets bool `yaml:"passSecrets,omitempty" json:"passSecrets,omitempty"`
+}
+
+func (instance TransformationPlugin) Validate() error {
+	if instance.Command == "" {
+		return fmt.Errorf("plugin command should not be empty")
+	}
+	if instance.Timeout < 0 {
+		return fmt.Errorf("plugin timeout should not be negative")
+	}
+	for _, event := range instance.On {
+		if _, err := event.MarshalText(); err != nil {
+			return err
+		}
+	}
+	return instance.Selector.Validate()
+}
+
+// ResolveCommand returns the location of the executable for the given project.
+func (instance TransformationPlugin) ResolveCommand(project Project) string {
+	if filepath.IsAbs(instance.Command) || filepath.Base(instance.Command) == instance.Command {
+		// Absolute or to be looked up in PATH.
+		return instance.Command
+	}
+	return filepath.Join(project.Root, instance.Command)
+}
+
+// GetTimeout returns Timeout or DefaultTransformationPluginTimeout if not set.
+func (instance TransformationPlugin) GetTimeout() time.Duration {
+	if instance.Timeout > 0 {
+		return time.Duration(instance.Timeout)
+	}
+	return DefaultTransformationPluginTimeout
+}
+
+// Duration is a time.Duration which is represented like "10s" or "1m30s".
+type Duration time.Duration
+
+func (instance *Duration) Set(plain string) error {
+	return instance.UnmarshalText([]byte(plain))
+}
+
+func (instance Duration) String() string {
+	return time.Duration(instance).String()
+}
+
+func (instance Duration) MarshalText() (text []byte, err error) {
+	return []byte(instance.String()), nil
+}
+
+func (instance *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return fmt.Errorf("illegal duration: %w", err)
+	}
+	*instance = Duration(v)
+	return nil
+}
@@ -1,5 +1,7 @@
 package model
 
+import "fmt"
+
 type Transformations map[TransformationName]Transformation
 
 func NewTransformations() Transformations {
@@ -12,3 +14,30 @@ func (instance Transformations) Get(name TransformationName) (result Transformat
 	}
 	return
 }
+
+func (instance Transformations) Validate() error {
+	for name, transformation := range instance {
+		if transformation.Patch == nil && transformation.Plugin == nil {
+			// Only the names of patches and plugins are checked to keep the settings of
+			// existing transformations working under every name they were declared with.
+			continue
+		}
+		if _, err := name.MarshalText(); err != nil {
+			return err
+		}
+		if transformation.Patch != nil && transformation.Plugin != nil {
+			return fmt.Errorf("transformation %v: either patch or plugin could be declared", name)
+		}
+		if v := transformation.Plugin; v != nil {
+			if err := v.Validate(); err != nil {
+				return fmt.Errorf("transformation %v: %w", name, err)
+			}
+		}
+		if v := transformation.Patch; v != nil {
+			if err := v.Validate(); err != nil {
+				return fmt.Errorf("transformation %v: %w", name, err)
+			}
+		}
+	}
+	return nil
+}
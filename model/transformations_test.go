@@ -0,0 +1,15 @@
+package model
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_Transformations_Validate(t *testing.T) {
+	plugin := &TransformationPlugin{Command: "./plugin"}
+
+	assert.NoError(t, Transformations{"Legacy_Name": {Enabled: new(false)}}.Validate(), "settings are accepted under every name")
+	assert.NoError(t, Transformations{"company-policies": {Plugin: plugin}}.Validate())
+	assert.ErrorIs(t, Transformations{"Company_Policies": {Plugin: plugin}}.Validate(), ErrIllegalTransformationName)
+	assert.ErrorContains(t, Transformations{"company-policies": {Plugin: &TransformationPlugin{}}}.Validate(), "plugin command should not be empty")
+}
@@ -1,49 +1,185 @@
 package model
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/echocat/kubor/common"
+	"github.com/echocat/kubor/template/functions"
+	"gopkg.in/yaml.v2"
+	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 )
 
+var (
+	ErrIllegalValue = errors.New("illegal value")
+)
+
 type Values map[string]interface{}
 
 func NewValues() Values {
 	return Values{}
 }
 
-func (instance Values) MergeWith(input ...Values) Values {
+// ValuesFromFile reads the values from the given YAML or JSON file.
+func ValuesFromFile(file string) (Values, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read values file '%s': %w", file, err)
+	}
+	var plain map[interface{}]interface{}
+	if err := yaml.Unmarshal(content, &plain); err != nil {
+		return nil, fmt.Errorf("cannot read values file '%s': %w", file, err)
+	}
 	result := Values{}
-	if instance != nil {
-		for k, v := range instance {
-			result[k] = v
-		}
+	for key, value := range plain {
+		result[fmt.Sprint(key)] = normalizeValue(value)
 	}
-	for _, values := range input {
-		for key, value := range values {
-			result[key] = value
+	return result, nil
+}
+
+// MergeWith merges all given inputs deeply into a copy of this instance using the
+// default ValuesMerge: Maps are merged recursively, a null value removes the key and
+// every other value of a later input replaces the existing one.
+func (instance Values) MergeWith(input ...Values) Values {
+	return ValuesMerge{}.Merge(instance, input...)
+}
+
+// normalizeValue converts every map (like the ones created by the YAML decoder) into
+// map[string]interface{} to be able to merge them with each other.
+func normalizeValue(in interface{}) interface{} {
+	switch v := in.(type) {
+	case Values:
+		return normalizeValue(map[string]interface{}(v))
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			result[key] = normalizeValue(value)
+		}
+		return result
+	case map[interface{}]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			result[fmt.Sprint(key)] = normalizeValue(value)
 		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, value := range v {
+			result[i] = normalizeValue(value)
+		}
+		return result
+	default:
+		return in
 	}
-	return result
 }
 
 func (instance *Values) IsCumulative() bool {
 	return true
 }
 
+// Set sets a value in format <path>[:<type>]=[<value>]. <path> could be a dotted path
+// (like "image.tag") to address nested values; dots which are part of a key could be
+// escaped using "\.". <type> could be one of string (default), int, float, bool, json or yaml.
+// A value which is null (like "<path>:yaml=~") removes the key while merging the values.
 func (instance *Values) Set(value string) error {
-	parts := strings.SplitN(value, "=", 2)
+	plainKey, plainValue, _ := strings.Cut(value, "=")
 	if *instance == nil {
 		*instance = Values{}
 	}
-	if len(parts) > 1 {
-		(*instance)[parts[0]] = parts[1]
+	path, valueType := parseValuePath(plainKey)
+	if len(path) == 0 {
+		return fmt.Errorf("%w: %s", ErrIllegalValue, value)
+	}
+	if parsed, err := parseTypedValue(valueType, plainValue); err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrIllegalValue, value, err)
 	} else {
-		(*instance)[parts[0]] = ""
+		instance.SetPath(path, parsed)
 	}
 	return nil
 }
 
+// SetPath sets the given value at the given path. Missing parent maps will be created;
+// parents which are not maps will be replaced.
+func (instance *Values) SetPath(path []string, value interface{}) {
+	if *instance == nil {
+		*instance = Values{}
+	}
+	current := map[string]interface{}(*instance)
+	for _, element := range path[:len(path)-1] {
+		next, ok := current[element].(map[string]interface{})
+		if !ok {
+			next = normalizeMapOrNil(current[element])
+			if next == nil {
+				next = map[string]interface{}{}
+			}
+			current[element] = next
+		}
+		current = next
+	}
+	current[path[len(path)-1]] = normalizeValue(value)
+}
+
+func normalizeMapOrNil(in interface{}) map[string]interface{} {
+	if result, ok := normalizeValue(in).(map[string]interface{}); ok {
+		return result
+	}
+	return nil
+}
+
+func parseValuePath(plain string) (path []string, valueType string) {
+	if i := strings.LastIndexByte(plain, ':'); i >= 0 {
+		switch candidate := plain[i+1:]; candidate {
+		case "string", "int", "float", "bool", "json", "yaml":
+			plain, valueType = plain[:i], candidate
+		}
+	}
+	if plain == "" {
+		return nil, valueType
+	}
+	var current strings.Builder
+	for i := 0; i < len(plain); i++ {
+		c := plain[i]
+		if c == '\\' && i+1 < len(plain) && plain[i+1] == '.' {
+			current.WriteByte('.')
+			i++
+		} else if c == '.' {
+			path = append(path, current.String())
+			current.Reset()
+		} else {
+			current.WriteByte(c)
+		}
+	}
+	return append(path, current.String()), valueType
+}
+
+func parseTypedValue(valueType string, plain string) (interface{}, error) {
+	switch valueType {
+	case "int":
+		return strconv.ParseInt(plain, 10, 64)
+	case "float":
+		return strconv.ParseFloat(plain, 64)
+	case "bool":
+		return strconv.ParseBool(plain)
+	case "json":
+		var result interface{}
+		if err := json.Unmarshal([]byte(plain), &result); err != nil {
+			return nil, err
+		}
+		return result, nil
+	case "yaml":
+		var result interface{}
+		if err := yaml.Unmarshal([]byte(plain), &result); err != nil {
+			return nil, err
+		}
+		return normalizeValue(result), nil
+	default:
+		return plain, nil
+	}
+}
+
 // String returns a readable representation of this value (for usage defaults)
 func (instance *Values) String() string {
 	return fmt.Sprintf("%s", *instance)
@@ -62,3 +198,33 @@ type ConditionalValues struct {
 func NewConditionalValuesSlice() []ConditionalValues {
 	return []ConditionalValues{}
 }
+
+// ValuesFile is the location of a file the values are loaded from. It could be a
+// template; if it renders to an empty string it is ignored. Relative paths are
+// resolved against the root of the project. If prefixed with "?" it is optional.
+type ValuesFile string
+
+// Resolve returns the rendered location of this file. An empty string is returned
+// if this file should be ignored.
+func (instance ValuesFile) Resolve(root string, data interface{}) (string, error) {
+	optional := strings.HasPrefix(string(instance), "?")
+	plain := strings.TrimPrefix(string(instance), "?")
+	if tmpl, err := functions.DefaultTemplateFactory().New(plain, plain); err != nil {
+		return "", fmt.Errorf("cannot handle values file '%s': %w", instance, err)
+	} else if rendered, err := tmpl.ExecuteToString(data); err != nil {
+		return "", fmt.Errorf("cannot handle values file '%s': %w", instance, err)
+	} else if rendered = strings.TrimSpace(rendered); rendered == "" {
+		// Ignore ... could happen if we use {{ if }} clauses
+		return "", nil
+	} else {
+		if !filepath.IsAbs(rendered) {
+			rendered = filepath.Join(root, rendered)
+		}
+		if _, err := os.Stat(rendered); os.IsNotExist(err) && optional {
+			return "", nil
+		}
+		return rendered, nil
+	}
+}
+
+type ValuesFiles []ValuesFile
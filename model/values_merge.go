@@ -0,0 +1,189 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	ListMergeReplace    = ListMergeStrategy("replace")
+	ListMergeAppend     = ListMergeStrategy("append")
+	ListMergeMergeByKey = ListMergeStrategy("mergeByKey")
+
+	defaultListMergeKey = "name"
+)
+
+var (
+	ErrIllegalListMergeStrategy = errors.New("illegal list merge strategy")
+)
+
+// ListMergeStrategy defines how a list of a later values source is combined with the
+// list of a former one. It could be "replace" (default), "append" or "mergeByKey".
+// mergeByKey merges all elements (which have to be maps) that have the same value at
+// key "name". Another key could be selected using "mergeByKey:<key>".
+type ListMergeStrategy string
+
+func (instance *ListMergeStrategy) Set(plain string) error {
+	return instance.UnmarshalText([]byte(plain))
+}
+
+func (instance ListMergeStrategy) String() string {
+	if v, err := instance.MarshalText(); err != nil {
+		return fmt.Sprintf("illegal-list-merge-strategy-%s", string(instance))
+	} else {
+		return string(v)
+	}
+}
+
+func (instance ListMergeStrategy) MarshalText() (text []byte, err error) {
+	if !instance.isValid() {
+		return nil, fmt.Errorf("%w: %s", ErrIllegalListMergeStrategy, string(instance))
+	}
+	return []byte(instance), nil
+}
+
+func (instance *ListMergeStrategy) UnmarshalText(text []byte) error {
+	v := ListMergeStrategy(text)
+	if !v.isValid() {
+		return fmt.Errorf("%w: %s", ErrIllegalListMergeStrategy, string(text))
+	}
+	*instance = v
+	return nil
+}
+
+// Mode returns the strategy without a possible key.
+func (instance ListMergeStrategy) Mode() ListMergeStrategy {
+	mode, _, _ := strings.Cut(string(instance), ":")
+	if mode == "" {
+		return ListMergeReplace
+	}
+	return ListMergeStrategy(mode)
+}
+
+// Key returns the key elements are identified by if Mode() is ListMergeMergeByKey.
+func (instance ListMergeStrategy) Key() string {
+	if _, key, ok := strings.Cut(string(instance), ":"); ok && key != "" {
+		return key
+	}
+	return defaultListMergeKey
+}
+
+func (instance ListMergeStrategy) isValid() bool {
+	switch instance.Mode() {
+	case ListMergeReplace, ListMergeAppend:
+		return !strings.Contains(string(instance), ":")
+	case ListMergeMergeByKey:
+		return !strings.HasSuffix(string(instance), ":")
+	}
+	return false
+}
+
+// ValuesMerge configures how values of different sources (defaults, values files,
+// conditional values and CLI) are merged with each other. Maps are always merged
+// deeply; a key with a null (or ~) value removes the key from the result.
+type ValuesMerge struct {
+	// Lists is the strategy for every list which is not configured by Paths.
+	Lists ListMergeStrategy `yaml:"lists,omitempty" json:"lists,omitempty"`
+	// Paths configures the strategy for lists at the given dotted paths (like "ingress.hosts").
+	// Elements of lists are not part of the path: "containers.env" addresses the env list of
+	// every element of the containers list.
+	Paths map[string]ListMergeStrategy `yaml:"paths,omitempty" json:"paths,omitempty"`
+}
+
+// Merge merges all given inputs deeply into a copy of base.
+func (instance ValuesMerge) Merge(base Values, input ...Values) Values {
+	result := instance.mergeMaps(nil, nil, base)
+	for _, values := range input {
+		result = instance.mergeMaps(nil, result, values)
+	}
+	return result
+}
+
+func (instance ValuesMerge) strategyFor(path []string) ListMergeStrategy {
+	if v, ok := instance.Paths[strings.Join(path, ".")]; ok {
+		return v
+	}
+	return instance.Lists
+}
+
+func (instance ValuesMerge) mergeMaps(path []string, target map[string]interface{}, source map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(target)+len(source))
+	for key, value := range target {
+		result[key] = value
+	}
+	for key, value := range source {
+		if value == nil {
+			delete(result, key)
+			continue
+		}
+		result[key] = instance.mergeValue(append(path[:len(path):len(path)], key), result[key], value)
+	}
+	return result
+}
+
+func (instance ValuesMerge) mergeValue(path []string, target interface{}, source interface{}) interface{} {
+	source = normalizeValue(source)
+	switch s := source.(type) {
+	case map[string]interface{}:
+		t, _ := target.(map[string]interface{})
+		return instance.mergeMaps(path, t, s)
+	case []interface{}:
+		t, ok := target.([]interface{})
+		if !ok {
+			return instance.mergeLists(path, ListMergeReplace, nil, s)
+		}
+		return instance.mergeLists(path, instance.strategyFor(path), t, s)
+	default:
+		return source
+	}
+}
+
+func (instance ValuesMerge) mergeLists(path []string, strategy ListMergeStrategy, target []interface{}, source []interface{}) []interface{} {
+	switch strategy.Mode() {
+	case ListMergeAppend:
+		result := make([]interface{}, 0, len(target)+len(source))
+		result = append(result, target...)
+		for _, element := range source {
+			result = append(result, instance.mergeValue(path, nil, element))
+		}
+		return result
+	case ListMergeMergeByKey:
+		key := strategy.Key()
+		result := make([]interface{}, 0, len(target)+len(source))
+		result = append(result, target...)
+		for _, element := range source {
+			if i := indexOfListElementByKey(result, key, element); i >= 0 {
+				result[i] = instance.mergeValue(path, result[i], element)
+			} else {
+				result = append(result, instance.mergeValue(path, nil, element))
+			}
+		}
+		return result
+	default:
+		result := make([]interface{}, len(source))
+		for i, element := range source {
+			result[i] = instance.mergeValue(path, nil, element)
+		}
+		return result
+	}
+}
+
+func indexOfListElementByKey(in []interface{}, key string, element interface{}) int {
+	em, ok := element.(map[string]interface{})
+	if !ok {
+		return -1
+	}
+	ev, ok := em[key]
+	if !ok || ev == nil {
+		return -1
+	}
+	for i, candidate := range in {
+		if cm, ok := candidate.(map[string]interface{}); ok {
+			if cv, ok := cm[key]; ok && fmt.Sprint(cv) == fmt.Sprint(ev) {
+				return i
+			}
+		}
+	}
+	return -1
+}
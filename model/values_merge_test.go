@@ -0,0 +1,175 @@
+package model
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_ValuesMerge_Merge(t *testing.T) {
+	list := func(elements ...interface{}) []interface{} { return elements }
+	named := func(name string, more ...interface{}) map[string]interface{} {
+		result := map[string]interface{}{"name": name}
+		for i := 0; i+1 < len(more); i += 2 {
+			result[more[i].(string)] = more[i+1]
+		}
+		return result
+	}
+
+	cases := []struct {
+		name     string
+		merge    ValuesMerge
+		base     Values
+		input    []Values
+		expected Values
+	}{{
+		name:     "maps are merged deeply",
+		base:     Values{"image": map[string]interface{}{"repository": "nginx", "tag": "1"}, "replicas": 1},
+		input:    []Values{{"image": map[string]interface{}{"tag": "2"}}},
+		expected: Values{"image": map[string]interface{}{"repository": "nginx", "tag": "2"}, "replicas": 1},
+	}, {
+		name:     "later inputs win",
+		base:     Values{"replicas": 1},
+		input:    []Values{{"replicas": 2}, {"replicas": 3}},
+		expected: Values{"replicas": 3},
+	}, {
+		name:     "null deletes key",
+		base:     Values{"image": map[string]interface{}{"repository": "nginx", "tag": "1"}, "replicas": 1},
+		input:    []Values{{"image": map[string]interface{}{"tag": nil}, "replicas": nil}},
+		expected: Values{"image": map[string]interface{}{"repository": "nginx"}},
+	}, {
+		name:     "null of absent key is ignored",
+		base:     Values{"replicas": 1},
+		input:    []Values{{"missing": nil}},
+		expected: Values{"replicas": 1},
+	}, {
+		name:     "scalar replaces map",
+		base:     Values{"image": map[string]interface{}{"tag": "1"}},
+		input:    []Values{{"image": "nginx:1"}},
+		expected: Values{"image": "nginx:1"},
+	}, {
+		name:     "map replaces scalar",
+		base:     Values{"image": "nginx:1"},
+		input:    []Values{{"image": map[string]interface{}{"tag": "1"}}},
+		expected: Values{"image": map[string]interface{}{"tag": "1"}},
+	}, {
+		name:     "lists are replaced by default",
+		base:     Values{"hosts": list("a", "b")},
+		input:    []Values{{"hosts": list("c")}},
+		expected: Values{"hosts": list("c")},
+	}, {
+		name:     "replace",
+		merge:    ValuesMerge{Lists: ListMergeReplace},
+		base:     Values{"hosts": list("a", "b")},
+		input:    []Values{{"hosts": list("c")}},
+		expected: Values{"hosts": list("c")},
+	}, {
+		name:     "append",
+		merge:    ValuesMerge{Lists: ListMergeAppend},
+		base:     Values{"hosts": list("a", "b")},
+		input:    []Values{{"hosts": list("c")}, {"hosts": list("d")}},
+		expected: Values{"hosts": list("a", "b", "c", "d")},
+	}, {
+		name:     "append to absent list",
+		merge:    ValuesMerge{Lists: ListMergeAppend},
+		base:     Values{},
+		input:    []Values{{"hosts": list("a")}},
+		expected: Values{"hosts": list("a")},
+	}, {
+		name:  "mergeByKey",
+		merge: ValuesMerge{Lists: ListMergeMergeByKey},
+		base: Values{"containers": list(
+			named("app", "image", "app:1", "cpu", "100m"),
+			named("sidecar", "image", "sidecar:1"),
+		)},
+		input: []Values{{"containers": list(
+			named("app", "image", "app:2", "cpu", nil),
+			named("extra", "image", "extra:1"),
+		)}},
+		expected: Values{"containers": list(
+			named("app", "image", "app:2"),
+			named("sidecar", "image", "sidecar:1"),
+			named("extra", "image", "extra:1"),
+		)},
+	}, {
+		name:  "mergeByKey with custom key",
+		merge: ValuesMerge{Lists: "mergeByKey:host"},
+		base:  Values{"ingress": list(map[string]interface{}{"host": "a", "path": "/"})},
+		input: []Values{{"ingress": list(map[string]interface{}{"host": "a", "path": "/api"}, map[string]interface{}{"host": "b"})}},
+		expected: Values{"ingress": list(
+			map[string]interface{}{"host": "a", "path": "/api"},
+			map[string]interface{}{"host": "b"},
+		)},
+	}, {
+		name:     "mergeByKey appends elements without key",
+		merge:    ValuesMerge{Lists: ListMergeMergeByKey},
+		base:     Values{"items": list(named("a"), "plain")},
+		input:    []Values{{"items": list("plain", map[string]interface{}{"other": 1})}},
+		expected: Values{"items": list(named("a"), "plain", "plain", map[string]interface{}{"other": 1})},
+	}, {
+		name: "paths override default strategy",
+		merge: ValuesMerge{
+			Lists: ListMergeAppend,
+			Paths: map[string]ListMergeStrategy{"ingress.hosts": ListMergeReplace},
+		},
+		base:     Values{"ingress": map[string]interface{}{"hosts": list("a")}, "args": list("-v")},
+		input:    []Values{{"ingress": map[string]interface{}{"hosts": list("b")}, "args": list("-x")}},
+		expected: Values{"ingress": map[string]interface{}{"hosts": list("b")}, "args": list("-v", "-x")},
+	}, {
+		name: "paths inside of list elements",
+		merge: ValuesMerge{
+			Lists: ListMergeMergeByKey,
+			Paths: map[string]ListMergeStrategy{"containers.args": ListMergeAppend},
+		},
+		base:     Values{"containers": list(named("app", "args", list("-v")))},
+		input:    []Values{{"containers": list(named("app", "args", list("-x")))}},
+		expected: Values{"containers": list(named("app", "args", list("-v", "-x")))},
+	}}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			actual := c.merge.Merge(c.base, c.input...)
+
+			assert.Equal(t, c.expected, actual)
+		})
+	}
+}
+
+func Test_ValuesMerge_Merge_does_not_modify_inputs(t *testing.T) {
+	base := Values{"image": map[string]interface{}{"tag": "1"}, "hosts": []interface{}{"a"}}
+	input := Values{"image": map[string]interface{}{"tag": "2"}, "hosts": []interface{}{"b"}}
+
+	ValuesMerge{Lists: ListMergeAppend}.Merge(base, input)
+
+	assert.Equal(t, Values{"image": map[string]interface{}{"tag": "1"}, "hosts": []interface{}{"a"}}, base)
+	assert.Equal(t, Values{"image": map[string]interface{}{"tag": "2"}, "hosts": []interface{}{"b"}}, input)
+}
+
+func Test_ListMergeStrategy_Set(t *testing.T) {
+	cases := []struct {
+		plain        string
+		expectedMode ListMergeStrategy
+		expectedKey  string
+	}{
+		{"replace", ListMergeReplace, "name"},
+		{"append", ListMergeAppend, "name"},
+		{"mergeByKey", ListMergeMergeByKey, "name"},
+		{"mergeByKey:host", ListMergeMergeByKey, "host"},
+	}
+	for _, c := range cases {
+		t.Run(c.plain, func(t *testing.T) {
+			var actual ListMergeStrategy
+
+			assert.NoError(t, actual.Set(c.plain))
+
+			assert.Equal(t, c.expectedMode, actual.Mode())
+			assert.Equal(t, c.expectedKey, actual.Key())
+		})
+	}
+
+	for _, plain := range []string{"foo", "append:name", "mergeByKey:"} {
+		t.Run(plain, func(t *testing.T) {
+			var actual ListMergeStrategy
+
+			assert.ErrorIs(t, actual.Set(plain), ErrIllegalListMergeStrategy)
+		})
+	}
+}
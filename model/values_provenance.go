@@ -0,0 +1,249 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	ValueSourceDefault     = ValueSourceKind("default")
+	ValueSourceValuesFile  = ValueSourceKind("valuesFile")
+	ValueSourceWorkspace   = ValueSourceKind("workspace")
+	ValueSourceSecretsFile = ValueSourceKind("secretsFile")
+	ValueSourceConditional = ValueSourceKind("conditional")
+	ValueSourceCli         = ValueSourceKind("cli")
+)
+
+const secretValueMask = "*****"
+
+var (
+	// secretValueWords are the words of a path (see valuePathWords) which makes the
+	// value secret. Words are compared case-insensitively.
+	secretValueWords = map[string]bool{
+		"pass": true, "passwd": true, "password": true, "passwords": true,
+		"secret": true, "secrets": true,
+		"token": true, "tokens": true,
+		"credential": true, "credentials": true,
+		"auth": true, "apikey": true, "privatekey": true,
+	}
+	// secretValueWordPairs are like secretValueWords but consist of two adjacent words
+	// like in "api_key" or "privateKey".
+	secretValueWordPairs = map[[2]string]bool{
+		{"api", "key"}:     true,
+		{"private", "key"}: true,
+	}
+)
+
+// ValueSourceKind describes what kind of source has set a value.
+type ValueSourceKind string
+
+func (instance ValueSourceKind) String() string {
+	return string(instance)
+}
+
+// ValueSource describes one source which has set (or deleted) a value.
+type ValueSource struct {
+	Kind ValueSourceKind `yaml:"kind" json:"kind"`
+	// Reference describes the source more precisely, like the values file or the condition.
+	Reference string `yaml:"reference,omitempty" json:"reference,omitempty"`
+	// Deleted is true if this source has removed the value using null.
+	Deleted bool `yaml:"deleted,omitempty" json:"deleted,omitempty"`
+}
+
+func (instance ValueSource) String() string {
+	result := instance.Kind.String()
+	if instance.Reference != "" {
+		result += " " + instance.Reference
+	}
+	if instance.Deleted {
+		result += " (deleted)"
+	}
+	return result
+}
+
+// ValuesProvenance records for every path (see ValuePathOf) of the values the
+// ordered list of sources which have set or overridden it.
+type ValuesProvenance map[string][]ValueSource
+
+// Record adds the given source to every leaf path of the given values.
+func (instance ValuesProvenance) Record(source ValueSource, values Values) {
+	walkValueLeafs(nil, map[string]interface{}(values), func(path []string, value interface{}) {
+		s := source
+		s.Deleted = value == nil
+		key := ValuePathOf(path)
+		instance[key] = append(instance[key], s)
+	})
+}
+
+// RecordAdded adds the given source in front of every leaf path which exists
+// in after but not in before.
+func (instance ValuesProvenance) RecordAdded(source ValueSource, before Values, after Values) {
+	existing := map[string]bool{}
+	walkValueLeafs(nil, map[string]interface{}(before), func(path []string, _ interface{}) {
+		existing[ValuePathOf(path)] = true
+	})
+	walkValueLeafs(nil, map[string]interface{}(after), func(path []string, _ interface{}) {
+		if key := ValuePathOf(path); !existing[key] {
+			instance[key] = append([]ValueSource{source}, instance[key]...)
+		}
+	})
+}
+
+// Explain returns for every path which is either part of the given values or of this
+// provenance the final value together with its sources, sorted by path.
+func (instance ValuesProvenance) Explain(values Values, maskSecrets bool) []ExplainedValue {
+	byPath := map[string]*ExplainedValue{}
+	walkValueLeafs(nil, map[string]interface{}(values), func(path []string, value interface{}) {
+		key := ValuePathOf(path)
+		explained := &ExplainedValue{
+			Path:    key,
+			Value:   value,
+			Present: true,
+		}
+		if maskSecrets {
+			explained.Value = instance.maskSecretsOf(path, value)
+			explained.Masked = instance.IsSecret(key)
+		}
+		byPath[key] = explained
+	})
+	for key, sources := range instance {
+		if _, ok := byPath[key]; !ok {
+			byPath[key] = &ExplainedValue{Path: key}
+		}
+		byPath[key].Sources = sources
+	}
+	result := make([]ExplainedValue, 0, len(byPath))
+	for _, candidate := range byPath {
+		result = append(result, *candidate)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Path < result[j].Path
+	})
+	return result
+}
+
+// IsSecret returns true if the given path looks like a secret (like "db.password",
+// "dbPassword" or "api_key" but not "author") or was set by a secrets file.
+func (instance ValuesProvenance) IsSecret(path string) bool {
+	words := valuePathWords(path)
+	for i, word := range words {
+		if secretValueWords[word] {
+			return true
+		}
+		if i+1 < len(words) && secretValueWordPairs[[2]string{word, words[i+1]}] {
+			return true
+		}
+	}
+	for _, source := range instance[path] {
+		if source.Kind == ValueSourceSecretsFile {
+			return true
+		}
+	}
+	return false
+}
+
+// valuePathWords splits the given path into lower case words. Words are separated by
+// every character which is neither a letter nor a digit (like the dots between the
+// elements of the path) and by camel case.
+func valuePathWords(path string) []string {
+	var result []string
+	var current []rune
+	flush := func() {
+		if len(current) > 0 {
+			result = append(result, strings.ToLower(string(current)))
+			current = current[:0]
+		}
+	}
+	runes := []rune(path)
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			flush()
+			continue
+		}
+		if unicode.IsUpper(r) && len(current) > 0 {
+			previous := current[len(current)-1]
+			// Split "dbPassword" before "P" and "APIKey" before "K".
+			if !unicode.IsUpper(previous) || (i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+				flush()
+			}
+		}
+		current = append(current, r)
+	}
+	flush()
+	return result
+}
+
+// MaskSecrets returns a copy of the given values where every value which is
+// secret (see IsSecret) is replaced by a mask. Elements of lists are addressed by
+// their index (like "users.0.password").
+func (instance ValuesProvenance) MaskSecrets(values Values) Values {
+	return instance.maskSecretsOfMap(nil, values)
+}
+
+func (instance ValuesProvenance) maskSecretsOfMap(prefix []string, values map[string]interface{}) Values {
+	result := Values{}
+	walkValueLeafs(prefix, values, func(path []string, value interface{}) {
+		result.SetPath(path[len(prefix):], instance.maskSecretsOf(path, value))
+	})
+	return result
+}
+
+// maskSecretsOf returns the mask if the given path is secret. Otherwise it returns the
+// value itself where lists are masked element by element.
+func (instance ValuesProvenance) maskSecretsOf(path []string, value interface{}) interface{} {
+	if instance.IsSecret(ValuePathOf(path)) {
+		return secretValueMask
+	}
+	list, ok := normalizeValue(value).([]interface{})
+	if !ok {
+		return value
+	}
+	for i, element := range list {
+		elementPath := append(path[:len(path):len(path)], strconv.Itoa(i))
+		if m, ok := element.(map[string]interface{}); ok {
+			list[i] = map[string]interface{}(instance.maskSecretsOfMap(elementPath, m))
+		} else {
+			list[i] = instance.maskSecretsOf(elementPath, element)
+		}
+	}
+	return list
+}
+
+// ExplainedValue is the final value of a path together with all sources which has set it.
+type ExplainedValue struct {
+	Path    string        `yaml:"path" json:"path"`
+	Value   interface{}   `yaml:"value,omitempty" json:"value,omitempty"`
+	Present bool          `yaml:"present" json:"present"`
+	Masked  bool          `yaml:"masked,omitempty" json:"masked,omitempty"`
+	Sources []ValueSource `yaml:"sources" json:"sources"`
+}
+
+// ValuePathOf creates a dotted path (as it is used by -v) out of the given elements.
+func ValuePathOf(elements []string) string {
+	escaped := make([]string, len(elements))
+	for i, element := range elements {
+		escaped[i] = strings.ReplaceAll(element, ".", `\.`)
+	}
+	return strings.Join(escaped, ".")
+}
+
+func walkValueLeafs(path []string, values map[string]interface{}, consumer func(path []string, value interface{})) {
+	for key, value := range values {
+		current := append(path[:len(path):len(path)], key)
+		if m, ok := normalizeValue(value).(map[string]interface{}); ok && len(m) > 0 {
+			walkValueLeafs(current, m, consumer)
+		} else {
+			consumer(current, value)
+		}
+	}
+}
+
+func conditionalValueSourceReference(index int, candidate ConditionalValues) string {
+	if patterns := candidate.On.Patterns(); len(patterns) > 0 {
+		return fmt.Sprintf("values[%d] (on: %s)", index, strings.Join(patterns, ", "))
+	}
+	return fmt.Sprintf("values[%d]", index)
+}
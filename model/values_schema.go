@@ -0,0 +1,252 @@
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"gopkg.in/yaml.v2"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ValuesSchema is the location of a JSON Schema (draft 2020-12) file, written as YAML
+// or JSON, which the values of a project are validated against. A relative location is
+// resolved against the root of the project.
+type ValuesSchema string
+
+// Load reads and compiles the schema. If this instance is empty nil is returned.
+func (instance ValuesSchema) Load(root string) (*ValuesSchemaDocument, error) {
+	if instance == "" {
+		return nil, nil
+	}
+	file := string(instance)
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(root, file)
+	}
+	fail := func(err error) (*ValuesSchemaDocument, error) {
+		return nil, fmt.Errorf("cannot load values schema '%s': %w", instance, err)
+	}
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return fail(err)
+	}
+	var plain interface{}
+	if err := yaml.Unmarshal(content, &plain); err != nil {
+		return fail(err)
+	}
+	document := normalizeValue(plain)
+	raw, err := toJsonValue(document)
+	if err != nil {
+		return fail(err)
+	}
+	location, err := filepath.Abs(file)
+	if err != nil {
+		return fail(err)
+	}
+	compiler := jsonschema.NewCompiler()
+	compiler.DefaultDraft(jsonschema.Draft2020)
+	if err := compiler.AddResource(location, raw); err != nil {
+		return fail(err)
+	}
+	schema, err := compiler.Compile(location)
+	if err != nil {
+		return fail(err)
+	}
+	return &ValuesSchemaDocument{
+		Source:   file,
+		Document: document,
+		schema:   schema,
+	}, nil
+}
+
+// ValuesSchemaDocument is a loaded and compiled ValuesSchema.
+type ValuesSchemaDocument struct {
+	Source   string
+	Document interface{}
+
+	schema *jsonschema.Schema
+}
+
+// Validate validates the given values against this schema. If there are violations
+// ValuesSchemaViolations is returned.
+func (instance *ValuesSchemaDocument) Validate(values Values) error {
+	raw, err := toJsonValue(map[string]interface{}(values))
+	if err != nil {
+		return fmt.Errorf("cannot validate values against values schema '%s': %w", instance.Source, err)
+	}
+	err = instance.schema.Validate(raw)
+	if ve, ok := err.(*jsonschema.ValidationError); ok {
+		violations := ValuesSchemaViolations{Source: instance.Source}
+		violations.collect(ve)
+		sort.SliceStable(violations.Violations, func(i, j int) bool {
+			return violations.Violations[i].Pointer < violations.Violations[j].Pointer
+		})
+		return violations
+	} else if err != nil {
+		return fmt.Errorf("cannot validate values against values schema '%s': %w", instance.Source, err)
+	}
+	return nil
+}
+
+// ApplyDefaults returns a copy of the given values where every absent property which
+// has a "default" inside of this schema is set to this default.
+func (instance *ValuesSchemaDocument) ApplyDefaults(values Values) Values {
+	result := ValuesMerge{}.Merge(values)
+	if applied, ok := instance.applyDefaults(instance.Document, map[string]interface{}(result), 0).(map[string]interface{}); ok {
+		return applied
+	}
+	return result
+}
+
+// WithDefaults returns a copy of the schema document where the "default" of every
+// property which is present in the given values is set to its value there. Properties
+// are followed into nested "properties" but not through "$ref"s; the default of such
+// a property contains all of its nested values.
+func (instance *ValuesSchemaDocument) WithDefaults(values Values) interface{} {
+	return withSchemaDefaults(normalizeValue(instance.Document), map[string]interface{}(values))
+}
+
+func withSchemaDefaults(schema interface{}, values map[string]interface{}) interface{} {
+	s, ok := schema.(map[string]interface{})
+	if !ok {
+		return schema
+	}
+	properties, ok := s["properties"].(map[string]interface{})
+	if !ok {
+		return schema
+	}
+	for name, property := range properties {
+		p, ok := property.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		value, exists := values[name]
+		if !exists {
+			continue
+		}
+		p["default"] = normalizeValue(value)
+		if nested, ok := value.(map[string]interface{}); ok {
+			withSchemaDefaults(p, nested)
+		}
+	}
+	return schema
+}
+
+// maxSchemaReferenceDepth prevents endless recursions while following "$ref"s.
+const maxSchemaReferenceDepth = 32
+
+func (instance *ValuesSchemaDocument) applyDefaults(schema interface{}, value interface{}, depth int) interface{} {
+	s, ok := schema.(map[string]interface{})
+	if !ok || depth > maxSchemaReferenceDepth {
+		return value
+	}
+	if ref, ok := s["$ref"].(string); ok {
+		value = instance.applyDefaults(instance.resolveReference(ref), value, depth+1)
+	}
+	if all, ok := s["allOf"].([]interface{}); ok {
+		for _, candidate := range all {
+			value = instance.applyDefaults(candidate, value, depth+1)
+		}
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		properties, _ := s["properties"].(map[string]interface{})
+		for name, property := range properties {
+			current, exists := v[name]
+			if !exists {
+				if p, ok := property.(map[string]interface{}); ok {
+					if def, ok := p["default"]; ok {
+						current, exists = normalizeValue(def), true
+					}
+				}
+			}
+			if exists {
+				v[name] = instance.applyDefaults(property, current, depth)
+			}
+		}
+	case []interface{}:
+		for i, element := range v {
+			v[i] = instance.applyDefaults(s["items"], element, depth)
+		}
+	}
+	return value
+}
+
+func (instance *ValuesSchemaDocument) resolveReference(ref string) interface{} {
+	if !strings.HasPrefix(ref, "#") {
+		return nil
+	}
+	var current interface{} = instance.Document
+	for _, element := range strings.Split(strings.TrimPrefix(ref[1:], "/"), "/") {
+		if element == "" {
+			continue
+		}
+		element = strings.ReplaceAll(strings.ReplaceAll(element, "~1", "/"), "~0", "~")
+		if m, ok := current.(map[string]interface{}); ok {
+			current = m[element]
+		} else {
+			return nil
+		}
+	}
+	return current
+}
+
+// ValuesSchemaViolation describes one location inside of the values which does not match the schema.
+type ValuesSchemaViolation struct {
+	// Pointer is the JSON pointer (RFC 6901) to the violating value.
+	Pointer string
+	Message string
+}
+
+func (instance ValuesSchemaViolation) String() string {
+	return fmt.Sprintf("#%s: %s", instance.Pointer, instance.Message)
+}
+
+// ValuesSchemaViolations is returned by ValuesSchemaDocument.Validate and holds all violations.
+type ValuesSchemaViolations struct {
+	Source     string
+	Violations []ValuesSchemaViolation
+}
+
+func (instance ValuesSchemaViolations) Error() string {
+	buf := new(bytes.Buffer)
+	_, _ = fmt.Fprintf(buf, "values do not match values schema '%s':", instance.Source)
+	for _, violation := range instance.Violations {
+		_, _ = fmt.Fprintf(buf, "\n\t%v", violation)
+	}
+	return buf.String()
+}
+
+var valuesSchemaMessagePrinter = message.NewPrinter(language.English)
+
+func (instance *ValuesSchemaViolations) collect(ve *jsonschema.ValidationError) {
+	if len(ve.Causes) > 0 {
+		for _, cause := range ve.Causes {
+			instance.collect(cause)
+		}
+		return
+	}
+	pointer := ""
+	for _, element := range ve.InstanceLocation {
+		pointer += "/" + strings.ReplaceAll(strings.ReplaceAll(element, "~", "~0"), "/", "~1")
+	}
+	instance.Violations = append(instance.Violations, ValuesSchemaViolation{
+		Pointer: pointer,
+		Message: ve.ErrorKind.LocalizedString(valuesSchemaMessagePrinter),
+	})
+}
+
+// toJsonValue converts the given value into a value as it would be created by
+// the JSON decoder (which is required by the validator).
+func toJsonValue(in interface{}) (interface{}, error) {
+	if b, err := json.Marshal(in); err != nil {
+		return nil, err
+	} else {
+		return jsonschema.UnmarshalJSON(bytes.NewReader(b))
+	}
+}
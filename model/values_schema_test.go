@@ -0,0 +1,168 @@
+package model
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"path/filepath"
+	"testing"
+)
+
+const testValuesSchema = `$schema: https://json-schema.org/draft/2020-12/schema
+type: object
+additionalProperties: false
+required: [image]
+$defs:
+  port:
+    type: object
+    properties:
+      protocol: {type: string, default: TCP}
+      port: {type: integer}
+properties:
+  replicas: {type: integer, minimum: 1, default: 2}
+  image:
+    type: object
+    properties:
+      repository: {type: string, default: nginx}
+      tag: {type: string}
+    required: [tag]
+  ports:
+    type: array
+    items: {$ref: "#/$defs/port"}
+  "a/b": {type: string}
+`
+
+func loadTestValuesSchema(t *testing.T) *ValuesSchemaDocument {
+	t.Helper()
+	root := t.TempDir()
+	writeTestFile(t, root, "values.schema.yml", testValuesSchema)
+	result, err := ValuesSchema("values.schema.yml").Load(root)
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	return result
+}
+
+func Test_ValuesSchema_Load_of_empty(t *testing.T) {
+	actual, err := ValuesSchema("").Load(t.TempDir())
+
+	assert.NoError(t, err)
+	assert.Nil(t, actual)
+}
+
+func Test_ValuesSchema_Load_fails(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, root, "broken.yml", "type: 1\n")
+
+	_, err := ValuesSchema("missing.yml").Load(root)
+	assert.ErrorContains(t, err, "cannot load values schema 'missing.yml'")
+
+	_, err = ValuesSchema("broken.yml").Load(root)
+	assert.ErrorContains(t, err, "cannot load values schema 'broken.yml'")
+}
+
+func Test_ValuesSchemaDocument_ApplyDefaults(t *testing.T) {
+	instance := loadTestValuesSchema(t)
+	values := Values{
+		"image": map[string]interface{}{"tag": "1.2.3"},
+		"ports": []interface{}{
+			map[string]interface{}{"port": 80},
+			map[string]interface{}{"port": 53, "protocol": "UDP"},
+		},
+	}
+
+	actual := instance.ApplyDefaults(values)
+
+	assert.Equal(t, Values{
+		"replicas": 2,
+		"image":    map[string]interface{}{"repository": "nginx", "tag": "1.2.3"},
+		"ports": []interface{}{
+			map[string]interface{}{"port": 80, "protocol": "TCP"},
+			map[string]interface{}{"port": 53, "protocol": "UDP"},
+		},
+	}, actual)
+	assert.Equal(t, Values{
+		"image": map[string]interface{}{"tag": "1.2.3"},
+		"ports": []interface{}{
+			map[string]interface{}{"port": 80},
+			map[string]interface{}{"port": 53, "protocol": "UDP"},
+		},
+	}, values, "input should not be modified")
+}
+
+func Test_ValuesSchemaDocument_ApplyDefaults_keeps_existing(t *testing.T) {
+	instance := loadTestValuesSchema(t)
+
+	actual := instance.ApplyDefaults(Values{"replicas": 5})
+
+	assert.Equal(t, Values{"replicas": 5}, actual, "absent objects should not be created")
+}
+
+func Test_ValuesSchemaDocument_Validate(t *testing.T) {
+	instance := loadTestValuesSchema(t)
+
+	assert.NoError(t, instance.Validate(Values{"image": map[string]interface{}{"tag": "1"}, "replicas": 1}))
+}
+
+func Test_ValuesSchemaDocument_Validate_reports_all_violations_with_pointers(t *testing.T) {
+	instance := loadTestValuesSchema(t)
+
+	err := instance.Validate(Values{
+		"replicas": 0,
+		"image":    map[string]interface{}{"repository": 1},
+		"ports":    []interface{}{map[string]interface{}{"port": "http"}},
+		"a/b":      1,
+		"typo":     true,
+	})
+
+	var violations ValuesSchemaViolations
+	require.ErrorAs(t, err, &violations)
+	assert.Equal(t, filepath.Base(instance.Source), filepath.Base(violations.Source))
+	var pointers []string
+	for _, violation := range violations.Violations {
+		pointers = append(pointers, violation.Pointer)
+	}
+	assert.Equal(t, []string{"", "/a~1b", "/image", "/image/repository", "/ports/0/port", "/replicas"}, pointers)
+	assert.ErrorContains(t, err, "#/replicas: ")
+	assert.ErrorContains(t, err, "typo")
+}
+
+func Test_ValuesSchemaDocument_WithDefaults(t *testing.T) {
+	instance := loadTestValuesSchema(t)
+
+	actual := instance.WithDefaults(Values{
+		"replicas": 3,
+		"image":    map[string]interface{}{"repository": "nginx", "tag": "1.2.3"},
+	})
+
+	properties := actual.(map[string]interface{})["properties"].(map[string]interface{})
+	assert.Equal(t, 3, properties["replicas"].(map[string]interface{})["default"])
+	image := properties["image"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"repository": "nginx", "tag": "1.2.3"}, image["default"])
+	imageProperties := image["properties"].(map[string]interface{})
+	assert.Equal(t, "nginx", imageProperties["repository"].(map[string]interface{})["default"])
+	assert.Equal(t, "1.2.3", imageProperties["tag"].(map[string]interface{})["default"])
+	assert.NotContains(t, properties["ports"], "default")
+
+	original := instance.Document.(map[string]interface{})["properties"].(map[string]interface{})
+	assert.Equal(t, 2, original["replicas"].(map[string]interface{})["default"], "document should not be modified")
+	assert.NotContains(t, original["image"], "default", "document should not be modified")
+}
+
+func Test_ProjectFactory_validates_values_against_schema(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, root, "values.schema.yml", testValuesSchema)
+	writeTestFile(t, root, ".kubor.yml", "artifactId: test\nvaluesSchema: values.schema.yml\nvalues:\n- replicas: 0\n")
+	instance := &ProjectFactory{source: filepath.Join(root, ".kubor.yml")}
+
+	_, err := instance.Create("")
+
+	var violations ValuesSchemaViolations
+	require.ErrorAs(t, err, &violations)
+	assert.ErrorContains(t, err, "#/replicas: ")
+
+	actual, err := instance.CreateToleratingViolations("")
+
+	require.NoError(t, err)
+	require.NotNil(t, actual.ValuesSchemaViolations)
+	assert.Equal(t, violations, *actual.ValuesSchemaViolations)
+	assert.Equal(t, Values{"replicas": 0}, actual.Values)
+}
@@ -0,0 +1,133 @@
+package model
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_Values_Set(t *testing.T) {
+	cases := []struct {
+		plain    string
+		expected Values
+	}{
+		{"foo=bar", Values{"foo": "bar"}},
+		{"foo=", Values{"foo": ""}},
+		{"foo=a=b", Values{"foo": "a=b"}},
+		{"image.tag=1.2.3", Values{"image": map[string]interface{}{"tag": "1.2.3"}}},
+		{`annotations.example\.org/owner=team`, Values{"annotations": map[string]interface{}{"example.org/owner": "team"}}},
+		{"replicas:int=3", Values{"replicas": int64(3)}},
+		{"ratio:float=0.5", Values{"ratio": 0.5}},
+		{"enabled:bool=true", Values{"enabled": true}},
+		{"port:string=8080", Values{"port": "8080"}},
+		{`ports:json=[80,443]`, Values{"ports": []interface{}{float64(80), float64(443)}}},
+		{"resources:yaml={cpu: 100m}", Values{"resources": map[string]interface{}{"cpu": "100m"}}},
+		{"removed:yaml=~", Values{"removed": nil}},
+		{"url=http://foo:8080", Values{"url": "http://foo:8080"}},
+	}
+	for _, c := range cases {
+		t.Run(c.plain, func(t *testing.T) {
+			var actual Values
+
+			require.NoError(t, actual.Set(c.plain))
+
+			assert.Equal(t, c.expected, actual)
+		})
+	}
+}
+
+func Test_Values_Set_merges_into_existing(t *testing.T) {
+	var actual Values
+
+	require.NoError(t, actual.Set("image.repository=nginx"))
+	require.NoError(t, actual.Set("image.tag=1.2.3"))
+	require.NoError(t, actual.Set("name=foo"))
+	require.NoError(t, actual.Set("name.first=bar"))
+
+	assert.Equal(t, Values{
+		"image": map[string]interface{}{"repository": "nginx", "tag": "1.2.3"},
+		"name":  map[string]interface{}{"first": "bar"},
+	}, actual)
+}
+
+func Test_Values_Set_fails(t *testing.T) {
+	for _, plain := range []string{"=foo", ":int=1", "replicas:int=three", "enabled:bool=maybe", "foo:json={"} {
+		t.Run(plain, func(t *testing.T) {
+			var actual Values
+
+			assert.ErrorIs(t, actual.Set(plain), ErrIllegalValue)
+		})
+	}
+}
+
+func Test_ValuesFromFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "values.yml")
+	require.NoError(t, os.WriteFile(file, []byte("image:\n  tag: 1.2.3\n  ports: [{name: http, port: 80}]\n1: one\n"), 0644))
+
+	actual, err := ValuesFromFile(file)
+
+	require.NoError(t, err)
+	assert.Equal(t, Values{
+		"image": map[string]interface{}{
+			"tag":   "1.2.3",
+			"ports": []interface{}{map[string]interface{}{"name": "http", "port": 80}},
+		},
+		"1": "one",
+	}, actual)
+}
+
+func Test_ValuesFromFile_fails(t *testing.T) {
+	_, err := ValuesFromFile(filepath.Join(t.TempDir(), "missing.yml"))
+	assert.ErrorContains(t, err, "cannot read values file")
+}
+
+func Test_ProjectFactory_layers_values(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, root, ".kubor.yml", `artifactId: test
+valuesFiles:
+- base.yml
+- "{{ .Values.env }}.yml"
+- ?missing.yml
+`)
+	writeTestFile(t, root, "base.yml", "env: prod\nimage: {repository: nginx, tag: base}\nreplicas: 1\nname: base\n")
+	writeTestFile(t, root, "prod.yml", "image: {tag: prod}\nreplicas: 2\n")
+	writeTestFile(t, root, "cli.yml", "replicas: 3\nname: cli-file\n")
+	instance := &ProjectFactory{
+		source:      filepath.Join(root, ".kubor.yml"),
+		valuesFiles: []string{filepath.Join(root, "cli.yml")},
+	}
+	require.NoError(t, instance.values.Set("name=cli"))
+
+	actual, err := instance.Create("")
+
+	require.NoError(t, err)
+	assert.Equal(t, Values{
+		"env":      "prod",
+		"image":    map[string]interface{}{"repository": "nginx", "tag": "prod"},
+		"replicas": 3,
+		"name":     "cli",
+	}, actual.Values)
+}
+
+func Test_ProjectFactory_uses_cli_values_to_resolve_values_files(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, root, ".kubor.yml", "artifactId: test\nvaluesFiles:\n- \"{{ .Values.env }}.yml\"\n")
+	writeTestFile(t, root, "dev.yml", "replicas: 1\n")
+	instance := &ProjectFactory{source: filepath.Join(root, ".kubor.yml")}
+	require.NoError(t, instance.values.Set("env=dev"))
+
+	actual, err := instance.Create("")
+
+	require.NoError(t, err)
+	assert.Equal(t, Values{"env": "dev", "replicas": 1}, actual.Values)
+}
+
+func writeTestFile(t *testing.T, root, name, content string) string {
+	t.Helper()
+	file := filepath.Join(root, name)
+	require.NoError(t, os.MkdirAll(filepath.Dir(file), 0755))
+	require.NoError(t, os.WriteFile(file, []byte(content), 0644))
+	return file
+}
@@ -0,0 +1,153 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v2"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	DefaultWorkspaceSource = ".kubor-workspace.yml"
+)
+
+var (
+	ErrCyclicWorkspaceDependencies = errors.New("cyclic workspace dependencies")
+)
+
+// Workspace groups several projects (members) which are handled together in one
+// invocation of kubor. Every member keeps its own project (groupId, artifactId,
+// claim, ...) but all of them are processed in the order of their dependencies.
+type Workspace struct {
+	// Values are provided to every member. They are applied after the values files
+	// of the member and before its secrets files and conditional values.
+	Values  Values            `yaml:"values,omitempty" json:"values,omitempty"`
+	Members []WorkspaceMember `yaml:"members" json:"members"`
+
+	// Values set using implicitly.
+	Source string `yaml:"-" json:"-"`
+	Root   string `yaml:"-" json:"-"`
+}
+
+type WorkspaceMember struct {
+	// Name identifies the member inside of the workspace. Default: the name of the
+	// directory of Source.
+	Name string `yaml:"name,omitempty" json:"name,omitempty"`
+	// Source is the location of the project file of this member or of the directory
+	// which contains it (as .kubor.yml). Relative to the workspace file.
+	Source string `yaml:"source" json:"source"`
+	// DependsOn are the names of all members which have to be handled before this one.
+	DependsOn []string `yaml:"dependsOn,omitempty" json:"dependsOn,omitempty"`
+}
+
+// LoadWorkspace reads and validates the given workspace file.
+func LoadWorkspace(file string) (*Workspace, error) {
+	fail := func(err error) (*Workspace, error) {
+		return nil, fmt.Errorf("cannot read workspace file '%s': %w", file, err)
+	}
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return fail(err)
+	}
+	result := Workspace{
+		Source: file,
+		Root:   filepath.Dir(file),
+	}
+	if err := yaml.Unmarshal(content, &result); err != nil {
+		return fail(err)
+	}
+	if values, ok := normalizeValue(map[string]interface{}(result.Values)).(map[string]interface{}); ok {
+		result.Values = values
+	}
+	for i, member := range result.Members {
+		if member.Source == "" {
+			return fail(fmt.Errorf("members[%d] has no source", i))
+		}
+		if !filepath.IsAbs(member.Source) {
+			member.Source = filepath.Join(result.Root, member.Source)
+		}
+		if fi, err := os.Stat(member.Source); err == nil && fi.IsDir() {
+			member.Source = filepath.Join(member.Source, ".kubor.yml")
+		}
+		if member.Name == "" {
+			member.Name = filepath.Base(filepath.Dir(member.Source))
+		}
+		result.Members[i] = member
+	}
+	if err := result.Validate(); err != nil {
+		return fail(err)
+	}
+	return &result, nil
+}
+
+func (instance Workspace) Validate() error {
+	names := make(map[string]bool, len(instance.Members))
+	for _, member := range instance.Members {
+		if names[member.Name] {
+			return fmt.Errorf("there is more than one member with name '%s'", member.Name)
+		}
+		names[member.Name] = true
+	}
+	for _, member := range instance.Members {
+		for _, dependency := range member.DependsOn {
+			if !names[dependency] {
+				return fmt.Errorf("member '%s' depends on unknown member '%s'", member.Name, dependency)
+			}
+		}
+	}
+	_, err := instance.Ordered()
+	return err
+}
+
+// Ordered returns all members in the order of their dependencies: A member is always
+// returned after all members it depends on. Members without a dependency between
+// them keep the order of their definition.
+func (instance Workspace) Ordered() ([]WorkspaceMember, error) {
+	byName := make(map[string]WorkspaceMember, len(instance.Members))
+	for _, member := range instance.Members {
+		byName[member.Name] = member
+	}
+	result := make([]WorkspaceMember, 0, len(instance.Members))
+	done := map[string]bool{}
+	var visit func(member WorkspaceMember, chain []string) error
+	visit = func(member WorkspaceMember, chain []string) error {
+		for i, candidate := range chain {
+			if candidate == member.Name {
+				return fmt.Errorf("%w: %s", ErrCyclicWorkspaceDependencies, strings.Join(append(chain[i:], member.Name), " -> "))
+			}
+		}
+		if done[member.Name] {
+			return nil
+		}
+		chain = append(chain[:len(chain):len(chain)], member.Name)
+		for _, dependency := range member.DependsOn {
+			if err := visit(byName[dependency], chain); err != nil {
+				return err
+			}
+		}
+		done[member.Name] = true
+		result = append(result, member)
+		return nil
+	}
+	for _, member := range instance.Members {
+		if err := visit(member, nil); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// ReverseOrdered returns all members in the reverse order of Ordered: A member is
+// always returned before all members it depends on (like required for delete).
+func (instance Workspace) ReverseOrdered() ([]WorkspaceMember, error) {
+	result, err := instance.Ordered()
+	if err != nil {
+		return nil, err
+	}
+	for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+		result[i], result[j] = result[j], result[i]
+	}
+	return result, nil
+}
@@ -0,0 +1,169 @@
+package model
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"path/filepath"
+	"testing"
+)
+
+func newTestWorkspace(dependencies ...[]string) Workspace {
+	result := Workspace{}
+	for _, dependency := range dependencies {
+		result.Members = append(result.Members, WorkspaceMember{
+			Name:      dependency[0],
+			Source:    dependency[0] + "/.kubor.yml",
+			DependsOn: dependency[1:],
+		})
+	}
+	return result
+}
+
+func namesOfWorkspaceMembers(members []WorkspaceMember) []string {
+	result := make([]string, len(members))
+	for i, member := range members {
+		result[i] = member.Name
+	}
+	return result
+}
+
+func Test_Workspace_Ordered(t *testing.T) {
+	cases := []struct {
+		name         string
+		workspace    Workspace
+		expected     []string
+		expectedDesc []string
+	}{{
+		name:         "without dependencies keeps definition order",
+		workspace:    newTestWorkspace([]string{"a"}, []string{"b"}, []string{"c"}),
+		expected:     []string{"a", "b", "c"},
+		expectedDesc: []string{"c", "b", "a"},
+	}, {
+		name:         "dependencies first",
+		workspace:    newTestWorkspace([]string{"frontend", "backend"}, []string{"backend", "database"}, []string{"database"}),
+		expected:     []string{"database", "backend", "frontend"},
+		expectedDesc: []string{"frontend", "backend", "database"},
+	}, {
+		name: "diamond",
+		workspace: newTestWorkspace(
+			[]string{"app", "api", "worker"},
+			[]string{"api", "infra"},
+			[]string{"worker", "infra"},
+			[]string{"infra"},
+			[]string{"standalone"},
+		),
+		expected:     []string{"infra", "api", "worker", "app", "standalone"},
+		expectedDesc: []string{"standalone", "app", "worker", "api", "infra"},
+	}}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			actual, err := c.workspace.Ordered()
+			require.NoError(t, err)
+			assert.Equal(t, c.expected, namesOfWorkspaceMembers(actual))
+
+			reversed, err := c.workspace.ReverseOrdered()
+			require.NoError(t, err)
+			assert.Equal(t, c.expectedDesc, namesOfWorkspaceMembers(reversed))
+		})
+	}
+}
+
+func Test_Workspace_Ordered_detects_cycles(t *testing.T) {
+	instance := newTestWorkspace([]string{"a", "b"}, []string{"b", "c"}, []string{"c", "a"})
+
+	_, err := instance.Ordered()
+	assert.ErrorIs(t, err, ErrCyclicWorkspaceDependencies)
+	assert.ErrorContains(t, err, "a -> b -> c -> a")
+
+	_, err = instance.ReverseOrdered()
+	assert.ErrorIs(t, err, ErrCyclicWorkspaceDependencies)
+
+	_, err = newTestWorkspace([]string{"a", "a"}).Ordered()
+	assert.ErrorContains(t, err, "a -> a")
+}
+
+func Test_Workspace_Validate(t *testing.T) {
+	assert.NoError(t, newTestWorkspace([]string{"a"}, []string{"b", "a"}).Validate())
+	assert.EqualError(t, newTestWorkspace([]string{"a"}, []string{"a"}).Validate(),
+		"there is more than one member with name 'a'")
+	assert.EqualError(t, newTestWorkspace([]string{"a", "missing"}).Validate(),
+		"member 'a' depends on unknown member 'missing'")
+	assert.ErrorIs(t, newTestWorkspace([]string{"a", "b"}, []string{"b", "a"}).Validate(), ErrCyclicWorkspaceDependencies)
+}
+
+func Test_LoadWorkspace(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, root, "backend/.kubor.yml", "artifactId: backend\n")
+	writeTestFile(t, root, "other/frontend.yml", "artifactId: frontend\n")
+	file := writeTestFile(t, root, DefaultWorkspaceSource, `values:
+  env: prod
+members:
+- source: backend
+- name: frontend
+  source: other/frontend.yml
+  dependsOn: [backend]
+`)
+
+	actual, err := LoadWorkspace(file)
+
+	require.NoError(t, err)
+	assert.Equal(t, Values{"env": "prod"}, actual.Values)
+	assert.Equal(t, []WorkspaceMember{{
+		Name:   "backend",
+		Source: filepath.Join(root, "backend", ".kubor.yml"),
+	}, {
+		Name:      "frontend",
+		Source:    filepath.Join(root, "other", "frontend.yml"),
+		DependsOn: []string{"backend"},
+	}}, actual.Members)
+}
+
+func Test_ProjectFactory_CreateAll(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, root, "backend/.kubor.yml", "artifactId: backend\nvalues:\n- replicas: 1\n")
+	writeTestFile(t, root, "frontend/.kubor.yml", "artifactId: frontend\n")
+	file := writeTestFile(t, root, DefaultWorkspaceSource, `values:
+  env: prod
+members:
+- source: frontend
+  dependsOn: [backend]
+- source: backend
+`)
+	instance := &ProjectFactory{workspace: file, artifactId: "ignored"}
+
+	ordered, err := instance.CreateAll("", false)
+	require.NoError(t, err)
+	reversed, err := instance.CreateAll("", true)
+	require.NoError(t, err)
+
+	require.Len(t, ordered, 2)
+	assert.Equal(t, Name("backend"), ordered[0].ArtifactId)
+	assert.Equal(t, "backend", ordered[0].WorkspaceMember)
+	assert.Equal(t, Values{"env": "prod", "replicas": 1}, ordered[0].Values)
+	assert.Equal(t, Name("frontend"), ordered[1].ArtifactId)
+	assert.Equal(t, Values{"env": "prod"}, ordered[1].Values)
+	require.Len(t, reversed, 2)
+	assert.Equal(t, Name("frontend"), reversed[0].ArtifactId)
+	assert.Equal(t, Name("backend"), reversed[1].ArtifactId)
+}
+
+func Test_ProjectFactory_Workspace_from_environment(t *testing.T) {
+	root := t.TempDir()
+	file := writeTestFile(t, root, DefaultWorkspaceSource, "members:\n- source: backend\n")
+	t.Setenv("KUBOR_WORKSPACE", file)
+	instance := &ProjectFactory{}
+
+	actual, err := instance.Workspace()
+
+	require.NoError(t, err)
+	require.NotNil(t, actual)
+	assert.Len(t, actual.Members, 1)
+	assert.False(t, instance.HasExplicitWorkspace())
+
+	instance.workspace = filepath.Join(root, "missing.yml")
+
+	_, err = instance.Workspace()
+
+	assert.Error(t, err, "--workspace wins over the environment")
+	assert.True(t, instance.HasExplicitWorkspace())
+}
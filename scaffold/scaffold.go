@@ -0,0 +1,244 @@
+package scaffold
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"github.com/echocat/kubor/model"
+	"gopkg.in/yaml.v2"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	ProjectFile = ".kubor.yml"
+
+	starterRoot        = "starter"
+	starterValuesFile  = "values.yml"
+	starterSchemaFile  = "values.schema.yml"
+	stagePlaceholder   = "####STAGE####"
+	projectFileAltName = ".kubor.yaml"
+)
+
+var (
+	ErrAlreadyExists = errors.New("already exists")
+
+	//go:embed starter
+	starter embed.FS
+)
+
+// Options configures a scaffold created by Create.
+type Options struct {
+	// Directory where the project is created in.
+	Directory string
+	// GroupId of the project. Default: the groupId of From or - if there is none - the
+	// ArtifactId (the starter templates use it as namespace).
+	GroupId model.Name
+	// ArtifactId of the project. Default: the name of Directory.
+	ArtifactId model.Name
+	// Stages of the project. If empty the default stages (or the ones of From) are used.
+	Stages model.Stages
+	// Schema creates a values schema for the starter values. Ignored if From is set.
+	Schema bool
+	// From is the directory of another project which is used as template instead of
+	// the starter templates. All its files (except hidden ones) are copied.
+	From string
+	// Force overwrites files which already exist.
+	Force bool
+}
+
+type file struct {
+	path    string
+	content []byte
+	perm    os.FileMode
+}
+
+// Create creates a new project inside of Options.Directory: the project file
+// (using model.Project.Save) and either the starter templates (a Deployment, a Service
+// and a ConfigMap with their values) or all files of Options.From.
+func Create(options Options) (*model.Project, error) {
+	fail := func(err error) (*model.Project, error) {
+		return nil, fmt.Errorf("cannot create project in '%s': %w", options.Directory, err)
+	}
+
+	target, err := filepath.Abs(options.Directory)
+	if err != nil {
+		return fail(err)
+	}
+	artifactId := options.ArtifactId
+	if artifactId == "" {
+		artifactId = model.Name(filepath.Base(target))
+		if _, err := artifactId.MarshalText(); err != nil {
+			return fail(fmt.Errorf("cannot use name of directory as artifactId, please provide one using --artifactId: %w", err))
+		}
+	}
+
+	var project model.Project
+	var files []file
+	if options.From != "" {
+		project, files, err = fromProject(options.From, target)
+	} else {
+		project, files, err = fromStarter(options, target)
+	}
+	if err != nil {
+		return fail(err)
+	}
+
+	project.ArtifactId = artifactId
+	if options.GroupId != "" {
+		project.GroupId = options.GroupId
+	}
+	if project.GroupId == "" {
+		project.GroupId = artifactId
+	}
+	if len(options.Stages) > 0 {
+		project.Stages = options.Stages
+	}
+	project.Source = filepath.Join(target, ProjectFile)
+
+	if !options.Force {
+		for _, candidate := range append([]string{project.Source, filepath.Join(target, projectFileAltName)}, pathsOf(files)...) {
+			if _, err := os.Stat(candidate); err == nil {
+				return fail(fmt.Errorf("%w: %s (use --force to overwrite)", ErrAlreadyExists, candidate))
+			} else if !os.IsNotExist(err) {
+				return fail(err)
+			}
+		}
+	}
+
+	stage := model.StageDefault
+	if len(project.Stages) > 0 && !project.Stages.Contains(stage) {
+		stage = project.Stages[0]
+	}
+	for _, f := range files {
+		if err := os.MkdirAll(filepath.Dir(f.path), 0755); err != nil {
+			return fail(err)
+		}
+		content := f.content
+		if options.From == "" {
+			content = []byte(strings.ReplaceAll(string(content), stagePlaceholder, stage.String()))
+		}
+		if err := os.WriteFile(f.path, content, f.perm); err != nil {
+			return fail(err)
+		}
+	}
+	if err := project.Save(); err != nil {
+		return fail(err)
+	}
+	return &project, nil
+}
+
+func fromStarter(options Options, target string) (model.Project, []file, error) {
+	project := model.NewProject()
+	project.ValuesFiles = model.ValuesFiles{starterValuesFile}
+	if options.Schema {
+		project.ValuesSchema = starterSchemaFile
+	}
+	var files []file
+	err := fs.WalkDir(starter, starterRoot, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		relative := strings.TrimPrefix(path, starterRoot+"/")
+		if relative == starterSchemaFile && !options.Schema {
+			return nil
+		}
+		if content, err := starter.ReadFile(path); err != nil {
+			return err
+		} else {
+			files = append(files, file{filepath.Join(target, filepath.FromSlash(relative)), content, 0644})
+		}
+		return nil
+	})
+	return project, files, err
+}
+
+func fromProject(from string, target string) (model.Project, []file, error) {
+	source, err := filepath.Abs(from)
+	if err != nil {
+		return model.Project{}, nil, err
+	}
+	projectFile := filepath.Join(source, ProjectFile)
+	if _, err := os.Stat(projectFile); os.IsNotExist(err) {
+		projectFile = filepath.Join(source, projectFileAltName)
+	}
+	content, err := os.ReadFile(projectFile)
+	if err != nil {
+		return model.Project{}, nil, fmt.Errorf("cannot read project to create scaffold from: %w", err)
+	}
+	project := model.NewProject()
+	if err := yaml.Unmarshal(content, &project); err != nil {
+		return model.Project{}, nil, fmt.Errorf("cannot read project to create scaffold from '%s': %w", projectFile, err)
+	}
+	if project.Extends, err = rebaseIncludes(project.Extends, source, target); err != nil {
+		return model.Project{}, nil, err
+	}
+	if project.Include, err = rebaseIncludes(project.Include, source, target); err != nil {
+		return model.Project{}, nil, err
+	}
+
+	var files []file
+	err = filepath.WalkDir(source, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == source {
+			return nil
+		}
+		if strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			if path == target {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		relative, err := filepath.Rel(source, path)
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if content, err := os.ReadFile(path); err != nil {
+			return err
+		} else {
+			files = append(files, file{filepath.Join(target, relative), content, info.Mode().Perm()})
+		}
+		return nil
+	})
+	return project, files, err
+}
+
+// rebaseIncludes makes all relative includes (which are relative to the project
+// the scaffold is created from) relative to the target.
+func rebaseIncludes(in model.ProjectIncludes, source string, target string) (model.ProjectIncludes, error) {
+	result := make(model.ProjectIncludes, len(in))
+	for i, candidate := range in {
+		if filepath.IsAbs(candidate) {
+			result[i] = candidate
+		} else if rebased, err := filepath.Rel(target, filepath.Join(source, candidate)); err != nil {
+			return nil, err
+		} else {
+			result[i] = filepath.ToSlash(rebased)
+		}
+	}
+	return result, nil
+}
+
+func pathsOf(files []file) []string {
+	result := make([]string, len(files))
+	for i, f := range files {
+		result[i] = f.path
+	}
+	sort.Strings(result)
+	return result
+}
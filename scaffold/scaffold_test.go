@@ -0,0 +1,173 @@
+package scaffold
+
+import (
+	"github.com/echocat/kubor/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func readTestProject(t *testing.T, directory string) model.Project {
+	t.Helper()
+	content, err := os.ReadFile(filepath.Join(directory, ProjectFile))
+	require.NoError(t, err)
+	result := model.NewProject()
+	require.NoError(t, yaml.UnmarshalStrict(content, &result))
+	result.Source = filepath.Join(directory, ProjectFile)
+	result.Root = directory
+	return result
+}
+
+func renderTestProject(t *testing.T, project model.Project) string {
+	t.Helper()
+	for _, file := range project.ValuesFiles {
+		values, err := model.ValuesFromFile(filepath.Join(project.Root, string(file)))
+		require.NoError(t, err)
+		project.Values = project.Values.MergeWith(values)
+	}
+	cp, err := project.RenderedTemplatesProvider()
+	require.NoError(t, err)
+	var result strings.Builder
+	for {
+		_, content, err := cp()
+		if err == io.EOF {
+			return result.String()
+		}
+		require.NoError(t, err)
+		result.Write(content)
+		result.WriteString("\n---\n")
+	}
+}
+
+func Test_Create_from_starter(t *testing.T) {
+	directory := filepath.Join(t.TempDir(), "my-service")
+
+	actual, err := Create(Options{Directory: directory})
+
+	require.NoError(t, err)
+	assert.Equal(t, model.Name("my-service"), actual.ArtifactId)
+	assert.Equal(t, model.Name("my-service"), actual.GroupId, "groupId should default to artifactId")
+	assert.Equal(t, model.NewProject().Claim, actual.Claim)
+	assert.FileExists(t, filepath.Join(directory, "values.yml"))
+	assert.NoFileExists(t, filepath.Join(directory, "values.schema.yml"))
+
+	saved := readTestProject(t, directory)
+	assert.Equal(t, model.Name("my-service"), saved.ArtifactId)
+	assert.Equal(t, model.Name("my-service"), saved.GroupId)
+	assert.Equal(t, model.ValuesFiles{"values.yml"}, saved.ValuesFiles)
+	assert.Equal(t, model.NewProject().Stages, saved.Stages)
+
+	content, err := os.ReadFile(filepath.Join(directory, ProjectFile))
+	require.NoError(t, err)
+	assert.NotContains(t, string(content), "claim", "defaults should not be written")
+
+	rendered := renderTestProject(t, saved)
+	assert.NotContains(t, rendered, stagePlaceholder)
+	assert.Contains(t, rendered, "namespace: my-service\n")
+	assert.Contains(t, rendered, "kubor.echocat.org/stage: "+model.StageDefault.String()+"\n")
+	assert.Contains(t, rendered, "containerPort: 80\n")
+	assert.Contains(t, rendered, "image: \"nginx:")
+}
+
+func Test_Create_from_starter_with_options(t *testing.T) {
+	directory := t.TempDir()
+
+	actual, err := Create(Options{
+		Directory:  directory,
+		GroupId:    "shop",
+		ArtifactId: "cart",
+		Stages:     model.Stages{"first", "second"},
+		Schema:     true,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, model.Name("shop"), actual.GroupId)
+	saved := readTestProject(t, directory)
+	assert.Equal(t, model.Name("shop"), saved.GroupId)
+	assert.Equal(t, model.Name("cart"), saved.ArtifactId)
+	assert.Equal(t, model.Stages{"first", "second"}, saved.Stages)
+	assert.Equal(t, model.ValuesSchema("values.schema.yml"), saved.ValuesSchema)
+	assert.FileExists(t, filepath.Join(directory, "values.schema.yml"))
+
+	schema, err := saved.ValuesSchema.Load(directory)
+	require.NoError(t, err)
+	values, err := model.ValuesFromFile(filepath.Join(directory, "values.yml"))
+	require.NoError(t, err)
+	assert.NoError(t, schema.Validate(values))
+
+	rendered := renderTestProject(t, saved)
+	assert.Contains(t, rendered, "namespace: shop\n")
+	assert.Contains(t, rendered, "kubor.echocat.org/stage: first\n")
+}
+
+func Test_Create_fails_if_files_exist(t *testing.T) {
+	directory := filepath.Join(t.TempDir(), "svc")
+	_, err := Create(Options{Directory: directory})
+	require.NoError(t, err)
+
+	_, err = Create(Options{Directory: directory})
+	assert.ErrorIs(t, err, ErrAlreadyExists)
+
+	_, err = Create(Options{Directory: directory, Force: true})
+	assert.NoError(t, err)
+}
+
+func Test_Create_fails_for_illegal_directory_name(t *testing.T) {
+	_, err := Create(Options{Directory: filepath.Join(t.TempDir(), "Not_Valid")})
+
+	assert.ErrorContains(t, err, "please provide one using --artifactId")
+}
+
+func Test_Create_from_project(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.CopyFS(root, fstest.MapFS{
+		"base.yml":          {Data: []byte("stages: [deploy]\n")},
+		"source/.kubor.yml": {Data: []byte("extends: ../base.yml\ngroupId: shop\nartifactId: source\nvaluesFiles: [values.yml]\n")},
+		"source/values.yml": {Data: []byte("replicas: 2\n")},
+		"source/kubernetes/templates/deployment.yml": {Data: []byte("kind: Deployment\n")},
+		"source/.hidden":     {Data: []byte("ignored\n")},
+		"source/.git/config": {Data: []byte("ignored\n")},
+	}))
+	directory := filepath.Join(root, "services", "target")
+
+	actual, err := Create(Options{Directory: directory, From: filepath.Join(root, "source")})
+
+	require.NoError(t, err)
+	assert.Equal(t, model.Name("target"), actual.ArtifactId)
+	assert.Equal(t, model.Name("shop"), actual.GroupId)
+	saved := readTestProject(t, directory)
+	assert.Equal(t, model.ProjectIncludes{"../../base.yml"}, saved.Extends)
+	assert.Equal(t, model.Name("shop"), saved.GroupId)
+	assert.Equal(t, model.Name("target"), saved.ArtifactId)
+	assert.Equal(t, model.ValuesFiles{"values.yml"}, saved.ValuesFiles)
+	assert.FileExists(t, filepath.Join(directory, "values.yml"))
+	assert.FileExists(t, filepath.Join(directory, "kubernetes", "templates", "deployment.yml"))
+	assert.NoFileExists(t, filepath.Join(directory, ".hidden"))
+	assert.NoDirExists(t, filepath.Join(directory, ".git"))
+}
+
+func Test_Create_from_project_inside_of_itself(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.CopyFS(root, fstest.MapFS{
+		".kubor.yml": {Data: []byte("artifactId: source\n")},
+		"values.yml": {Data: []byte("replicas: 2\n")},
+	}))
+
+	_, err := Create(Options{Directory: filepath.Join(root, "copy"), From: root})
+
+	require.NoError(t, err)
+	assert.FileExists(t, filepath.Join(root, "copy", "values.yml"))
+	assert.NoDirExists(t, filepath.Join(root, "copy", "copy"))
+}
+
+func Test_Create_from_missing_project(t *testing.T) {
+	_, err := Create(Options{Directory: filepath.Join(t.TempDir(), "svc"), From: t.TempDir()})
+
+	assert.ErrorContains(t, err, "cannot read project to create scaffold from")
+}
@@ -0,0 +1,203 @@
+package template
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	executionErrorRegexp = regexp.MustCompile(`(?s)^template: (.+?):(\d+):(\d+): executing "(?:[^"\\]|\\.)*" at <(.*?)>: (.*)$`)
+	parseErrorRegexp     = regexp.MustCompile(`(?s)^template: (.+?):(\d+): (.*)$`)
+)
+
+// TemplateError describes a failure while parsing or executing a template. It
+// records where inside the source the failure happened. If the failure was
+// caused inside of a nested template (for example via include or render) the
+// nested TemplateError is available as Cause.
+type TemplateError struct {
+	// Source is the name (usually the filename) of the template which failed.
+	Source string
+	// Line is the 1-based line inside of Source; 0 if unknown.
+	Line int
+	// Column is the 1-based column inside of Line; 0 if unknown.
+	Column int
+	// Action is the template action (like "<.Values.foo>") which failed; empty if unknown.
+	Action string
+	// Message describes the failure itself - without the message of Cause.
+	Message string
+	// Code is the source code of the template which failed; empty if unknown.
+	Code string
+	// Cause is the nested TemplateError which caused this error, if any.
+	Cause error
+
+	// original is the error as it was reported by text/template.
+	original error
+}
+
+func (instance *TemplateError) Error() string {
+	buf := new(bytes.Buffer)
+	buf.WriteString(instance.Location())
+	if instance.Message != "" {
+		buf.WriteString(": ")
+		buf.WriteString(instance.Message)
+	}
+	if cause := instance.Cause; cause != nil {
+		buf.WriteString(": ")
+		buf.WriteString(cause.Error())
+	}
+	return buf.String()
+}
+
+func (instance *TemplateError) Unwrap() error {
+	if instance.Cause != nil {
+		return instance.Cause
+	}
+	return instance.original
+}
+
+// Location returns the location of this error in format <source>[:<line>[:<column>]].
+func (instance *TemplateError) Location() string {
+	result := instance.Source
+	if instance.Line > 0 {
+		result += ":" + strconv.Itoa(instance.Line)
+		if instance.Column > 0 {
+			result += ":" + strconv.Itoa(instance.Column)
+		}
+	}
+	return result
+}
+
+// Stack returns this error and all of its nested TemplateErrors. The first
+// element is always this error, the last one the error which originally failed.
+func (instance *TemplateError) Stack() []*TemplateError {
+	result := []*TemplateError{instance}
+	var current error = instance
+	for {
+		var next *TemplateError
+		if cause := errors.Unwrap(current); cause == nil || !errors.As(cause, &next) {
+			return result
+		}
+		result = append(result, next)
+		current = next
+	}
+}
+
+// Excerpt returns the lines around the failed line of Code, including a caret
+// pointing to the failed column. An empty string is returned if either Code or
+// Line is unknown.
+func (instance *TemplateError) Excerpt(surroundingLines int) string {
+	if instance.Code == "" || instance.Line <= 0 {
+		return ""
+	}
+	lines := strings.Split(strings.TrimSuffix(strings.ReplaceAll(instance.Code, "\r\n", "\n"), "\n"), "\n")
+	if instance.Line > len(lines) {
+		return ""
+	}
+	from, to := instance.Line-surroundingLines, instance.Line+surroundingLines
+	if from < 1 {
+		from = 1
+	}
+	if to > len(lines) {
+		to = len(lines)
+	}
+	width := len(strconv.Itoa(to))
+	buf := new(bytes.Buffer)
+	for i := from; i <= to; i++ {
+		_, _ = fmt.Fprintf(buf, "%*d | %s\n", width, i, lines[i-1])
+		if i == instance.Line && instance.Column > 0 {
+			_, _ = fmt.Fprintf(buf, "%*s | %s^\n", width, "", caretIndent(lines[i-1], instance.Column-1))
+		}
+	}
+	return buf.String()
+}
+
+func caretIndent(line string, column int) string {
+	if column > len(line) {
+		column = len(line)
+	}
+	result := []byte(line[:column])
+	for i, c := range result {
+		if c != '\t' {
+			result[i] = ' '
+		}
+	}
+	return string(result)
+}
+
+// CollectTemplateErrors returns all TemplateErrors which are contained in the
+// given error. This includes errors combined by errors.Join or similar. Nested
+// TemplateErrors (see TemplateError.Stack()) are not returned separately.
+func CollectTemplateErrors(err error) []*TemplateError {
+	if err == nil {
+		return nil
+	}
+	if te, ok := err.(*TemplateError); ok {
+		return []*TemplateError{te}
+	}
+	switch v := err.(type) {
+	case interface{ Unwrap() []error }:
+		var result []*TemplateError
+		for _, child := range v.Unwrap() {
+			result = append(result, CollectTemplateErrors(child)...)
+		}
+		return result
+	case interface{ Unwrap() error }:
+		return CollectTemplateErrors(v.Unwrap())
+	}
+	return nil
+}
+
+// newTemplateError converts the given error (which was created by
+// text/template) into a TemplateError. codeOf is used to resolve the source
+// code of the failing template. If the given error cannot be interpreted it is
+// returned as it is.
+func newTemplateError(err error, codeOf func(source string) string) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := err.(*TemplateError); ok {
+		return err
+	}
+
+	plain := err.Error()
+	if m := executionErrorRegexp.FindStringSubmatch(plain); m != nil {
+		line, _ := strconv.Atoi(m[2])
+		column, _ := strconv.Atoi(m[3])
+		result := &TemplateError{
+			Source:   m[1],
+			Line:     line,
+			Column:   column + 1,
+			Action:   "<" + m[4] + ">",
+			Code:     codeOf(m[1]),
+			original: err,
+		}
+		result.Message, result.Cause = splitMessageAndCause(m[5], err)
+		return result
+	}
+	if m := parseErrorRegexp.FindStringSubmatch(plain); m != nil {
+		line, _ := strconv.Atoi(m[2])
+		return &TemplateError{
+			Source:   m[1],
+			Line:     line,
+			Message:  m[3],
+			Code:     codeOf(m[1]),
+			original: err,
+		}
+	}
+	return err
+}
+
+// splitMessageAndCause removes the message of a nested cause from the given message.
+func splitMessageAndCause(message string, err error) (string, error) {
+	var nested *TemplateError
+	if errors.As(err, &nested) {
+		if trimmed := strings.TrimSuffix(message, nested.Error()); trimmed != message {
+			return strings.TrimSuffix(strings.TrimSpace(trimmed), ":"), nested
+		}
+	}
+	return message, nil
+}
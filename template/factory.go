@@ -18,11 +18,19 @@ type Factory interface {
 
 type FactoryImpl struct {
 	FunctionProvider FunctionProvider
+
+	// Library if set, provides its named templates to every template created by this factory.
+	Library *Library
 }
 
 func (instance *FactoryImpl) new(name string, file *string, code string) (Template, error) {
-	if delegate, err := newDelegate(name, code, instance.FunctionProvider); err != nil {
-		return nil, err
+	if delegate, err := newDelegate(name, code, instance.FunctionProvider, instance.Library); err != nil {
+		return nil, newTemplateError(err, func(source string) string {
+			if source == name {
+				return code
+			}
+			return instance.Library.codeOf(source)
+		})
 	} else {
 		return &Impl{
 			sourceName:       name,
@@ -31,6 +39,7 @@ func (instance *FactoryImpl) new(name string, file *string, code string) (Templa
 			functionProvider: instance.FunctionProvider,
 			factory:          instance,
 			delegate:         delegate,
+			library:          instance.Library,
 		}, nil
 	}
 }
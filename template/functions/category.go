@@ -105,12 +105,16 @@ func (instance Categories) FilterBy(predicate FunctionPredicate) (Categories, er
 
 var CategoriesDefault = Categories{
 	"codecs":        CategoryCodecs,
+	"control":       CategoryControl,
 	"conversations": CategoryConversations,
+	"environment":   CategoryEnvironment,
 	"general":       CategoryGeneral,
 	"kubernetes":    CategoryKubernetes,
 	"ids":           CategoryIds,
 	"math":          CategoryMath,
+	"network":       CategoryNetwork,
 	"path":          CategoryPath,
+	"project":       CategoryProject,
 	"regexp":        CategoryRegexp,
 	"serialization": CategorySerialization,
 	"strings":       CategoryStrings,
@@ -0,0 +1,371 @@
+package functions
+
+import (
+	"fmt"
+	"github.com/echocat/kubor/template"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ControlError is returned by the control functions (required, fail, assert, value*)
+// if a template explicitly fails. It carries the source of the template it was raised in.
+type ControlError struct {
+	Source  string
+	Message string
+}
+
+func (instance ControlError) Error() string {
+	if instance.Source == "" {
+		return instance.Message
+	}
+	return fmt.Sprintf("%s: %s", instance.Source, instance.Message)
+}
+
+func newControlError(context template.ExecutionContext, message string, args ...interface{}) ControlError {
+	return ControlError{
+		Source:  context.GetTemplate().GetSource(),
+		Message: fmt.Sprintf(message, args...),
+	}
+}
+
+var FuncRequired = Function{
+	Description: "Fails the rendering with the given <message> if the provided <value> is nil or an empty string.",
+	Parameters: Parameters{{
+		Name:        "message",
+		Description: "Message which will be displayed if <value> is absent.",
+	}, {
+		Name: "value",
+	}},
+	Returns: Return{
+		Description: "The provided <value> if present.",
+	},
+}.MustWithFunc(func(context template.ExecutionContext, message string, value interface{}) (interface{}, error) {
+	if value == nil {
+		return nil, newControlError(context, "%s", message)
+	}
+	if str, ok := value.(string); ok && str == "" {
+		return nil, newControlError(context, "%s", message)
+	}
+	return value, nil
+})
+
+var FuncFail = Function{
+	Description: "Fails the rendering with the given <message>.",
+	Parameters: Parameters{{
+		Name: "message",
+	}},
+	Returns: Return{
+		Description: "Nothing, it will always fail.",
+	},
+}.MustWithFunc(func(context template.ExecutionContext, message string) (string, error) {
+	return "", newControlError(context, "%s", message)
+})
+
+var FuncAssert = Function{
+	Description: "Fails the rendering with the given <message> if <condition> is not true." +
+		" If <condition> is not a boolean it is true if it is not empty.",
+	Parameters: Parameters{{
+		Name: "condition",
+	}, {
+		Name:        "message",
+		Description: "Message which will be displayed if <condition> is not true.",
+	}},
+	Returns: Return{
+		Description: "Always an empty string, which allows to use it directly inside of the template.",
+	},
+}.MustWithFunc(func(context template.ExecutionContext, condition interface{}, message string) (string, error) {
+	if b, ok := condition.(bool); ok {
+		if !b {
+			return "", newControlError(context, "%s", message)
+		}
+	} else if empty(condition) {
+		return "", newControlError(context, "%s", message)
+	}
+	return "", nil
+})
+
+var FuncValue = Function{
+	Description: "Resolves the value of the given <path> (like \"foo.bar.0.name\") inside the values of the current context." +
+		" If the value does not exist or is nil <default> is returned.",
+	Parameters: Parameters{{
+		Name: "path",
+	}, {
+		Name: "default",
+	}},
+}.MustWithFunc(func(context template.ExecutionContext, path string, def interface{}) (interface{}, error) {
+	if v, found, err := resolveValueOfContext(context, path); err != nil {
+		return nil, err
+	} else if !found || v == nil {
+		return def, nil
+	} else {
+		return v, nil
+	}
+})
+
+var FuncValueString = Function{
+	Description: "Resolves the value of the given <path> (like \"foo.bar.0.name\") inside the values of the current context" +
+		" as string. If the value does not exist or is nil <default> is returned. Numbers and booleans will be converted;" +
+		" every other type will fail.",
+	Parameters: Parameters{{
+		Name: "path",
+	}, {
+		Name: "default",
+	}},
+}.MustWithFunc(func(context template.ExecutionContext, path string, def interface{}) (string, error) {
+	if v, err := resolveValueOfContextOr(context, path, def); err != nil {
+		return "", err
+	} else if v == nil {
+		return "", nil
+	} else {
+		switch tv := reflect.ValueOf(v); tv.Kind() {
+		case reflect.String:
+			return tv.String(), nil
+		case reflect.Bool,
+			reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+			return fmt.Sprint(v), nil
+		default:
+			return "", newControlError(context, "value '%s' is expected to be a string, but got: %v", path, reflect.TypeOf(v))
+		}
+	}
+})
+
+var FuncValueInt = Function{
+	Description: "Resolves the value of the given <path> (like \"foo.bar.0.name\") inside the values of the current context" +
+		" as integer. If the value does not exist or is nil <default> is returned. Strings will be parsed; every other type" +
+		" which does not represent a whole number will fail.",
+	Parameters: Parameters{{
+		Name: "path",
+	}, {
+		Name: "default",
+	}},
+}.MustWithFunc(func(context template.ExecutionContext, path string, def interface{}) (int64, error) {
+	if v, err := resolveValueOfContextOr(context, path, def); err != nil {
+		return 0, err
+	} else if v == nil {
+		return 0, nil
+	} else {
+		fail := func() (int64, error) {
+			return 0, newControlError(context, "value '%s' is expected to be an integer, but got: %v", path, v)
+		}
+		switch tv := reflect.ValueOf(v); tv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return tv.Int(), nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if tv.Uint() > math.MaxInt64 {
+				return fail()
+			}
+			return int64(tv.Uint()), nil
+		case reflect.Float32, reflect.Float64:
+			if f := tv.Float(); f != math.Trunc(f) {
+				return fail()
+			} else {
+				return int64(f), nil
+			}
+		case reflect.String:
+			if i, err := strconv.ParseInt(strings.TrimSpace(tv.String()), 10, 64); err != nil {
+				return fail()
+			} else {
+				return i, nil
+			}
+		default:
+			return fail()
+		}
+	}
+})
+
+var FuncValueFloat = Function{
+	Description: "Resolves the value of the given <path> (like \"foo.bar.0.name\") inside the values of the current context" +
+		" as floating point number. If the value does not exist or is nil <default> is returned. Strings will be parsed;" +
+		" every other type which is not a number will fail.",
+	Parameters: Parameters{{
+		Name: "path",
+	}, {
+		Name: "default",
+	}},
+}.MustWithFunc(func(context template.ExecutionContext, path string, def interface{}) (float64, error) {
+	if v, err := resolveValueOfContextOr(context, path, def); err != nil {
+		return 0, err
+	} else if v == nil {
+		return 0, nil
+	} else {
+		fail := func() (float64, error) {
+			return 0, newControlError(context, "value '%s' is expected to be a number, but got: %v", path, v)
+		}
+		switch tv := reflect.ValueOf(v); tv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return float64(tv.Int()), nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return float64(tv.Uint()), nil
+		case reflect.Float32, reflect.Float64:
+			return tv.Float(), nil
+		case reflect.String:
+			if f, err := strconv.ParseFloat(strings.TrimSpace(tv.String()), 64); err != nil {
+				return fail()
+			} else {
+				return f, nil
+			}
+		default:
+			return fail()
+		}
+	}
+})
+
+var FuncValueBool = Function{
+	Description: "Resolves the value of the given <path> (like \"foo.bar.0.name\") inside the values of the current context" +
+		" as boolean. If the value does not exist or is nil <default> is returned. Strings will be parsed; every other type" +
+		" will fail.",
+	Parameters: Parameters{{
+		Name: "path",
+	}, {
+		Name: "default",
+	}},
+}.MustWithFunc(func(context template.ExecutionContext, path string, def interface{}) (bool, error) {
+	if v, err := resolveValueOfContextOr(context, path, def); err != nil {
+		return false, err
+	} else if v == nil {
+		return false, nil
+	} else {
+		switch tv := reflect.ValueOf(v); tv.Kind() {
+		case reflect.Bool:
+			return tv.Bool(), nil
+		case reflect.String:
+			if b, err := strconv.ParseBool(strings.TrimSpace(tv.String())); err == nil {
+				return b, nil
+			}
+		}
+		return false, newControlError(context, "value '%s' is expected to be a boolean, but got: %v", path, v)
+	}
+})
+
+var FuncValueSlice = Function{
+	Description: "Resolves the value of the given <path> (like \"foo.bar.0.name\") inside the values of the current context" +
+		" as slice. If the value does not exist or is nil an empty slice is returned. Every other type will fail.",
+	Parameters: Parameters{{
+		Name: "path",
+	}},
+}.MustWithFunc(func(context template.ExecutionContext, path string) ([]interface{}, error) {
+	if v, found, err := resolveValueOfContext(context, path); err != nil {
+		return nil, err
+	} else if !found || v == nil {
+		return []interface{}{}, nil
+	} else if tv := reflect.ValueOf(v); tv.Kind() != reflect.Slice && tv.Kind() != reflect.Array {
+		return nil, newControlError(context, "value '%s' is expected to be a list, but got: %v", path, reflect.TypeOf(v))
+	} else {
+		result := make([]interface{}, tv.Len())
+		for i := 0; i < tv.Len(); i++ {
+			result[i] = tv.Index(i).Interface()
+		}
+		return result, nil
+	}
+})
+
+var FuncValueMap = Function{
+	Description: "Resolves the value of the given <path> (like \"foo.bar.0.name\") inside the values of the current context" +
+		" as map. If the value does not exist or is nil an empty map is returned. Every other type will fail.",
+	Parameters: Parameters{{
+		Name: "path",
+	}},
+}.MustWithFunc(func(context template.ExecutionContext, path string) (map[string]interface{}, error) {
+	if v, found, err := resolveValueOfContext(context, path); err != nil {
+		return nil, err
+	} else if !found || v == nil {
+		return map[string]interface{}{}, nil
+	} else if tv := reflect.ValueOf(v); tv.Kind() != reflect.Map {
+		return nil, newControlError(context, "value '%s' is expected to be a map, but got: %v", path, reflect.TypeOf(v))
+	} else {
+		result := make(map[string]interface{}, tv.Len())
+		for _, key := range tv.MapKeys() {
+			result[fmt.Sprint(key.Interface())] = tv.MapIndex(key).Interface()
+		}
+		return result, nil
+	}
+})
+
+var FuncsControl = Functions{
+	"required":    FuncRequired,
+	"fail":        FuncFail,
+	"assert":      FuncAssert,
+	"value":       FuncValue,
+	"valueString": FuncValueString,
+	"valueInt":    FuncValueInt,
+	"valueFloat":  FuncValueFloat,
+	"valueBool":   FuncValueBool,
+	"valueSlice":  FuncValueSlice,
+	"valueMap":    FuncValueMap,
+}
+var CategoryControl = Category{
+	Functions: FuncsControl,
+}
+
+func resolveValueOfContextOr(context template.ExecutionContext, path string, def interface{}) (interface{}, error) {
+	if v, found, err := resolveValueOfContext(context, path); err != nil {
+		return nil, err
+	} else if !found || v == nil {
+		return def, nil
+	} else {
+		return v, nil
+	}
+}
+
+// resolveValueOfContext resolves the given path inside the "Values" property of the data of the
+// current context. If there is no such property the data itself is used as root.
+func resolveValueOfContext(context template.ExecutionContext, path string) (interface{}, bool, error) {
+	root := context.GetData()
+	if values, found := lookupProperty(reflect.ValueOf(root), "Values"); found {
+		root = values.Interface()
+	}
+	if path == "" || path == "." {
+		return root, true, nil
+	}
+	current := reflect.ValueOf(root)
+	for _, element := range strings.Split(path, ".") {
+		if next, found := lookupProperty(current, element); !found {
+			return nil, false, nil
+		} else {
+			current = next
+		}
+	}
+	if !current.IsValid() {
+		return nil, true, nil
+	}
+	return current.Interface(), true, nil
+}
+
+func lookupProperty(v reflect.Value, name string) (reflect.Value, bool) {
+	for v.IsValid() && (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return reflect.Value{}, false
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return reflect.Value{}, false
+	}
+	switch v.Kind() {
+	case reflect.Map:
+		if v.Type().Key().Kind() == reflect.String {
+			if result := v.MapIndex(reflect.ValueOf(name).Convert(v.Type().Key())); result.IsValid() {
+				return result, true
+			}
+			return reflect.Value{}, false
+		}
+		for _, key := range v.MapKeys() {
+			if fmt.Sprint(key.Interface()) == name {
+				return v.MapIndex(key), true
+			}
+		}
+	case reflect.Struct:
+		if field, ok := v.Type().FieldByName(name); ok && field.IsExported() {
+			return v.FieldByIndex(field.Index), true
+		}
+	case reflect.Slice, reflect.Array:
+		if i, err := strconv.Atoi(name); err == nil && i >= 0 && i < v.Len() {
+			return v.Index(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
@@ -0,0 +1,80 @@
+package functions
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_FuncRequired(t *testing.T) {
+	data := map[string]interface{}{"foo": "bar", "empty": "", "nil": nil}
+	assert.Equal(t, "bar", mustExecuteTemplate(t, `{{ .foo | required "foo is mandatory" }}`, data))
+
+	_, err := executeTemplate(t, `{{ .empty | required "empty is mandatory" }}`, data)
+	assert.ErrorContains(t, err, t.Name()+": empty is mandatory")
+
+	_, err = executeTemplate(t, `{{ .nil | required "nil is mandatory" }}`, data)
+	assert.ErrorContains(t, err, t.Name()+": nil is mandatory")
+}
+
+func Test_FuncFail(t *testing.T) {
+	_, err := executeTemplate(t, `foo
+{{ fail "this is broken" }}`, nil)
+	assert.ErrorContains(t, err, t.Name()+":2:")
+	assert.ErrorContains(t, err, t.Name()+": this is broken")
+	var ce ControlError
+	assert.ErrorAs(t, err, &ce)
+	assert.Equal(t, ControlError{Source: t.Name(), Message: "this is broken"}, ce)
+}
+
+func Test_FuncAssert(t *testing.T) {
+	assert.Equal(t, "ok", mustExecuteTemplate(t, `{{ assert true "should not fail" }}ok`, nil))
+	assert.Equal(t, "ok", mustExecuteTemplate(t, `{{ assert "foo" "should not fail" }}ok`, nil))
+
+	_, err := executeTemplate(t, `{{ assert (eq 1 2) "1 is not 2" }}`, nil)
+	assert.ErrorContains(t, err, t.Name()+": 1 is not 2")
+
+	_, err = executeTemplate(t, `{{ assert "" "empty" }}`, nil)
+	assert.ErrorContains(t, err, t.Name()+": empty")
+}
+
+func Test_FuncValueGetters(t *testing.T) {
+	data := struct {
+		Values map[string]interface{}
+	}{
+		Values: map[string]interface{}{
+			"image": map[interface{}]interface{}{
+				"tag":      "1.2",
+				"replicas": 3,
+				"enabled":  "true",
+				"ratio":    0.5,
+			},
+			"ports": []interface{}{
+				map[string]interface{}{"name": "http", "port": 8080},
+			},
+			"nil": nil,
+		},
+	}
+
+	assert.Equal(t, "1.2", mustExecuteTemplate(t, `{{ valueString "image.tag" "latest" }}`, data))
+	assert.Equal(t, "latest", mustExecuteTemplate(t, `{{ valueString "image.unknown" "latest" }}`, data))
+	assert.Equal(t, "latest", mustExecuteTemplate(t, `{{ valueString "nil" "latest" }}`, data))
+	assert.Equal(t, "3", mustExecuteTemplate(t, `{{ valueString "image.replicas" "" }}`, data))
+	assert.Equal(t, "3", mustExecuteTemplate(t, `{{ valueInt "image.replicas" 1 }}`, data))
+	assert.Equal(t, "1", mustExecuteTemplate(t, `{{ valueInt "image.unknown" 1 }}`, data))
+	assert.Equal(t, "true", mustExecuteTemplate(t, `{{ valueBool "image.enabled" false }}`, data))
+	assert.Equal(t, "0.5", mustExecuteTemplate(t, `{{ valueFloat "image.ratio" 1.0 }}`, data))
+	assert.Equal(t, "8080", mustExecuteTemplate(t, `{{ valueInt "ports.0.port" 80 }}`, data))
+	assert.Equal(t, "1", mustExecuteTemplate(t, `{{ len (valueSlice "ports") }}`, data))
+	assert.Equal(t, "0", mustExecuteTemplate(t, `{{ len (valueSlice "unknown") }}`, data))
+	assert.Equal(t, "1.2", mustExecuteTemplate(t, `{{ (valueMap "image").tag }}`, data))
+	assert.Equal(t, "fallback", mustExecuteTemplate(t, `{{ value "image.unknown" "fallback" }}`, data))
+
+	_, err := executeTemplate(t, `{{ valueInt "image.tag" 1 }}`, data)
+	assert.ErrorContains(t, err, t.Name()+": value 'image.tag' is expected to be an integer, but got: 1.2")
+
+	_, err = executeTemplate(t, `{{ valueString "ports" "" }}`, data)
+	assert.ErrorContains(t, err, t.Name()+": value 'ports' is expected to be a string")
+
+	_, err = executeTemplate(t, `{{ valueMap "ports" }}`, data)
+	assert.ErrorContains(t, err, t.Name()+": value 'ports' is expected to be a map")
+}
@@ -0,0 +1,99 @@
+package functions
+
+import (
+	"github.com/echocat/kubor/template"
+	"os"
+	"reflect"
+	"strings"
+)
+
+var FuncEnv = Function{
+	Description: "Returns the value of the environment variable <name>. If the variable is not set an empty string is returned." +
+		" In difference to .Env.<name> this does not fail if the variable is absent.",
+	Parameters: Parameters{{
+		Name: "name",
+	}},
+	Returns: Return{
+		Description: "The value of the environment variable or an empty string.",
+	},
+}.MustWithFunc(func(context template.ExecutionContext, name string) string {
+	return environOfContext(context)[name]
+})
+
+var FuncEnvOr = Function{
+	Description: "Returns the value of the environment variable <name>. If the variable is not set or empty <default> is returned.",
+	Parameters: Parameters{{
+		Name: "name",
+	}, {
+		Name: "default",
+	}},
+	Returns: Return{
+		Description: "The value of the environment variable or <default>.",
+	},
+}.MustWithFunc(func(context template.ExecutionContext, name string, def string) string {
+	if v := environOfContext(context)[name]; v != "" {
+		return v
+	}
+	return def
+})
+
+var FuncRequiredEnv = Function{
+	Description: "Returns the value of the environment variable <name>. If the variable is not set or empty the rendering fails.",
+	Parameters: Parameters{{
+		Name: "name",
+	}},
+	Returns: Return{
+		Description: "The value of the environment variable.",
+	},
+}.MustWithFunc(func(context template.ExecutionContext, name string) (string, error) {
+	if v := environOfContext(context)[name]; v != "" {
+		return v, nil
+	}
+	return "", newControlError(context, "required environment variable '%s' is not set", name)
+})
+
+var FuncEnvPrefix = Function{
+	Description: "Collects all environment variables which names start with <prefix>.",
+	Parameters: Parameters{{
+		Name: "prefix",
+	}},
+	Returns: Return{
+		Description: "A map of all matching variables. The keys are the names of the variables without <prefix>." +
+			" Example: {{ envPrefix \"APP_\" }} with APP_DB_HOST=foo set results in map[DB_HOST:foo]",
+	},
+}.MustWithFunc(func(context template.ExecutionContext, prefix string) map[string]string {
+	result := map[string]string{}
+	for name, value := range environOfContext(context) {
+		if strings.HasPrefix(name, prefix) {
+			result[name[len(prefix):]] = value
+		}
+	}
+	return result
+})
+
+var FuncsEnvironment = Functions{
+	"env":         FuncEnv,
+	"envOr":       FuncEnvOr,
+	"requiredEnv": FuncRequiredEnv,
+	"envPrefix":   FuncEnvPrefix,
+}
+var CategoryEnvironment = Category{
+	Functions: FuncsEnvironment,
+}
+
+// environOfContext returns the "Env" property of the data of the current context.
+// If there is no such property the environment of the current process is used.
+func environOfContext(context template.ExecutionContext) map[string]string {
+	if v, found := lookupProperty(reflect.ValueOf(context.GetData()), "Env"); found && v.CanInterface() {
+		if env, ok := v.Interface().(map[string]string); ok {
+			return env
+		}
+	}
+	result := map[string]string{}
+	for _, keyAndValue := range os.Environ() {
+		if key, value, ok := strings.Cut(keyAndValue, "="); ok {
+			result[key] = value
+		}
+	}
+	return result
+}
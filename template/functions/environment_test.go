@@ -0,0 +1,28 @@
+package functions
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_FuncEnv(t *testing.T) {
+	data := struct {
+		Env map[string]string
+	}{
+		Env: map[string]string{"FOO": "foo", "APP_DB_HOST": "db.local", "APP_DB_PORT": "5432", "EMPTY": ""},
+	}
+	assert.Equal(t, "foo", mustExecuteTemplate(t, `{{ env "FOO" }}`, data))
+	assert.Equal(t, "", mustExecuteTemplate(t, `{{ env "UNKNOWN" }}`, data))
+	assert.Equal(t, "foo", mustExecuteTemplate(t, `{{ envOr "FOO" "bar" }}`, data))
+	assert.Equal(t, "bar", mustExecuteTemplate(t, `{{ envOr "EMPTY" "bar" }}`, data))
+	assert.Equal(t, "foo", mustExecuteTemplate(t, `{{ requiredEnv "FOO" }}`, data))
+	assert.Equal(t, "map[DB_HOST:db.local DB_PORT:5432]", mustExecuteTemplate(t, `{{ envPrefix "APP_" }}`, data))
+
+	_, err := executeTemplate(t, `{{ requiredEnv "UNKNOWN" }}`, data)
+	assert.ErrorContains(t, err, t.Name()+": required environment variable 'UNKNOWN' is not set")
+}
+
+func Test_FuncEnv_fromProcess(t *testing.T) {
+	t.Setenv("KUBOR_TEST_ENV", "from-process")
+	assert.Equal(t, "from-process", mustExecuteTemplate(t, `{{ env "KUBOR_TEST_ENV" }}`, nil))
+}
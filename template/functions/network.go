@@ -0,0 +1,417 @@
+package functions
+
+import (
+	"fmt"
+	"math/big"
+	"net"
+	"net/netip"
+	"net/url"
+)
+
+// maxNetworkEnumeration limits the amount of elements cidrHosts and cidrSubnets will return.
+const maxNetworkEnumeration = 65536
+
+var FuncCidrParse = Function{
+	Description: `Parses the given <cidr> (like "10.0.0.0/8" or "fd00::/64").`,
+	Parameters: Parameters{{
+		Name: "cidr",
+	}},
+	Returns: Return{
+		Description: `A map with the keys "ip" (the address as provided), "network" (the masked network in CIDR notation),` +
+			` "networkAddress", "broadcastAddress" (the last address of the network), "netmask", "prefixLength",` +
+			` "bits" (32 for IPv4, 128 for IPv6) and "ipv6".`,
+	},
+}.MustWithFunc(func(cidr string) (map[string]interface{}, error) {
+	prefix, err := parsePrefix(cidr)
+	if err != nil {
+		return nil, err
+	}
+	masked := prefix.Masked()
+	bits := prefix.Addr().BitLen()
+	return map[string]interface{}{
+		"ip":               prefix.Addr().String(),
+		"network":          masked.String(),
+		"networkAddress":   masked.Addr().String(),
+		"broadcastAddress": lastAddrOf(masked).String(),
+		"netmask":          net.IP(net.CIDRMask(prefix.Bits(), bits)).String(),
+		"prefixLength":     prefix.Bits(),
+		"bits":             bits,
+		"ipv6":             bits == 128,
+	}, nil
+})
+
+var FuncCidrContains = Function{
+	Description: `Checks if the given <ip> or network (in CIDR notation) is completely part of the network <cidr>.`,
+	Parameters: Parameters{{
+		Name: "cidr",
+	}, {
+		Name:        "ip",
+		Description: `Either a plain address (like "10.1.2.3") or a network in CIDR notation (like "10.1.0.0/16").`,
+	}},
+	Returns: Return{
+		Description: `true if <ip> is contained in <cidr>.`,
+	},
+}.MustWithFunc(func(cidr string, ip string) (bool, error) {
+	prefix, err := parsePrefix(cidr)
+	if err != nil {
+		return false, err
+	}
+	prefix = prefix.Masked()
+	if addr, err := netip.ParseAddr(ip); err == nil {
+		return prefix.Contains(addr.Unmap()), nil
+	}
+	candidate, err := parsePrefix(ip)
+	if err != nil {
+		return false, fmt.Errorf("neither an ip address nor a network in CIDR notation: %s", ip)
+	}
+	return candidate.Bits() >= prefix.Bits() && prefix.Contains(candidate.Masked().Addr()), nil
+})
+
+var FuncCidrSubnets = Function{
+	Description: `Splits the network <cidr> into subnets by extending its prefix length by <newBits>.` +
+		` Example: {{ cidrSubnets 2 "10.0.0.0/16" }} results in [10.0.0.0/18 10.0.64.0/18 10.0.128.0/18 10.0.192.0/18]`,
+	Parameters: Parameters{{
+		Name:        "newBits",
+		Description: "Number of bits the prefix length should be extended by. This results in 2^<newBits> subnets.",
+	}, {
+		Name: "cidr",
+	}},
+	Returns: Return{
+		Description: "The subnets in CIDR notation.",
+	},
+}.MustWithFunc(func(newBits int, cidr string) ([]string, error) {
+	prefix, err := parsePrefix(cidr)
+	if err != nil {
+		return nil, err
+	}
+	prefix = prefix.Masked()
+	bits := prefix.Bits() + newBits
+	if newBits < 0 || bits > prefix.Addr().BitLen() {
+		return nil, fmt.Errorf("cannot extend prefix length of %v by %d bits", prefix, newBits)
+	}
+	amount := new(big.Int).Lsh(big.NewInt(1), uint(newBits))
+	if amount.Cmp(big.NewInt(maxNetworkEnumeration)) > 0 {
+		return nil, fmt.Errorf("splitting %v by %d bits would result in more than %d subnets", prefix, newBits, maxNetworkEnumeration)
+	}
+	step := new(big.Int).Lsh(big.NewInt(1), uint(prefix.Addr().BitLen()-bits))
+	result := make([]string, amount.Int64())
+	for i := range result {
+		addr := addAddr(prefix.Addr(), new(big.Int).Mul(step, big.NewInt(int64(i))))
+		result[i] = netip.PrefixFrom(addr, bits).String()
+	}
+	return result, nil
+})
+
+var FuncCidrHost = Function{
+	Description: `Returns the address with the number <n> inside of the network <cidr>. The network address itself is 0.` +
+		` If <n> is negative it is counted from the end of the network; -1 is the last address.`,
+	Parameters: Parameters{{
+		Name: "n",
+	}, {
+		Name: "cidr",
+	}},
+	Returns: Return{
+		Description: "The requested address.",
+	},
+}.MustWithFunc(func(n int, cidr string) (string, error) {
+	prefix, err := parsePrefix(cidr)
+	if err != nil {
+		return "", err
+	}
+	prefix = prefix.Masked()
+	size := new(big.Int).Lsh(big.NewInt(1), uint(prefix.Addr().BitLen()-prefix.Bits()))
+	offset := big.NewInt(int64(n))
+	if n < 0 {
+		offset.Add(offset, size)
+	}
+	if offset.Sign() < 0 || offset.Cmp(size) >= 0 {
+		return "", fmt.Errorf("there is no address #%d inside of %v", n, prefix)
+	}
+	return addAddr(prefix.Addr(), offset).String(), nil
+})
+
+var FuncCidrHosts = Function{
+	Description: `Enumerates all usable host addresses of the network <cidr>. For IPv4 networks with a prefix length` +
+		` smaller than 31 the network and broadcast addresses are excluded.`,
+	Parameters: Parameters{{
+		Name: "cidr",
+	}},
+	Returns: Return{
+		Description: fmt.Sprintf("All host addresses. It fails if the network contains more than %d addresses.", maxNetworkEnumeration),
+	},
+}.MustWithFunc(func(cidr string) ([]string, error) {
+	prefix, err := parsePrefix(cidr)
+	if err != nil {
+		return nil, err
+	}
+	prefix = prefix.Masked()
+	hostBits := prefix.Addr().BitLen() - prefix.Bits()
+	if hostBits > 16 {
+		return nil, fmt.Errorf("%v contains more than %d addresses", prefix, maxNetworkEnumeration)
+	}
+	first, last := prefix.Addr(), lastAddrOf(prefix)
+	if prefix.Addr().Is4() && hostBits > 1 {
+		first, last = first.Next(), last.Prev()
+	}
+	var result []string
+	for addr := first; addr.IsValid() && addr.Compare(last) <= 0; addr = addr.Next() {
+		result = append(result, addr.String())
+	}
+	return result, nil
+})
+
+var FuncIsIP = Function{
+	Description: `Checks if the given <str> is a valid IPv4 or IPv6 address.`,
+	Parameters: Parameters{{
+		Name: "str",
+	}},
+}.MustWithFunc(func(str string) bool {
+	_, err := netip.ParseAddr(str)
+	return err == nil
+})
+
+var FuncIsIPv4 = Function{
+	Description: `Checks if the given <str> is a valid IPv4 address.`,
+	Parameters: Parameters{{
+		Name: "str",
+	}},
+}.MustWithFunc(func(str string) bool {
+	addr, err := netip.ParseAddr(str)
+	return err == nil && addr.Is4()
+})
+
+var FuncIsIPv6 = Function{
+	Description: `Checks if the given <str> is a valid IPv6 address.`,
+	Parameters: Parameters{{
+		Name: "str",
+	}},
+}.MustWithFunc(func(str string) bool {
+	addr, err := netip.ParseAddr(str)
+	return err == nil && addr.Is6()
+})
+
+var FuncSplitHostPort = Function{
+	Description: `Splits the network address <hostPort> (like "example.org:80" or "[::1]:80") into host and port.` +
+		` See https://golang.org/pkg/net/#SplitHostPort for more details.`,
+	Parameters: Parameters{{
+		Name: "hostPort",
+	}},
+	Returns: Return{
+		Description: `A map with the keys "host" and "port".`,
+	},
+}.MustWithFunc(func(hostPort string) (map[string]string, error) {
+	if host, port, err := net.SplitHostPort(hostPort); err != nil {
+		return nil, err
+	} else {
+		return map[string]string{
+			"host": host,
+			"port": port,
+		}, nil
+	}
+})
+
+var FuncJoinHostPort = Function{
+	Description: `Combines <host> and <port> into a network address (like "example.org:80" or "[::1]:80").` +
+		` See https://golang.org/pkg/net/#JoinHostPort for more details.`,
+	Parameters: Parameters{{
+		Name: "host",
+	}, {
+		Name:        "port",
+		Description: "Either a number or a string.",
+	}},
+}.MustWithFunc(func(host string, port interface{}) string {
+	return net.JoinHostPort(host, fmt.Sprint(port))
+})
+
+var FuncUrlParse = Function{
+	Description: `Parses the given <url>. See https://golang.org/pkg/net/url/#Parse for more details.`,
+	Parameters: Parameters{{
+		Name: "url",
+	}},
+	Returns: Return{
+		Description: `A map with the keys "scheme", "username", "password", "host" (including the port), "hostname",` +
+			` "port", "path", "query" (a map of string slices), "rawQuery" and "fragment".` +
+			` The same map could be used as input for urlBuild.`,
+	},
+}.MustWithFunc(func(in string) (map[string]interface{}, error) {
+	u, err := url.Parse(in)
+	if err != nil {
+		return nil, err
+	}
+	password, _ := u.User.Password()
+	query := map[string]interface{}{}
+	for key, values := range u.Query() {
+		query[key] = values
+	}
+	return map[string]interface{}{
+		"scheme":   u.Scheme,
+		"username": u.User.Username(),
+		"password": password,
+		"host":     u.Host,
+		"hostname": u.Hostname(),
+		"port":     u.Port(),
+		"path":     u.Path,
+		"query":    query,
+		"rawQuery": u.RawQuery,
+		"fragment": u.Fragment,
+	}, nil
+})
+
+var FuncUrlBuild = Function{
+	Description: `Builds an URL out of the given <parts>. All of its values are escaped where required.`,
+	Parameters: Parameters{{
+		Name: "parts",
+		Description: `A map with the optional keys "scheme", "username", "password", "host" (could include the port),` +
+			` "hostname" and "port" (only used if "host" is absent), "path", "query" (a map of strings or string slices;` +
+			` has precedence over "rawQuery"), "rawQuery" and "fragment".`,
+	}},
+	Returns: Return{
+		Description: "The resulting URL.",
+	},
+}.MustWithFunc(func(in interface{}) (string, error) {
+	parts, ok := stringKeyedMapOf(in)
+	if !ok {
+		return "", fmt.Errorf("parts are expected to be a map, but got: %v", in)
+	}
+	str := func(key string) string {
+		if v, ok := parts[key]; ok && v != nil {
+			return fmt.Sprint(v)
+		}
+		return ""
+	}
+	u := url.URL{
+		Scheme:   str("scheme"),
+		Host:     str("host"),
+		Path:     str("path"),
+		RawQuery: str("rawQuery"),
+		Fragment: str("fragment"),
+	}
+	if u.Host == "" && str("hostname") != "" {
+		if port := str("port"); port != "" {
+			u.Host = net.JoinHostPort(str("hostname"), port)
+		} else if addr, err := netip.ParseAddr(str("hostname")); err == nil && addr.Is6() {
+			u.Host = "[" + addr.String() + "]"
+		} else {
+			u.Host = str("hostname")
+		}
+	}
+	if username := str("username"); username != "" {
+		if password, ok := parts["password"]; ok && password != nil && password != "" {
+			u.User = url.UserPassword(username, fmt.Sprint(password))
+		} else {
+			u.User = url.User(username)
+		}
+	}
+	if query, ok := parts["query"]; ok && query != nil {
+		values, err := toUrlValues(query)
+		if err != nil {
+			return "", err
+		}
+		u.RawQuery = values.Encode()
+	}
+	return u.String(), nil
+})
+
+var FuncUrlQueryEscape = Function{
+	Description: `Escapes the string <str> so it can be safely placed inside a URL query.` +
+		` See https://golang.org/pkg/net/url/#QueryEscape for more details.`,
+	Parameters: Parameters{{
+		Name: "str",
+	}},
+}.MustWithFunc(url.QueryEscape)
+
+var FuncUrlPathEscape = Function{
+	Description: `Escapes the string <str> so it can be safely placed inside a URL path segment.` +
+		` See https://golang.org/pkg/net/url/#PathEscape for more details.`,
+	Parameters: Parameters{{
+		Name: "str",
+	}},
+}.MustWithFunc(url.PathEscape)
+
+func parsePrefix(cidr string) (netip.Prefix, error) {
+	if prefix, err := netip.ParsePrefix(cidr); err != nil {
+		return netip.Prefix{}, fmt.Errorf("illegal network in CIDR notation: %s", cidr)
+	} else {
+		return prefix, nil
+	}
+}
+
+func addAddr(addr netip.Addr, offset *big.Int) netip.Addr {
+	raw := addr.AsSlice()
+	sum := new(big.Int).Add(new(big.Int).SetBytes(raw), offset)
+	sum.FillBytes(raw)
+	result, _ := netip.AddrFromSlice(raw)
+	return result
+}
+
+func lastAddrOf(prefix netip.Prefix) netip.Addr {
+	size := new(big.Int).Lsh(big.NewInt(1), uint(prefix.Addr().BitLen()-prefix.Bits()))
+	return addAddr(prefix.Masked().Addr(), size.Sub(size, big.NewInt(1)))
+}
+
+func toUrlValues(in interface{}) (url.Values, error) {
+	if values, ok := in.(url.Values); ok {
+		return values, nil
+	}
+	m, ok := stringKeyedMapOf(in)
+	if !ok {
+		return nil, fmt.Errorf("query is expected to be a map, but got: %v", in)
+	}
+	result := url.Values{}
+	for key, value := range m {
+		switch v := value.(type) {
+		case []string:
+			result[key] = append(result[key], v...)
+		case []interface{}:
+			for _, element := range v {
+				result.Add(key, fmt.Sprint(element))
+			}
+		default:
+			result.Add(key, fmt.Sprint(v))
+		}
+	}
+	return result, nil
+}
+
+func stringKeyedMapOf(in interface{}) (map[string]interface{}, bool) {
+	result := map[string]interface{}{}
+	switch v := in.(type) {
+	case map[string]interface{}:
+		return v, true
+	case map[string]string:
+		for key, value := range v {
+			result[key] = value
+		}
+	case map[string][]string:
+		for key, value := range v {
+			result[key] = value
+		}
+	case map[interface{}]interface{}:
+		for key, value := range v {
+			result[fmt.Sprint(key)] = value
+		}
+	default:
+		return nil, false
+	}
+	return result, true
+}
+
+var FuncsNetwork = Functions{
+	"cidrParse":      FuncCidrParse,
+	"cidrContains":   FuncCidrContains,
+	"cidrSubnets":    FuncCidrSubnets,
+	"cidrHost":       FuncCidrHost,
+	"cidrHosts":      FuncCidrHosts,
+	"isIP":           FuncIsIP,
+	"isIPv4":         FuncIsIPv4,
+	"isIPv6":         FuncIsIPv6,
+	"splitHostPort":  FuncSplitHostPort,
+	"joinHostPort":   FuncJoinHostPort,
+	"urlParse":       FuncUrlParse,
+	"urlBuild":       FuncUrlBuild,
+	"urlQueryEscape": FuncUrlQueryEscape,
+	"urlPathEscape":  FuncUrlPathEscape,
+}
+var CategoryNetwork = Category{
+	Functions: FuncsNetwork,
+}
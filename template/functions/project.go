@@ -0,0 +1,113 @@
+package functions
+
+import (
+	"fmt"
+	"github.com/echocat/kubor/template"
+	"reflect"
+)
+
+var FuncGroupId = Function{
+	Description: "Returns the groupId of the current project.",
+	Returns: Return{
+		Description: "The groupId or an empty string if not defined.",
+	},
+}.MustWithFunc(func(context template.ExecutionContext) (string, error) {
+	return projectStringOfContext(context, "GroupId")
+})
+
+var FuncArtifactId = Function{
+	Description: "Returns the artifactId of the current project.",
+}.MustWithFunc(func(context template.ExecutionContext) (string, error) {
+	return projectStringOfContext(context, "ArtifactId")
+})
+
+var FuncRelease = Function{
+	Description: "Returns the release of the current project.",
+	Returns: Return{
+		Description: "The release or an empty string if not defined.",
+	},
+}.MustWithFunc(func(context template.ExecutionContext) (string, error) {
+	return projectStringOfContext(context, "Release")
+})
+
+var FuncContextName = Function{
+	Description: "Returns the name of the Kubernetes context the current project is executed against.",
+	Returns: Return{
+		Description: "The name of the context or an empty string if the default context is used.",
+	},
+}.MustWithFunc(func(context template.ExecutionContext) (string, error) {
+	return projectStringOfContext(context, "Context")
+})
+
+var FuncStages = Function{
+	Description: "Returns all stages of the current project in the order they are applied.",
+}.MustWithFunc(func(context template.ExecutionContext) ([]string, error) {
+	return projectStringsOfContext(context, "Stages")
+})
+
+var FuncClaimedNamespaces = Function{
+	Description: "Returns all namespaces which are claimed by the current project (see claim.namespaces).",
+	Returns: Return{
+		Description: "The claimed namespaces. This is empty while the claim itself is evaluated.",
+	},
+}.MustWithFunc(func(context template.ExecutionContext) ([]string, error) {
+	return projectStringsOfContext(context, "Claim", "Namespaces")
+})
+
+var FuncsProject = Functions{
+	"groupId":           FuncGroupId,
+	"artifactId":        FuncArtifactId,
+	"release":           FuncRelease,
+	"contextName":       FuncContextName,
+	"stages":            FuncStages,
+	"claimedNamespaces": FuncClaimedNamespaces,
+}
+var CategoryProject = Category{
+	Functions: FuncsProject,
+}
+
+// projectPropertyOfContext resolves the given property path inside the data of the current
+// context which is expected to be the project.
+func projectPropertyOfContext(context template.ExecutionContext, path ...string) (reflect.Value, error) {
+	current := reflect.ValueOf(context.GetData())
+	for _, element := range path {
+		if next, found := lookupProperty(current, element); !found {
+			return reflect.Value{}, fmt.Errorf("%s: project property '%s' is not available in this context", context.GetTemplate().GetSource(), element)
+		} else {
+			current = next
+		}
+	}
+	return current, nil
+}
+
+func projectStringOfContext(context template.ExecutionContext, path ...string) (string, error) {
+	if v, err := projectPropertyOfContext(context, path...); err != nil {
+		return "", err
+	} else if v.Kind() == reflect.String {
+		return v.String(), nil
+	} else {
+		return fmt.Sprint(v.Interface()), nil
+	}
+}
+
+func projectStringsOfContext(context template.ExecutionContext, path ...string) ([]string, error) {
+	v, err := projectPropertyOfContext(context, path...)
+	if err != nil {
+		return nil, err
+	}
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, fmt.Errorf("%s: project property '%v' is not a list", context.GetTemplate().GetSource(), path)
+	}
+	result := make([]string, v.Len())
+	for i := range result {
+		if element := v.Index(i); element.Kind() == reflect.String {
+			result[i] = element.String()
+		} else {
+			result[i] = fmt.Sprint(element.Interface())
+		}
+	}
+	return result, nil
+}
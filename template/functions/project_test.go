@@ -0,0 +1,35 @@
+package functions
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type testName string
+
+func Test_FuncsProject(t *testing.T) {
+	data := struct {
+		GroupId    testName
+		ArtifactId testName
+		Release    string
+		Context    string
+		Stages     []testName
+		Claim      struct {
+			Namespaces []testName
+		}
+	}{
+		GroupId:    "grp",
+		ArtifactId: "app",
+		Release:    "1.0",
+		Context:    "prod",
+		Stages:     []testName{"prepare", "deploy"},
+	}
+	data.Claim.Namespaces = []testName{"grp", "grp-jobs"}
+
+	assert.Equal(t, "grp app 1.0 prod", mustExecuteTemplate(t, `{{ groupId }} {{ artifactId }} {{ release }} {{ contextName }}`, data))
+	assert.Equal(t, "[prepare deploy]", mustExecuteTemplate(t, `{{ stages }}`, data))
+	assert.Equal(t, "[grp grp-jobs]", mustExecuteTemplate(t, `{{ claimedNamespaces }}`, data))
+
+	_, err := executeTemplate(t, `{{ groupId }}`, "foo")
+	assert.ErrorContains(t, err, t.Name()+": project property 'GroupId' is not available in this context")
+}
@@ -52,6 +52,29 @@ var FuncInclude = Function{
 	}
 })
 
+var FuncIncludeNamed = Function{
+	Description: "Renders the named template <name> using <data>. The template could either be defined inside of the" +
+		" current template or inside of one of the library templates (see templating.libraryPattern) using {{ define }}." +
+		" In difference to {{ template }} the result is returned as string and could be piped into other functions like indent.",
+	Parameters: Parameters{{
+		Name:        "name",
+		Description: "The name of the template which should be rendered using the provided <data>.",
+	}, {
+		Name:        "data",
+		Description: "The data that could be accessed while the rendering the named template.",
+	}},
+	Returns: Return{
+		Description: "The rendered content.",
+	},
+}.MustWithFunc(func(context template.ExecutionContext, name string, data interface{}) (string, error) {
+	tmpl := context.GetTemplate()
+	if result, err := tmpl.ExecuteTemplateToString(name, data); err != nil {
+		return "", fmt.Errorf("%s: cannot evaluate named template '%s': %w", tmpl.GetSource(), name, err)
+	} else {
+		return result, nil
+	}
+})
+
 var FuncSourceFile = Function{
 	Returns: Return{
 		Description: "The filename which is the source of this rendered template if any.",
@@ -69,10 +92,11 @@ var FuncSourceName = Function{
 })
 
 var FuncsTemplating = Functions{
-	"render":     FuncRender,
-	"include":    FuncInclude,
-	"sourceFile": FuncSourceFile,
-	"sourceName": FuncSourceName,
+	"render":       FuncRender,
+	"include":      FuncInclude,
+	"includeNamed": FuncIncludeNamed,
+	"sourceFile":   FuncSourceFile,
+	"sourceName":   FuncSourceName,
 }
 var CategoryTemplating = Category{
 	Functions: FuncsTemplating,
@@ -0,0 +1,77 @@
+package functions
+
+import (
+	"github.com/echocat/kubor/template"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_FuncIncludeNamed(t *testing.T) {
+	assert.Equal(t, mustExecuteTemplate(t, `{{ define "foo" }}foo:{{ . }}{{ end }}{{ includeNamed "foo" "bar" | upper }}`, nil), "FOO:BAR")
+
+	_, err := executeTemplate(t, `{{ includeNamed "unknown" . }}`, nil)
+	assert.Error(t, err)
+}
+
+func Test_FuncIncludeNamed_fromLibrary(t *testing.T) {
+	library := DefaultTemplateLibrary()
+	assert.NoError(t, library.Add("_helpers.tpl", `{{ define "labels" }}app: {{ .name }}
+tier: {{ .tier }}{{ end }}`))
+	assert.NoError(t, library.Add("_other.tpl", `{{ define "name" }}{{ .name }}-{{ .tier }}{{ end }}`))
+
+	tmpl, err := DefaultTemplateFactoryWithLibrary(library).New(t.Name(), `name: {{ template "name" . }}
+labels:
+{{ includeNamed "labels" . | indent 2 }}`)
+	assert.NoError(t, err)
+
+	actual, err := tmpl.ExecuteToString(map[string]interface{}{"name": "foo", "tier": "backend"})
+	assert.NoError(t, err)
+	assert.Equal(t, `name: foo-backend
+labels:
+  app: foo
+  tier: backend`, actual)
+}
+
+func Test_TemplateError(t *testing.T) {
+	library := DefaultTemplateLibrary()
+	assert.NoError(t, library.Add("_helpers.tpl", `{{ define "labels" }}app: {{ .name }}
+tier: {{ fail "no tier" }}{{ end }}`))
+
+	tmpl, err := DefaultTemplateFactoryWithLibrary(library).New(t.Name(), `name: foo
+labels:
+  {{ includeNamed "labels" . }}`)
+	assert.NoError(t, err)
+
+	_, err = tmpl.ExecuteToString(map[string]interface{}{"name": "foo"})
+	var te *template.TemplateError
+	assert.ErrorAs(t, err, &te)
+
+	stack := te.Stack()
+	assert.Len(t, stack, 2)
+	assert.Equal(t, t.Name(), stack[0].Source)
+	assert.Equal(t, 3, stack[0].Line)
+	assert.Equal(t, 6, stack[0].Column)
+	assert.Equal(t, `<includeNamed "labels" .>`, stack[0].Action)
+	assert.Equal(t, "_helpers.tpl", stack[1].Source)
+	assert.Equal(t, 2, stack[1].Line)
+	assert.Equal(t, 10, stack[1].Column)
+	assert.Equal(t, `<fail "no tier">`, stack[1].Action)
+	assert.Equal(t, `1 | {{ define "labels" }}app: {{ .name }}
+2 | tier: {{ fail "no tier" }}{{ end }}
+  |          ^
+`, stack[1].Excerpt(2))
+
+	var ce ControlError
+	assert.ErrorAs(t, err, &ce)
+	assert.Equal(t, "no tier", ce.Message)
+}
+
+func Test_TemplateError_parse(t *testing.T) {
+	_, err := DefaultTemplateFactory().New(t.Name(), `foo
+{{ if }}`)
+	var te *template.TemplateError
+	assert.ErrorAs(t, err, &te)
+	assert.Equal(t, t.Name(), te.Source)
+	assert.Equal(t, 2, te.Line)
+	assert.Equal(t, "missing value for if", te.Message)
+}
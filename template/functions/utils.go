@@ -18,3 +18,14 @@ func DefaultTemplateFactory() template.Factory {
 		FunctionProvider: CategoriesDefault,
 	}
 }
+
+func DefaultTemplateLibrary() *template.Library {
+	return template.NewLibrary(CategoriesDefault)
+}
+
+func DefaultTemplateFactoryWithLibrary(library *template.Library) template.Factory {
+	return &template.FactoryImpl{
+		FunctionProvider: CategoriesDefault,
+		Library:          library,
+	}
+}
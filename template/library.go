@@ -0,0 +1,104 @@
+package template
+
+import (
+	"fmt"
+	"io"
+	"os"
+	nt "text/template"
+)
+
+// Library holds named templates (created via {{ define }}) which are parsed
+// once and are afterwards available to every template created by a Factory
+// that references this Library.
+type Library struct {
+	functionProvider FunctionProvider
+	delegate         *nt.Template
+	sources          []string
+	codes            map[string]string
+}
+
+func NewLibrary(functionProvider FunctionProvider) *Library {
+	return &Library{
+		functionProvider: functionProvider,
+		codes:            map[string]string{},
+	}
+}
+
+func (instance *Library) Add(name string, code string) error {
+	codeOf := func(source string) string {
+		if source == name {
+			return code
+		}
+		return instance.codeOf(source)
+	}
+	if instance.delegate == nil {
+		if delegate, err := newDelegate(name, code, instance.functionProvider, nil); err != nil {
+			return newTemplateError(err, codeOf)
+		} else {
+			instance.delegate = delegate
+		}
+	} else if _, err := instance.delegate.New(name).Parse(code); err != nil {
+		return newTemplateError(err, codeOf)
+	}
+	instance.sources = append(instance.sources, name)
+	instance.codes[name] = code
+	return nil
+}
+
+func (instance *Library) AddFromReader(name string, reader io.Reader) error {
+	if content, err := io.ReadAll(reader); err != nil {
+		return err
+	} else {
+		return instance.Add(name, string(content))
+	}
+}
+
+func (instance *Library) AddFromFile(file string) error {
+	if f, err := os.Open(file); err != nil {
+		return fmt.Errorf("cannot read library template from %s: %w", file, err)
+	} else {
+		//noinspection GoUnhandledErrorResult
+		defer f.Close()
+		if err := instance.AddFromReader(file, f); err != nil {
+			return fmt.Errorf("cannot parse library template from %s: %w", file, err)
+		}
+		return nil
+	}
+}
+
+// GetSources returns the names of all sources (usually filenames) this library was created from.
+func (instance *Library) GetSources() []string {
+	if instance == nil {
+		return nil
+	}
+	return instance.sources
+}
+
+// GetDefinitionNames returns the names of all templates which are defined by this library.
+func (instance *Library) GetDefinitionNames() []string {
+	if instance == nil || instance.delegate == nil {
+		return nil
+	}
+	result := make([]string, 0, len(instance.delegate.Templates()))
+	for _, candidate := range instance.delegate.Templates() {
+		result = append(result, candidate.Name())
+	}
+	return result
+}
+
+func (instance *Library) codeOf(source string) string {
+	if instance == nil {
+		return ""
+	}
+	return instance.codes[source]
+}
+
+func (instance *Library) newDelegate(name string, code string) (*nt.Template, error) {
+	if clone, err := instance.delegate.Clone(); err != nil {
+		return nil, err
+	} else {
+		return clone.New(name).
+			Option("missingkey=error").
+			Parse(code)
+	}
+}
@@ -2,6 +2,7 @@ package template
 
 import (
 	"bytes"
+	"fmt"
 	"io"
 	nt "text/template"
 )
@@ -11,6 +12,8 @@ type Template interface {
 
 	Execute(data interface{}, target io.Writer) error
 	ExecuteToString(data interface{}) (string, error)
+	ExecuteTemplate(name string, data interface{}, target io.Writer) error
+	ExecuteTemplateToString(name string, data interface{}) (string, error)
 	MustExecute(data interface{}, target io.Writer)
 	MustExecuteToString(data interface{}) string
 
@@ -19,7 +22,10 @@ type Template interface {
 	GetSourceFile() *string
 }
 
-func newDelegate(name string, code string, functionProvider FunctionProvider) (*nt.Template, error) {
+func newDelegate(name string, code string, functionProvider FunctionProvider, library *Library) (*nt.Template, error) {
+	if library != nil && library.delegate != nil {
+		return library.newDelegate(name, code)
+	}
 	if functions, err := functionProvider.GetFunctions(); err != nil {
 		return nil, err
 	} else if funcMap, err := functions.CreateDummyFuncMap(); err != nil {
@@ -40,6 +46,7 @@ type Impl struct {
 	factory          Factory
 	functionProvider FunctionProvider
 	delegate         *nt.Template
+	library          *Library
 }
 
 func (instance *Impl) WithSourceFile(sourceFile string) (Template, error) {
@@ -50,23 +57,15 @@ func (instance *Impl) WithSourceFile(sourceFile string) (Template, error) {
 		functionProvider: instance.functionProvider,
 		factory:          instance.factory,
 		delegate:         instance.delegate,
+		library:          instance.library,
 	}, nil
 }
 
 func (instance *Impl) Execute(data interface{}, target io.Writer) error {
-	if context, err := instance.newExecutionContext(data); err != nil {
-		return err
-	} else if clone, err := instance.delegate.Clone(); err != nil {
-		return err
-	} else if functions, err := instance.functionProvider.GetFunctions(); err != nil {
-		return err
-	} else if funcMap, err := functions.CreateFuncMap(context); err != nil {
+	if clone, err := instance.prepareExecution(data); err != nil {
 		return err
 	} else {
-		return clone.
-			Option("missingkey=error").
-			Funcs(funcMap).
-			Execute(target, data)
+		return newTemplateError(clone.Execute(target, data), instance.codeOf)
 	}
 }
 
@@ -78,6 +77,47 @@ func (instance *Impl) ExecuteToString(data interface{}) (string, error) {
 	return buf.String(), nil
 }
 
+func (instance *Impl) ExecuteTemplate(name string, data interface{}, target io.Writer) error {
+	if clone, err := instance.prepareExecution(data); err != nil {
+		return err
+	} else if clone.Lookup(name) == nil {
+		return fmt.Errorf("%s: there is no template named '%s' defined", instance.GetSource(), name)
+	} else {
+		return newTemplateError(clone.ExecuteTemplate(target, name, data), instance.codeOf)
+	}
+}
+
+func (instance *Impl) ExecuteTemplateToString(name string, data interface{}) (string, error) {
+	buf := new(bytes.Buffer)
+	if err := instance.ExecuteTemplate(name, data, buf); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func (instance *Impl) prepareExecution(data interface{}) (*nt.Template, error) {
+	if context, err := instance.newExecutionContext(data); err != nil {
+		return nil, err
+	} else if clone, err := instance.delegate.Clone(); err != nil {
+		return nil, err
+	} else if functions, err := instance.functionProvider.GetFunctions(); err != nil {
+		return nil, err
+	} else if funcMap, err := functions.CreateFuncMap(context); err != nil {
+		return nil, err
+	} else {
+		return clone.
+			Option("missingkey=error").
+			Funcs(funcMap), nil
+	}
+}
+
+func (instance *Impl) codeOf(source string) string {
+	if source == instance.sourceName {
+		return instance.sourceCode
+	}
+	return instance.library.codeOf(source)
+}
+
 func (instance *Impl) MustExecute(data interface{}, target io.Writer) {
 	if err := instance.Execute(data, target); err != nil {
 		panic(err)